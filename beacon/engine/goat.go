@@ -0,0 +1,22 @@
+package engine
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// GoatConfigV1 is the goat config handshake between the execution and
+// consensus clients, the digest covers the genesis, the predeploy addresses,
+// the tax parameters and the request types.
+type GoatConfigV1 struct {
+	ChainID     *hexutil.Big `json:"chainId"`
+	GenesisHash common.Hash  `json:"genesisHash"`
+	Digest      common.Hash  `json:"digest"`
+}
+
+// NewGoatConfigV1 creates a GoatConfigV1 with the given parameters.
+func NewGoatConfigV1(chainID *big.Int, genesis, digest common.Hash) *GoatConfigV1 {
+	return &GoatConfigV1{ChainID: (*hexutil.Big)(chainID), GenesisHash: genesis, Digest: digest}
+}
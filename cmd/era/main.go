@@ -265,6 +265,7 @@ func checkAccumulator(e *era.Era) error {
 	//   1) the block index is constructed correctly
 	//   2) the tx root matches the value in the block
 	//   3) the receipts root matches the value in the block
+	//   3a) the goat requests hash matches the value in the block, if present
 	//   4) the starting total difficulty value is correct
 	//   5) the accumulator is correct by recomputing it locally, which verifies
 	//      the blocks are all correct (via hash)
@@ -290,6 +291,20 @@ func checkAccumulator(e *era.Era) error {
 		if rr != block.ReceiptHash() {
 			return fmt.Errorf("receipt root in block %d mismatch: want %s, got %s", block.NumberU64(), block.ReceiptHash(), rr)
 		}
+		// 3a) recompute goat requests hash and check value against block.
+		requests, err := it.GoatRequests()
+		if err != nil {
+			return fmt.Errorf("error reading goat requests %d: %w", it.Number(), err)
+		}
+		if requests != nil {
+			want := block.Header().RequestsHash
+			if want == nil {
+				return fmt.Errorf("block %d has goat requests but no requests hash", block.NumberU64())
+			}
+			if qr := types.CalcRequestsHash(requests); qr != *want {
+				return fmt.Errorf("requests hash in block %d mismatch: want %s, got %s", block.NumberU64(), *want, qr)
+			}
+		}
 		hashes = append(hashes, block.Hash())
 		td.Add(td, block.Difficulty())
 		tds = append(tds, new(big.Int).Set(td))
@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/console/prompt"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/pruner"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -54,6 +55,26 @@ var (
 		Name:  "remove.chain",
 		Usage: "If set, selects the state data for removal",
 	}
+	freezerCompressionFlag = &cli.StringFlag{
+		Name:  "compression",
+		Usage: "Compression algorithm of the rewritten table (none, snappy, zstd), the current one is kept if unset",
+	}
+	freezerCompressionLevelFlag = &cli.IntFlag{
+		Name:  "compression.level",
+		Usage: "Zstd compression level of the rewritten table",
+	}
+	freezerDictFlag = &cli.StringFlag{
+		Name:  "compression.dict",
+		Usage: "Path of the zstd dictionary, either trained by zstd or raw content",
+	}
+	freezerDictSizeFlag = &cli.IntFlag{
+		Name:  "compression.dict.size",
+		Usage: "Size of the raw zstd dictionary sampled from the table if no dictionary file is given",
+	}
+	freezerDirFlag = &cli.StringFlag{
+		Name:  "dir",
+		Usage: "Directory to relocate the table to, the current one is kept if unset",
+	}
 
 	removedbCommand = &cli.Command{
 		Action:    removeDB,
@@ -78,11 +99,13 @@ Remove blockchain and state databases`,
 			dbPutCmd,
 			dbGetSlotsCmd,
 			dbDumpFreezerIndex,
+			dbRecompactFreezerCmd,
 			dbImportCmd,
 			dbExportCmd,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
 			dbInspectHistoryCmd,
+			dbMigrateSchemeCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -178,6 +201,31 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "This command displays information about the freezer index.",
 	}
+	dbRecompactFreezerCmd = &cli.Command{
+		Action:    freezerRecompact,
+		Name:      "freezer-recompact",
+		Usage:     "Rewrite a specific freezer table with another compression or location",
+		ArgsUsage: "<freezer-type> <table-type>",
+		Flags: flags.Merge([]cli.Flag{
+			freezerCompressionFlag,
+			freezerCompressionLevelFlag,
+			freezerDictFlag,
+			freezerDictSizeFlag,
+			freezerDirFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `
+geth db freezer-recompact --compression zstd --compression.dict.size 65536 chain receipts
+geth db freezer-recompact --dir /mnt/fast/ancient chain headers
+
+This command rewrites all the items of a freezer table, e.g. compressing the
+receipts with zstd and a dictionary, or relocating the frequently accessed
+tables to a faster disk. The node must be stopped while the command is running.
+
+The table is rewritten aside and only replaces the original one once finished.
+If the replacement is interrupted, it's resumed the next time the freezer is
+opened, so the command can be safely aborted at any point.
+`,
+	}
 	dbImportCmd = &cli.Command{
 		Action:    importLDBdata,
 		Name:      "import",
@@ -229,6 +277,26 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "This command queries the history of the account or storage slot within the specified block range",
 	}
+	dbMigrateSchemeCmd = &cli.Command{
+		Action:    migrateScheme,
+		Name:      "migrate-scheme",
+		Usage:     "Migrate the hash-based state into the path-based scheme",
+		ArgsUsage: "<root>",
+		Flags:     flags.Merge(utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `
+geth db migrate-scheme <state-root>
+
+This command converts the persisted hash-based state into the path-based layout
+offline, which avoids a full resync for switching the state scheme. The state
+with the given root, or the latest persisted state of the canonical chain if
+no root is specified, is migrated. The snapshot is rebuilt if it's not matched
+with the migrated state and all the legacy trie nodes are removed afterwards.
+
+The chain head is rewound to the migrated state on the next startup. The
+progress is persisted periodically and the migration can be resumed by running
+the command again after an interruption.
+`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -627,6 +695,31 @@ func freezerInspect(ctx *cli.Context) error {
 	return rawdb.InspectFreezerTable(ancient, freezer, table, start, end)
 }
 
+func freezerRecompact(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	config := rawdb.FreezerRecompaction{
+		Compression: rawdb.FreezerCompression{
+			Algorithm: ctx.String(freezerCompressionFlag.Name),
+			Level:     ctx.Int(freezerCompressionLevelFlag.Name),
+		},
+		DictSize:  ctx.Int(freezerDictSizeFlag.Name),
+		Directory: ctx.String(freezerDirFlag.Name),
+	}
+	if path := ctx.String(freezerDictFlag.Name); path != "" {
+		dict, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		config.Compression.Dict = dict
+	}
+	stack, _ := makeConfigNode(ctx)
+	ancient := stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+	stack.Close()
+	return rawdb.RecompactFreezerTable(ancient, ctx.Args().Get(0), ctx.Args().Get(1), config)
+}
+
 func importLDBdata(ctx *cli.Context) error {
 	start := 0
 	switch ctx.NArg() {
@@ -930,3 +1023,27 @@ func inspectHistory(ctx *cli.Context) error {
 	}
 	return inspectStorage(triedb, start, end, address, slot, ctx.Bool("raw"))
 }
+
+func migrateScheme(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		return fmt.Errorf("max 1 argument: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	var (
+		root common.Hash
+		err  error
+	)
+	if ctx.NArg() == 1 {
+		root, err = parseRoot(ctx.Args().First())
+		if err != nil {
+			log.Error("Failed to resolve state root", "err", err)
+			return err
+		}
+	}
+	return pruner.MigrateScheme(db, root)
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/ethereum/go-ethereum/cmd/utils"
@@ -148,6 +149,34 @@ as the backend data source, making this command a lot faster.
 
 The argument is interpreted as block number or hash. If none is provided, the latest
 block is used.
+`,
+			},
+			{
+				Name:      "export",
+				Usage:     "Export the state of a block into a portable state file",
+				ArgsUsage: "<file> [? <blockHash> | <blockNum>]",
+				Action:    snapshotExportState,
+				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth snapshot export <file> [? <blockHash> | <blockNum>]
+will stream the accounts, storage slots and contract codes of the state of the
+given block (the latest block by default) from the snapshot into a chunked and
+checksummed state file. If the file ends with .gz, the output will be gzipped.
+`,
+			},
+			{
+				Name:      "import",
+				Usage:     "Import the state from a portable state file",
+				ArgsUsage: "<file>",
+				Action:    snapshotImportState,
+				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth snapshot import <file>
+will rebuild the state tries from a state file created by 'geth snapshot export'
+and verify the rebuilt state root against the one recorded in the file before
+writing anything. The tries are written along with the snapshot of the state,
+and the head is moved to the exported block if the chain contains it. The state
+can seed a new node, which still needs the chain up to the exported block.
 `,
 			},
 			{
@@ -661,6 +690,77 @@ func snapshotExportPreimages(ctx *cli.Context) error {
 	return utils.ExportSnapshotPreimages(chaindb, snaptree, ctx.Args().First(), root)
 }
 
+// snapshotExportState exports the state of a block into a state file.
+func snapshotExportState(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		utils.Fatalf("This command requires one or two arguments.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	defer chaindb.Close()
+
+	var header *types.Header
+	if ctx.NArg() > 1 {
+		arg := ctx.Args().Get(1)
+		if hashish(arg) {
+			hash := common.HexToHash(arg)
+			if number := rawdb.ReadHeaderNumber(chaindb, hash); number != nil {
+				header = rawdb.ReadHeader(chaindb, hash, *number)
+			}
+		} else {
+			number, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				return err
+			}
+			header = rawdb.ReadHeader(chaindb, rawdb.ReadCanonicalHash(chaindb, number), number)
+		}
+	} else {
+		header = rawdb.ReadHeadHeader(chaindb)
+	}
+	if header == nil {
+		return errors.New("block not found")
+	}
+	triedb := utils.MakeTrieDatabase(ctx, chaindb, false, true, false)
+	defer triedb.Close()
+
+	snapConfig := snapshot.Config{
+		CacheSize:  256,
+		Recovery:   false,
+		NoBuild:    true,
+		AsyncBuild: false,
+	}
+	snaptree, err := snapshot.New(snapConfig, chaindb, triedb, header.Root)
+	if err != nil {
+		return err
+	}
+	return utils.ExportState(chaindb, snaptree, ctx.Args().First(), header)
+}
+
+// snapshotImportState imports the state from a state file.
+func snapshotImportState(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	defer chaindb.Close()
+
+	scheme, err := rawdb.ParseStateScheme(ctx.String(utils.StateSchemeFlag.Name), chaindb)
+	if err != nil {
+		return err
+	}
+	header, err := utils.ImportState(chaindb, ctx.Args().First(), scheme)
+	if err != nil {
+		return err
+	}
+	log.Info("Imported state", "number", header.Number, "hash", header.Hash, "root", header.Root)
+	return nil
+}
+
 // checkAccount iterates the snap data layers, and looks up the given account
 // across all layers.
 func checkAccount(ctx *cli.Context) error {
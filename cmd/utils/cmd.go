@@ -309,6 +309,15 @@ func ImportHistory(chain *core.BlockChain, db ethdb.Database, dir string, networ
 				} else if status != core.CanonStatTy {
 					return fmt.Errorf("error inserting header %d, not canon: %v", it.Number(), status)
 				}
+				requests, err := it.GoatRequests()
+				if err != nil {
+					return fmt.Errorf("error reading goat requests %d: %w", it.Number(), err)
+				}
+				if requests != nil {
+					if want := block.Header().RequestsHash; want == nil || types.CalcRequestsHash(requests) != *want {
+						return fmt.Errorf("goat requests hash mismatch %d", it.Number())
+					}
+				}
 				if _, err := chain.InsertReceiptChain([]*types.Block{block}, []types.Receipts{receipts}, 2^64-1); err != nil {
 					return fmt.Errorf("error inserting body %d: %w", it.Number(), err)
 				}
@@ -448,7 +457,20 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 				if td == nil {
 					return fmt.Errorf("export failed on #%d: total difficulty not found", n)
 				}
-				if err := w.Add(block, receipts, td); err != nil {
+				if bc.Config().Goat == nil {
+					if err := w.Add(block, receipts, td); err != nil {
+						return err
+					}
+					continue
+				}
+				requests := bc.GetGoatRequests(block.Hash(), n)
+				if requests == nil {
+					if n != 0 {
+						return fmt.Errorf("export failed on #%d: goat requests not found", n)
+					}
+					requests = [][]byte{} // genesis has no requests
+				}
+				if err := w.AddWithRequests(block, receipts, requests, td); err != nil {
 					return err
 				}
 			}
@@ -863,3 +885,119 @@ func ExportChaindata(fn string, kind string, iter ChainDataIterator, interrupt c
 		"elapsed", common.PrettyDuration(time.Since(start)))
 	return nil
 }
+
+// ExportState exports the state of the given block from the snapshot into the
+// specified file in the portable state format.
+func ExportState(chaindb ethdb.Database, snaptree *snapshot.Tree, fn string, header *types.Header) error {
+	log.Info("Exporting state", "file", fn, "number", header.Number, "hash", header.Hash(), "root", header.Root)
+
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	// Enable gzip compressing if file name has gz suffix.
+	var (
+		writer io.Writer = fh
+		gz     *gzip.Writer
+	)
+	if strings.HasSuffix(fn, ".gz") {
+		gz = gzip.NewWriter(writer)
+		writer = gz
+	}
+	buf := bufio.NewWriter(writer)
+
+	err = snapshot.ExportState(buf, snaptree, chaindb, &snapshot.StateFileHeader{
+		Root:   header.Root,
+		Number: header.Number.Uint64(),
+		Hash:   header.Hash(),
+	})
+	// Flush and close the writers in order, a failure of any of them leaves
+	// the file truncated.
+	if ferr := buf.Flush(); err == nil {
+		err = ferr
+	}
+	if gz != nil {
+		if cerr := gz.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := fh.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ImportState imports the state from the specified file in the portable state
+// format, rebuilding the tries with the given node scheme. The state is marked
+// as synced, and the head is moved to its block if the chain contains it, unless
+// the current head block is later and has its state.
+func ImportState(chaindb ethdb.Database, fn string, scheme string) (*snapshot.StateFileHeader, error) {
+	log.Info("Importing state", "file", fn, "scheme", scheme)
+
+	// The path-based scheme keeps a single persistent state, refuse to mix
+	// the imported one into it.
+	if scheme == rawdb.PathScheme && len(rawdb.ReadAccountTrieNode(chaindb, nil)) != 0 {
+		return nil, errors.New("database already contains a path-based state")
+	}
+	open := func() (io.ReadCloser, error) {
+		fh, err := os.Open(fn)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasSuffix(fn, ".gz") {
+			return fh, nil
+		}
+		gz, err := gzip.NewReader(fh)
+		if err != nil {
+			fh.Close()
+			return nil, err
+		}
+		return &gzipFile{Reader: gz, file: fh}, nil
+	}
+	header, err := snapshot.ImportState(open, chaindb, scheme)
+	if err != nil {
+		return nil, err
+	}
+	// Reset the persistent state of the path-based scheme to the imported one
+	// and mark the state sync as finished.
+	batch := chaindb.NewBatch()
+	if scheme == rawdb.PathScheme {
+		rawdb.DeleteTrieJournal(batch)
+		rawdb.WritePersistentStateID(batch, 0)
+	}
+	rawdb.WriteSnapSyncStatusFlag(batch, rawdb.StateSyncFinished)
+
+	// Move the head to the block of the imported state, unless the chain lacks
+	// it or the current head is later and usable.
+	head := rawdb.ReadHeadBlock(chaindb)
+	switch {
+	case rawdb.ReadCanonicalHash(chaindb, header.Number) != header.Hash:
+		log.Warn("Block of the imported state is not in the chain", "number", header.Number, "hash", header.Hash)
+
+	case head != nil && head.NumberU64() >= header.Number && rawdb.HasTrieNode(chaindb, common.Hash{}, nil, head.Root(), scheme):
+		log.Info("Keeping current head with state", "number", head.Number(), "hash", head.Hash())
+
+	default:
+		rawdb.WriteHeadBlockHash(batch, header.Hash)
+		rawdb.WriteHeadFastBlockHash(batch, header.Hash)
+		if headHeader := rawdb.ReadHeadHeader(chaindb); headHeader == nil || headHeader.Number.Uint64() < header.Number {
+			rawdb.WriteHeadHeaderHash(batch, header.Hash)
+		}
+		log.Info("Moved head to the imported state", "number", header.Number, "hash", header.Hash)
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// gzipFile is a gzip decompressed file, closing both on Close.
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (f *gzipFile) Close() error {
+	f.Reader.Close()
+	return f.file.Close()
+}
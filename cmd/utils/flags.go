@@ -273,12 +273,33 @@ var (
 		Value:    ethconfig.Defaults.StateHistory,
 		Category: flags.StateCategory,
 	}
+	StateHistoryIndexFlag = &cli.BoolFlag{
+		Name:     "history.state.index",
+		Usage:    "Index the retained state histories for serving historical state (path scheme only)",
+		Category: flags.StateCategory,
+	}
 	TransactionHistoryFlag = &cli.Uint64Flag{
 		Name:     "history.transactions",
 		Usage:    "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	LogHistoryFlag = &cli.Uint64Flag{
+		Name:     "history.logs",
+		Usage:    "Number of recent blocks to maintain log index for (default = entire chain)",
+		Value:    ethconfig.Defaults.LogHistory,
+		Category: flags.StateCategory,
+	}
+	HistoryExpiryFlag = &cli.Uint64Flag{
+		Name:     "history.expiry",
+		Usage:    "Block number before which ancient block bodies and receipts are pruned (0 = keep entire chain)",
+		Category: flags.StateCategory,
+	}
+	HistoryEraFlag = &flags.DirectoryFlag{
+		Name:     "history.era",
+		Usage:    "Directory of Era1 archives to serve the pruned block bodies and receipts from",
+		Category: flags.StateCategory,
+	}
 	// Beacon client light sync settings
 	BeaconApiFlag = &cli.StringSliceFlag{
 		Name:     "beacon.api",
@@ -396,6 +417,12 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolPrivateLifetimeFlag = &cli.Uint64Flag{
+		Name:     "txpool.privatelifetime",
+		Usage:    "Number of blocks a private transaction is kept for",
+		Value:    ethconfig.Defaults.TxPool.PrivateLifetime,
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -498,6 +525,18 @@ var (
 		Value:    ethconfig.Defaults.Miner.Recommit,
 		Category: flags.MinerCategory,
 	}
+	MinerOrderingPolicyFlag = &cli.StringFlag{
+		Name:     "miner.ordering",
+		Usage:    "Ordering policy of the pending transactions in the built blocks (price, fifo, fair)",
+		Value:    ethconfig.Defaults.Miner.OrderingPolicy,
+		Category: flags.MinerCategory,
+	}
+	MinerSenderTxLimitFlag = &cli.IntFlag{
+		Name:     "miner.sendertxlimit",
+		Usage:    "Maximum number of transactions per sender in a block of the fair ordering policy",
+		Value:    ethconfig.Defaults.Miner.SenderTxLimit,
+		Category: flags.MinerCategory,
+	}
 	MinerPendingFeeRecipientFlag = &cli.StringFlag{
 		Name:     "miner.pending.feeRecipient",
 		Usage:    "0x prefixed public address for the pending block producer (not used for actual block production)",
@@ -535,6 +574,11 @@ var (
 		Usage:    "Record information useful for VM and contract debugging",
 		Category: flags.VMCategory,
 	}
+	VMParallelFlag = &cli.BoolFlag{
+		Name:     "vm.parallel",
+		Usage:    "Execute the transactions of imported blocks optimistically in parallel",
+		Category: flags.VMCategory,
+	}
 	VMTraceFlag = &cli.StringFlag{
 		Name:     "vmtrace",
 		Usage:    "Name of tracer which should record internal VM operations (costly)",
@@ -564,6 +608,18 @@ var (
 		Value:    ethconfig.Defaults.RPCTxFeeCap,
 		Category: flags.APICategory,
 	}
+	RPCLogRangeLimitFlag = &cli.Uint64Flag{
+		Name:     "rpc.lograngelimit",
+		Usage:    "Sets a cap on the number of blocks searched by a log query, longer ranges must be paged (0 = no cap)",
+		Value:    ethconfig.Defaults.LogRangeLimit,
+		Category: flags.APICategory,
+	}
+	RPCLogQueryLimitFlag = &cli.IntFlag{
+		Name:     "rpc.logquerylimit",
+		Usage:    "Sets a cap on the number of logs returned by a log query, more results must be paged (0 = no cap)",
+		Value:    ethconfig.Defaults.LogQueryLimit,
+		Category: flags.APICategory,
+	}
 	// Authenticated RPC HTTP settings
 	AuthListenFlag = &cli.StringFlag{
 		Name:     "authrpc.addr",
@@ -740,6 +796,21 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCRateLimitFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit",
+		Usage:    "Cost units per second each HTTP and WebSocket client may spend (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitBurstFlag = &cli.IntFlag{
+		Name:     "rpc.ratelimit.burst",
+		Usage:    "Cost units each HTTP and WebSocket client may spend at once, calls costing more are rejected (defaults to the rate limit, at least the largest method cost)",
+		Category: flags.APICategory,
+	}
+	RPCResponseCacheFlag = &cli.IntFlag{
+		Name:     "rpc.cache",
+		Usage:    "Megabytes of memory allocated to caching HTTP and WebSocket responses derived from finalized blocks (0 = disabled)",
+		Category: flags.APICategory,
+	}
 	EnablePersonal = &cli.BoolFlag{
 		Name:     "rpc.enabledeprecatedpersonal",
 		Usage:    "Enables the (deprecated) personal namespace",
@@ -1176,6 +1247,24 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+
+	if ctx.IsSet(RPCRateLimitFlag.Name) {
+		if cfg.RPCRateLimit == nil {
+			cfg.RPCRateLimit = new(rpc.RateLimitConfig)
+		}
+		cfg.RPCRateLimit.Client.Rate = ctx.Float64(RPCRateLimitFlag.Name)
+		// Allow the most expensive methods to be called by default
+		cfg.RPCRateLimit.Client.Burst = max(int(math.Ceil(cfg.RPCRateLimit.Client.Rate)), cfg.RPCRateLimit.MaxCost())
+	}
+	if ctx.IsSet(RPCRateLimitBurstFlag.Name) {
+		if cfg.RPCRateLimit == nil {
+			cfg.RPCRateLimit = new(rpc.RateLimitConfig)
+		}
+		cfg.RPCRateLimit.Client.Burst = ctx.Int(RPCRateLimitBurstFlag.Name)
+	}
+	if ctx.IsSet(RPCResponseCacheFlag.Name) {
+		cfg.RPCResponseCache = ctx.Int(RPCResponseCacheFlag.Name)
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1531,6 +1620,9 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.IsSet(TxPoolPrivateLifetimeFlag.Name) {
+		cfg.PrivateLifetime = ctx.Uint64(TxPoolPrivateLifetimeFlag.Name)
+	}
 }
 
 func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
@@ -1561,6 +1653,12 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.IsSet(MinerRecommitIntervalFlag.Name) {
 		cfg.Recommit = ctx.Duration(MinerRecommitIntervalFlag.Name)
 	}
+	if ctx.IsSet(MinerOrderingPolicyFlag.Name) {
+		cfg.OrderingPolicy = ctx.String(MinerOrderingPolicyFlag.Name)
+	}
+	if ctx.IsSet(MinerSenderTxLimitFlag.Name) {
+		cfg.SenderTxLimit = ctx.Int(MinerSenderTxLimitFlag.Name)
+	}
 	if ctx.IsSet(MinerNewPayloadTimeoutFlag.Name) {
 		log.Warn("The flag --miner.newpayload-timeout is deprecated and will be removed, please use --miner.recommit")
 		cfg.Recommit = ctx.Duration(MinerNewPayloadTimeoutFlag.Name)
@@ -1705,6 +1803,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(StateHistoryFlag.Name) {
 		cfg.StateHistory = ctx.Uint64(StateHistoryFlag.Name)
 	}
+	if ctx.IsSet(StateHistoryIndexFlag.Name) {
+		cfg.StateHistoryIndex = ctx.Bool(StateHistoryIndexFlag.Name)
+	}
 	if ctx.IsSet(StateSchemeFlag.Name) {
 		cfg.StateScheme = ctx.String(StateSchemeFlag.Name)
 	}
@@ -1720,6 +1821,15 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		log.Warn("The flag --txlookuplimit is deprecated and will be removed, please use --history.transactions")
 		cfg.TransactionHistory = ctx.Uint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.IsSet(LogHistoryFlag.Name) {
+		cfg.LogHistory = ctx.Uint64(LogHistoryFlag.Name)
+	}
+	if ctx.IsSet(HistoryExpiryFlag.Name) {
+		cfg.HistoryExpiry = ctx.Uint64(HistoryExpiryFlag.Name)
+	}
+	if ctx.IsSet(HistoryEraFlag.Name) {
+		cfg.HistoryEra = ctx.String(HistoryEraFlag.Name)
+	}
 	if ctx.String(GCModeFlag.Name) == "archive" && cfg.TransactionHistory != 0 {
 		cfg.TransactionHistory = 0
 		log.Warn("Disabled transaction unindexing for archive node")
@@ -1761,6 +1871,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.Bool(VMEnableDebugFlag.Name)
 	}
+	if ctx.IsSet(VMParallelFlag.Name) {
+		cfg.ParallelExecution = ctx.Bool(VMParallelFlag.Name)
+	}
 
 	if ctx.IsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.Uint64(RPCGlobalGasCapFlag.Name)
@@ -1776,6 +1889,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.IsSet(RPCLogRangeLimitFlag.Name) {
+		cfg.LogRangeLimit = ctx.Uint64(RPCLogRangeLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCLogQueryLimitFlag.Name) {
+		cfg.LogQueryLimit = ctx.Int(RPCLogQueryLimitFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -1958,7 +2077,9 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSyst
 // RegisterFilterAPI adds the eth log filtering RPC API to the node.
 func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
 	filterSystem := filters.NewFilterSystem(backend, filters.Config{
-		LogCacheSize: ethcfg.FilterLogCacheSize,
+		LogCacheSize:  ethcfg.FilterLogCacheSize,
+		LogRangeLimit: ethcfg.LogRangeLimit,
+		LogQueryLimit: ethcfg.LogQueryLimit,
 	})
 	stack.RegisterAPIs([]rpc.API{{
 		Namespace: "eth",
@@ -2170,6 +2291,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 		Preimages:           ctx.Bool(CachePreimagesFlag.Name),
 		StateScheme:         scheme,
 		StateHistory:        ctx.Uint64(StateHistoryFlag.Name),
+		StateHistoryIndex:   ctx.Bool(StateHistoryIndexFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
@@ -2191,6 +2313,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	}
 	vmcfg := vm.Config{
 		EnablePreimageRecording: ctx.Bool(VMEnableDebugFlag.Name),
+		ParallelExecution:       ctx.Bool(VMParallelFlag.Name),
 	}
 	if ctx.IsSet(VMTraceFlag.Name) {
 		if name := ctx.String(VMTraceFlag.Name); name != "" {
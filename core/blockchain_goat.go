@@ -0,0 +1,79 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/types/goattypes"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// GetGoatRequests retrieves the goat requests generated by the given block,
+// it returns nil if the block is unknown.
+func (bc *BlockChain) GetGoatRequests(hash common.Hash, number uint64) [][]byte {
+	return rawdb.ReadGoatRequests(bc.db, hash, number)
+}
+
+// GetGoatRewards retrieves the validator rewards paid to the recipient in the
+// canonical block range [from, to].
+func (bc *BlockChain) GetGoatRewards(recipient common.Address, from, to uint64) []*rawdb.GoatRewardEntry {
+	return rawdb.ReadGoatRewardEntries(bc.db, recipient, from, to)
+}
+
+// goatConfig is the goat relevant configuration which must be the same in the
+// execution and consensus clients.
+type goatConfig struct {
+	ChainID      *big.Int
+	Genesis      common.Hash
+	Predeploys   []common.Address
+	TaxBasePoint uint64
+	TaxMaxPoint  uint64
+	RequestTypes []byte
+	CLRootTime   *uint64 `rlp:"nil"`
+}
+
+// GoatConfigDigest returns the keccak256 hash of the RLP encoded goat config,
+// which consists of the chain id, the genesis hash, the predeploy addresses,
+// the foundation tax parameters, the request types and the goat fork times.
+func (bc *BlockChain) GoatConfigDigest() common.Hash {
+	config := &goatConfig{
+		ChainID: bc.chainConfig.ChainID,
+		Genesis: bc.genesisBlock.Hash(),
+		Predeploys: []common.Address{
+			goattypes.GoatTokenContract,
+			goattypes.GoatFoundationContract,
+			goattypes.BridgeContract,
+			goattypes.LockingContract,
+			goattypes.BitcoinContract,
+			goattypes.RelayerContract,
+			goattypes.RelayerExecutor,
+			goattypes.LockingExecutor,
+			goattypes.CLRootsContract,
+		},
+		TaxBasePoint: gfBasePoint.Uint64(),
+		TaxMaxPoint:  gfMaxBasePoint.Uint64(),
+	}
+	for typ := goattypes.GasRequestType; typ <= goattypes.RemoveVoterRequestType; typ++ {
+		config.RequestTypes = append(config.RequestTypes, typ)
+	}
+	if bc.chainConfig.Goat != nil {
+		config.CLRootTime = bc.chainConfig.Goat.CLRootTime
+	}
+	data, err := rlp.EncodeToBytes(config)
+	if err != nil {
+		log.Crit("Failed to encode goat config", "err", err)
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+// writeGoatIndexes writes the goat requests and the reward entries of a block
+// which is inserted without execution(e.g. snap sync or receipt import).
+func writeGoatIndexes(db ethdb.KeyValueWriter, block *types.Block, requests [][]byte) {
+	rawdb.WriteGoatRequests(db, block.Hash(), block.NumberU64(), requests)
+	rawdb.WriteGoatRewardEntriesByBlock(db, block)
+}
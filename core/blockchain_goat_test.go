@@ -0,0 +1,228 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/types/goattypes"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+func TestGoatRewardHistory(t *testing.T) {
+	var (
+		engine = beacon.NewFaker()
+
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		funds  = new(big.Int).Mul(big.NewInt(1e6), big.NewInt(params.Ether))
+		config = *params.AllGoatDebugChainConfig
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc:  types.GenesisAlloc{addr: {Balance: funds}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+
+		to        = common.HexToAddress("0x4a284d2835a3497e08b8b7fb30459a1c8229553d")
+		recipient = common.HexToAddress("0x94d76e24f818426ae84aa404140e8d5f60e10e7e")
+	)
+
+	var goatNonce uint64
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, engine, 3, func(i int, b *BlockGen) {
+		if i > 0 {
+			reward := &goattypes.DistributeRewardTx{
+				Id:        uint64(i),
+				Recipient: recipient,
+				Goat:      big.NewInt(int64(i * 100)),
+				GasReward: big.NewInt(int64(i * 10)),
+			}
+			b.AddTx(types.NewTx(types.NewGoatTx(goattypes.LockingModule, goattypes.LockingDistributeRewardAction, goatNonce, reward)))
+			goatNonce++
+		}
+		tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    uint64(i),
+			To:       &to,
+			Gas:      21000,
+			GasPrice: big.NewInt(1e9),
+			Value:    big.NewInt(0),
+		}), signer, key)
+		b.AddTx(tx)
+	})
+	check := func(chain *BlockChain) {
+		t.Helper()
+
+		for _, block := range blocks {
+			requests := chain.GetGoatRequests(block.Hash(), block.NumberU64())
+			if len(requests) == 0 {
+				t.Fatalf("block %d: goat requests not found", block.NumberU64())
+			}
+			if hash := types.CalcRequestsHash(requests); hash != *block.Header().RequestsHash {
+				t.Fatalf("block %d: requests hash mismatch: have %x want %x", block.NumberU64(), hash, *block.Header().RequestsHash)
+			}
+		}
+
+		rewards := chain.GetGoatRewards(recipient, 0, 3)
+		if len(rewards) != 2 {
+			t.Fatalf("reward count mismatch: have %d want 2", len(rewards))
+		}
+		for i, reward := range rewards {
+			block := blocks[i+1]
+			if reward.BlockNumber != block.NumberU64() || reward.BlockHash != block.Hash() {
+				t.Errorf("reward %d: block mismatch: have %d(%x) want %d(%x)", i, reward.BlockNumber, reward.BlockHash, block.NumberU64(), block.Hash())
+			}
+			if reward.TxIndex != 0 || reward.TxHash != block.Transactions()[0].Hash() {
+				t.Errorf("reward %d: tx mismatch: have %d(%x)", i, reward.TxIndex, reward.TxHash)
+			}
+			if reward.Goat.Int64() != int64((i+1)*100) || reward.GasReward.Int64() != int64((i+1)*10) {
+				t.Errorf("reward %d: amount mismatch: goat %s gas %s", i, reward.Goat, reward.GasReward)
+			}
+		}
+		if rewards := chain.GetGoatRewards(recipient, 3, 3); len(rewards) != 1 {
+			t.Errorf("reward count mismatch: have %d want 1", len(rewards))
+		}
+		if rewards := chain.GetGoatRewards(to, 0, 3); len(rewards) != 0 {
+			t.Errorf("reward count mismatch: have %d want 0", len(rewards))
+		}
+	}
+
+	// Executed blocks
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	check(chain)
+
+	// Blocks inserted with their receipts(snap sync), both into the ancient
+	// and the live stores
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		t.Fatalf("failed to create freezer db: %v", err)
+	}
+	defer db.Close()
+	snap, err := NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer snap.Stop()
+
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if n, err := snap.InsertHeaderChain(headers); err != nil {
+		t.Fatalf("header %d: failed to insert into chain: %v", n, err)
+	}
+	if n, err := snap.InsertReceiptChain(blocks, receipts, 1); err != nil {
+		t.Fatalf("block %d: failed to insert receipts into chain: %v", n, err)
+	}
+	check(snap)
+
+	// Receipts which don't match the requests hash are rejected
+	db = rawdb.NewMemoryDatabase()
+	bad, err := NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer bad.Stop()
+	if _, err := bad.InsertHeaderChain(headers); err != nil {
+		t.Fatalf("failed to insert headers: %v", err)
+	}
+	tampered := make([]types.Receipts, len(receipts))
+	copy(tampered, receipts)
+	receipt := *receipts[2][1]
+	receipt.CumulativeGasUsed += 21000
+	tampered[2] = types.Receipts{receipts[2][0], &receipt}
+	if _, err := bad.InsertReceiptChain(blocks, tampered, 0); err == nil || !strings.Contains(err.Error(), "goat requests hash mismatch") {
+		t.Fatalf("receipts with invalid goat requests inserted: %v", err)
+	}
+}
+
+func TestGoatSetCode(t *testing.T) {
+	var (
+		engine = beacon.NewFaker()
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		funds  = new(big.Int).Mul(big.NewInt(1e6), big.NewInt(params.Ether))
+		bb     = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		config = *params.AllGoatDebugChainConfig
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc: types.GenesisAlloc{
+				addr: {Balance: funds},
+				// The address 0xBBBB sstores 42 into slot 42
+				bb: {Code: []byte{byte(vm.PUSH1), 0x42, byte(vm.DUP1), byte(vm.SSTORE)}},
+			},
+		}
+	)
+	// The generated blocks are 10s apart, the fork happens at block 2
+	config.Goat = &params.GoatConfig{SetCodeTime: u64(20)}
+
+	for _, tt := range []struct {
+		number, time uint64
+		active       bool
+	}{
+		{1, 10, false},
+		{2, 20, true},
+	} {
+		if have := config.Rules(new(big.Int).SetUint64(tt.number), true, tt.time).IsGoatSetCode; have != tt.active {
+			t.Fatalf("time %d: rule mismatch, have %v, want %v", tt.time, have, tt.active)
+		}
+		if have := config.IsSetCode(new(big.Int).SetUint64(tt.number), tt.time); have != tt.active {
+			t.Fatalf("time %d: set code mismatch, have %v, want %v", tt.time, have, tt.active)
+		}
+	}
+	signer := types.LatestSigner(gspec.Config)
+	auth, _ := types.SignSetCode(key, types.SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(config.ChainID),
+		Address: bb,
+		Nonce:   1,
+	})
+	tx := types.MustSignNewTx(key, signer, &types.SetCodeTx{
+		ChainID:   uint256.MustFromBig(config.ChainID),
+		Nonce:     0,
+		To:        addr,
+		Gas:       500000,
+		GasFeeCap: uint256.NewInt(1e10),
+		GasTipCap: uint256.NewInt(2),
+		AuthList:  []types.SetCodeAuthorization{auth},
+	})
+	// Set code transactions are rejected before the fork
+	if _, err := types.Sender(types.MakeSigner(&config, big.NewInt(1), 10), tx); !errors.Is(err, types.ErrTxTypeNotSupported) {
+		t.Fatalf("unexpected sender error before the fork: %v", err)
+	}
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 2, func(i int, b *BlockGen) {
+		if i == 1 {
+			b.AddTx(tx)
+		}
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	// The sender is delegated to 0xBBBB, which ran in its context
+	state, _ := chain.State()
+	if code, want := state.GetCode(addr), types.AddressToDelegation(bb); !bytes.Equal(code, want) {
+		t.Fatalf("sender code mismatch: have %x, want %x", code, want)
+	}
+	fortyTwo := common.BytesToHash([]byte{0x42})
+	if have := state.GetState(addr, fortyTwo); have != fortyTwo {
+		t.Fatalf("sender storage mismatch: have %x, want %x", have, fortyTwo)
+	}
+}
@@ -19,6 +19,7 @@ package core
 import (
 	"fmt"
 	"math/big"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
@@ -622,7 +623,7 @@ func (cm *chainMaker) makeHeader(parent *types.Block, state *state.StateDB, engi
 	}
 
 	if cm.config.Goat != nil {
-		header.Extra = goattypes.EmptyGoatTxRoot
+		header.Extra = slices.Clone(goattypes.EmptyGoatTxRoot)
 	}
 	return header
 }
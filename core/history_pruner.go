@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// historyPruneInterval is the time interval between the attempts to prune the
+// newly frozen history.
+const historyPruneInterval = time.Minute
+
+// historyPruner is the module responsible for pruning the block bodies and
+// receipts before the configured boundary from the ancient store, as the
+// chain freezer moves them out of the active database.
+type historyPruner struct {
+	boundary uint64 // Block number before which the history is pruned
+	db       ethdb.Database
+	term     chan chan struct{}
+	closed   chan struct{}
+}
+
+// newHistoryPruner initializes the history pruner.
+func newHistoryPruner(boundary uint64, db ethdb.Database) *historyPruner {
+	pruner := &historyPruner{
+		boundary: boundary,
+		db:       db,
+		term:     make(chan chan struct{}),
+		closed:   make(chan struct{}),
+	}
+	go pruner.loop()
+
+	log.Info("Initialized history pruner", "boundary", boundary)
+	return pruner
+}
+
+// prune truncates the ancient bodies and receipts up to the boundary, or up
+// to the ancient head if the boundary is not frozen yet. The returned flag
+// reports whether the pruning is finished.
+func (pruner *historyPruner) prune() (bool, error) {
+	frozen, err := pruner.db.Ancients()
+	if err != nil {
+		return false, err
+	}
+	tail, err := pruner.db.Tail()
+	if err != nil {
+		return false, err
+	}
+	target := min(pruner.boundary, frozen)
+	if target > tail {
+		start := time.Now()
+		if _, err := pruner.db.TruncateTail(target); err != nil {
+			return false, err
+		}
+		log.Info("Pruned ancient history", "from", tail, "to", target, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return target == pruner.boundary, nil
+}
+
+// loop periodically prunes the history until the boundary is reached.
+func (pruner *historyPruner) loop() {
+	defer close(pruner.closed)
+
+	ticker := time.NewTicker(historyPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		done, err := pruner.prune()
+		if err != nil {
+			log.Error("Failed to prune ancient history", "err", err)
+			done = true
+		}
+		if done {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case ch := <-pruner.term:
+			close(ch)
+			return
+		}
+	}
+}
+
+// close shutdown the pruner. Safe to be called for multiple times.
+func (pruner *historyPruner) close() {
+	ch := make(chan struct{})
+	select {
+	case pruner.term <- ch:
+		<-ch
+	case <-pruner.closed:
+	}
+}
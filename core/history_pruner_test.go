@@ -0,0 +1,103 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// TestHistoryPruner tests that the ancient bodies and receipts before the
+// boundary are pruned, while the headers are retained.
+func TestHistoryPruner(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   types.GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 64, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), common.Address{0xaa}, big.NewInt(1), params.TxGas, gen.BaseFee(), nil), signer, key)
+		gen.AddTx(tx)
+	})
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		t.Fatalf("failed to create temp freezer db: %v", err)
+	}
+	defer db.Close()
+
+	chain, _ := NewBlockChain(db, DefaultCacheConfigWithScheme(rawdb.HashScheme), gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if n, err := chain.InsertHeaderChain(headers); err != nil {
+		t.Fatalf("failed to insert header %d: %v", n, err)
+	}
+	// Freeze the first half of the chain, which is partially pruned
+	if n, err := chain.InsertReceiptChain(blocks, receipts, 32); err != nil {
+		t.Fatalf("failed to insert receipt %d: %v", n, err)
+	}
+	chain.Stop()
+
+	config := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	config.HistoryExpiry = 40
+	chain, _ = NewBlockChain(db, config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	defer chain.Stop()
+
+	// The boundary is not frozen yet, the pruner prunes up to the frozen head
+	// and keeps waiting.
+	pruner := chain.historyPruner
+	select {
+	case <-pruner.closed:
+		t.Fatal("history pruner exited before reaching the boundary")
+	default:
+	}
+	for tail := chain.HistoryTail(); tail != 33; tail = chain.HistoryTail() {
+		if done, err := pruner.prune(); done || err != nil {
+			t.Fatalf("unexpected pruning result: %v %v", done, err)
+		}
+	}
+	for _, block := range blocks {
+		number := block.NumberU64()
+		if chain.GetHeaderByNumber(number) == nil {
+			t.Fatalf("header %d is missing", number)
+		}
+		pruned := number < 33
+		if have := chain.GetBlockByNumber(number) == nil; have != pruned {
+			t.Fatalf("block %d: unexpected body availability, pruned %v", number, pruned)
+		}
+		if have := chain.GetReceiptsByHash(block.Hash()) == nil; have != pruned {
+			t.Fatalf("block %d: unexpected receipts availability, pruned %v", number, pruned)
+		}
+	}
+	if chain.GetBlockByNumber(0) == nil {
+		t.Fatal("genesis block is pruned")
+	}
+}
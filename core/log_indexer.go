@@ -0,0 +1,147 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// logIndexThrottling is the time to wait between processing two consecutive
+	// index sections. It's useful during chain upgrades to prevent disk overload.
+	logIndexThrottling = 100 * time.Millisecond
+)
+
+// LogIndexer implements a core.ChainIndexer, building up an index of the log
+// addresses and positional topics of the canonical chain, which maps them to
+// the blocks containing the matching logs for fast log filtering.
+type LogIndexer struct {
+	size    uint64              // section size to generate the log index for
+	history uint64              // number of recent blocks to retain the log index for, 0 means all
+	db      ethdb.Database      // database instance to write index data and metadata into
+	section uint64              // Section is the section number being processed currently
+	entries map[string][]uint64 // Numbers of the blocks containing the logs, by index key
+
+	unavailable bool // Whether the receipts of a block in the current section are missing
+}
+
+// NewLogIndexer returns a chain indexer that generates the log index for the
+// canonical chain, retaining the index of the given number of recent blocks
+// (0 means the entire chain).
+func NewLogIndexer(db ethdb.Database, size, confirms, history uint64) *ChainIndexer {
+	backend := &LogIndexer{
+		db:      db,
+		size:    size,
+		history: history,
+	}
+	table := rawdb.NewTable(db, string(rawdb.LogIndexTablePrefix))
+
+	return NewChainIndexer(db, table, backend, size, confirms, logIndexThrottling, "logindex")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new log index section.
+func (l *LogIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	l.section, l.entries, l.unavailable = section, make(map[string][]uint64), false
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the logs of a new header
+// into the index. The receipts may be missing if the history is pruned or was
+// skipped by the sync, in which case the section is marked unavailable.
+func (l *LogIndexer) Process(ctx context.Context, header *types.Header) error {
+	// Skip loading the receipts if the block doesn't contain any log, or if
+	// the section can't be indexed anyway.
+	if l.unavailable || header.Bloom == (types.Bloom{}) {
+		return nil
+	}
+	number := header.Number.Uint64()
+	logs := rawdb.ReadLogs(l.db, header.Hash(), number)
+	if logs == nil {
+		log.Debug("Receipts unavailable for log indexing", "number", number, "section", l.section)
+		l.unavailable = true
+		return nil
+	}
+	for _, txLogs := range logs {
+		for _, log := range txLogs {
+			l.add(rawdb.LogIndexAddressKey(log.Address), number)
+			for i, topic := range log.Topics {
+				l.add(rawdb.LogIndexTopicKey(i, topic), number)
+			}
+		}
+	}
+	return nil
+}
+
+// add appends the block number to the entry of the index key.
+func (l *LogIndexer) add(key []byte, number uint64) {
+	numbers := l.entries[string(key)]
+	if len(numbers) > 0 && numbers[len(numbers)-1] == number {
+		return
+	}
+	l.entries[string(key)] = append(numbers, number)
+}
+
+// Commit implements core.ChainIndexerBackend, finalizing the log index section
+// and writing it out into the database. The sections out of the retained range
+// are unindexed meanwhile.
+//
+// An unavailable section is left without an index, the gap is filtered without
+// the index while the other sections are kept.
+func (l *LogIndexer) Commit() error {
+	batch := l.db.NewBatch()
+
+	// Drop the index of the section if it's reprocessed due to a reorg
+	rawdb.DeleteLogIndexSection(l.db, batch, l.section)
+	if l.unavailable {
+		log.Info("Skipping unavailable log index section", "section", l.section)
+	} else {
+		rawdb.WriteLogIndexSection(batch, l.section, l.entries)
+	}
+
+	if head := (l.section + 1) * l.size; l.history != 0 && head > l.history {
+		l.prune(batch, (head-l.history)/l.size)
+	}
+	return batch.Write()
+}
+
+// Prune implements core.ChainIndexerBackend, deleting the log index of the
+// sections older than the given threshold.
+func (l *LogIndexer) Prune(threshold uint64) error {
+	batch := l.db.NewBatch()
+	l.prune(batch, threshold)
+	return batch.Write()
+}
+
+// prune deletes the log index of the sections older than the given threshold
+// and forwards the index tail.
+func (l *LogIndexer) prune(batch ethdb.Batch, threshold uint64) {
+	tail := rawdb.ReadLogIndexTail(l.db)
+	if threshold <= tail {
+		return
+	}
+	for section := tail; section < threshold; section++ {
+		rawdb.DeleteLogIndexSection(l.db, batch, section)
+	}
+	rawdb.WriteLogIndexTail(batch, threshold)
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newLogIndexerTestChain writes a chain of 16 blocks into the database, with a
+// log from address i%3 in block i+1 for even i, and returns the headers.
+func newLogIndexerTestChain(db ethdb.Database) []*types.Header {
+	gspec := &Genesis{
+		Config:  params.TestChainConfig,
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 16, func(i int, gen *BlockGen) {
+		if i%2 == 1 {
+			return
+		}
+		receipt := types.NewReceipt(nil, false, 0)
+		receipt.Logs = []*types.Log{{Address: common.Address{byte(i % 3)}, Topics: []common.Hash{{byte(i)}}}}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		gen.AddUncheckedReceipt(receipt)
+		gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.Address{0xff}, big.NewInt(1), params.TxGas, gen.BaseFee(), nil))
+	})
+	headers := []*types.Header{gspec.ToBlock().Header()}
+	for i, block := range blocks {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+		headers = append(headers, block.Header())
+	}
+	return headers
+}
+
+// processLogIndexSection indexes a section of the headers.
+func processLogIndexSection(t *testing.T, indexer *LogIndexer, headers []*types.Header, section uint64) {
+	t.Helper()
+	if err := indexer.Reset(context.Background(), section, common.Hash{}); err != nil {
+		t.Fatalf("failed to reset section %d: %v", section, err)
+	}
+	for _, header := range headers[section*indexer.size : (section+1)*indexer.size] {
+		if err := indexer.Process(context.Background(), header); err != nil {
+			t.Fatalf("failed to process header %d: %v", header.Number, err)
+		}
+	}
+	if err := indexer.Commit(); err != nil {
+		t.Fatalf("failed to commit section %d: %v", section, err)
+	}
+}
+
+// TestLogIndexer tests that the log index sections are generated, regenerated
+// on reorgs and pruned out of the retained range.
+func TestLogIndexer(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		headers = newLogIndexerTestChain(db)
+		indexer = &LogIndexer{db: db, size: 4, history: 12}
+	)
+	process := func(section uint64) {
+		t.Helper()
+		processLogIndexSection(t, indexer, headers, section)
+	}
+	check := func(addr byte, want []uint64) {
+		t.Helper()
+		have, err := rawdb.ReadLogIndex(db, rawdb.LogIndexAddressKey(common.Address{addr}), 0, 4)
+		if err != nil {
+			t.Fatalf("failed to read log index: %v", err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Fatalf("log index mismatch of address %d: have %v, want %v", addr, have, want)
+		}
+	}
+	for section := uint64(0); section < 3; section++ {
+		process(section)
+	}
+	// Block i+1 emits from address i%3 for even i
+	check(0, []uint64{1, 7})
+	check(1, []uint64{5, 11})
+	check(2, []uint64{3, 9})
+
+	// Reprocess the second section without the logs, the stale entries must
+	// be dropped.
+	for i := 4; i < 8; i++ {
+		headers[i] = types.CopyHeader(headers[i])
+		headers[i].Bloom = types.Bloom{}
+	}
+	process(1)
+	check(0, []uint64{1})
+	check(1, []uint64{11})
+	check(2, []uint64{3, 9})
+
+	// Committing the fourth section moves the head beyond the retained range
+	process(3)
+	if tail := rawdb.ReadLogIndexTail(db); tail != 1 {
+		t.Fatalf("log index tail mismatch: have %d, want %d", tail, 1)
+	}
+	if rawdb.HasLogIndexSection(db, 0) {
+		t.Fatal("pruned log index section is present")
+	}
+	check(0, []uint64{13})
+	check(1, []uint64{11})
+	check(2, []uint64{9, 15})
+
+	// Prune explicitly up to the fourth section
+	if err := indexer.Prune(3); err != nil {
+		t.Fatalf("failed to prune log index: %v", err)
+	}
+	check(2, []uint64{15})
+	if tail := rawdb.ReadLogIndexTail(db); tail != 3 {
+		t.Fatalf("log index tail mismatch: have %d, want %d", tail, 3)
+	}
+}
+
+// Tests that the sections with missing receipts, e.g. due to pruned history,
+// are skipped without stalling the indexer or dropping the other sections.
+func TestLogIndexerUnavailable(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		headers = newLogIndexerTestChain(db)
+		indexer = &LogIndexer{db: db, size: 4}
+	)
+	// Prune the receipts of the second and third sections
+	for _, header := range headers[4:12] {
+		rawdb.DeleteReceipts(db, header.Hash(), header.Number.Uint64())
+	}
+	for section := uint64(0); section < 4; section++ {
+		processLogIndexSection(t, indexer, headers, section)
+	}
+	if tail := rawdb.ReadLogIndexTail(db); tail != 0 {
+		t.Fatalf("log index tail mismatch: have %d, want %d", tail, 0)
+	}
+	for section, want := range []bool{true, false, false, true} {
+		if have := rawdb.HasLogIndexSection(db, uint64(section)); have != want {
+			t.Fatalf("log index section %d presence mismatch: have %v, want %v", section, have, want)
+		}
+	}
+	have, err := rawdb.ReadLogIndex(db, rawdb.LogIndexAddressKey(common.Address{0}), 0, 4)
+	if err != nil {
+		t.Fatalf("failed to read log index: %v", err)
+	}
+	if want := []uint64{1, 13}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("log index mismatch: have %v, want %v", have, want)
+	}
+}
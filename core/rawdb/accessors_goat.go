@@ -0,0 +1,131 @@
+package rawdb
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/types/goattypes"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ReadGoatRequests retrieves the goat requests generated by the given block,
+// it returns nil if the requests are not found.
+func ReadGoatRequests(db ethdb.KeyValueReader, hash common.Hash, number uint64) [][]byte {
+	data, _ := db.Get(goatRequestsKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var requests [][]byte
+	if err := rlp.DecodeBytes(data, &requests); err != nil {
+		log.Error("Invalid goat requests RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return requests
+}
+
+// WriteGoatRequests stores the goat requests generated by the given block.
+func WriteGoatRequests(db ethdb.KeyValueWriter, hash common.Hash, number uint64, requests [][]byte) {
+	data, err := rlp.EncodeToBytes(requests)
+	if err != nil {
+		log.Crit("Failed to encode goat requests", "err", err)
+	}
+	if err := db.Put(goatRequestsKey(number, hash), data); err != nil {
+		log.Crit("Failed to store goat requests", "err", err)
+	}
+}
+
+// DeleteGoatRequests removes the goat requests associated with a block hash.
+func DeleteGoatRequests(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(goatRequestsKey(number, hash)); err != nil {
+		log.Crit("Failed to delete goat requests", "err", err)
+	}
+}
+
+// GoatRewardEntry is a validator reward paid by a DistributeRewardTx.
+type GoatRewardEntry struct {
+	BlockHash common.Hash
+	TxHash    common.Hash
+	Id        uint64
+	Goat      *big.Int
+	GasReward *big.Int
+
+	// Derived fields, they are resolved from the database key
+	Recipient   common.Address `rlp:"-"`
+	BlockNumber uint64         `rlp:"-"`
+	TxIndex     uint64         `rlp:"-"`
+}
+
+// WriteGoatRewardEntriesByBlock indexes the rewards distributed by the goat
+// txs of the given block by their recipient.
+func WriteGoatRewardEntriesByBlock(db ethdb.KeyValueWriter, block *types.Block) {
+	for i, tx := range block.Transactions() {
+		if !tx.IsGoatTx() {
+			// goat txs are always at the beginning of the block
+			break
+		}
+		reward, ok := tx.GoatTx().(*goattypes.DistributeRewardTx)
+		if !ok {
+			continue
+		}
+		entry := &GoatRewardEntry{
+			BlockHash: block.Hash(),
+			TxHash:    tx.Hash(),
+			Id:        reward.Id,
+			Goat:      reward.Goat,
+			GasReward: reward.GasReward,
+		}
+		data, err := rlp.EncodeToBytes(entry)
+		if err != nil {
+			log.Crit("Failed to encode goat reward entry", "err", err)
+		}
+		if err := db.Put(goatRewardKey(reward.Recipient, block.NumberU64(), uint16(i)), data); err != nil {
+			log.Crit("Failed to store goat reward entry", "err", err)
+		}
+	}
+}
+
+// ReadGoatRewardEntries retrieves the rewards paid to the recipient in the
+// canonical blocks of range [from, to]. Entries which were written by blocks
+// reorged out of the canonical chain are skipped.
+func ReadGoatRewardEntries(db ethdb.Database, recipient common.Address, from, to uint64) []*GoatRewardEntry {
+	prefix := append(append([]byte{}, goatRewardPrefix...), recipient.Bytes()...)
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var (
+		entries   []*GoatRewardEntry
+		canonical = make(map[uint64]common.Hash)
+	)
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8+2 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > to {
+			break
+		}
+		entry := new(GoatRewardEntry)
+		if err := rlp.DecodeBytes(it.Value(), entry); err != nil {
+			log.Error("Invalid goat reward entry RLP", "recipient", recipient, "number", number, "err", err)
+			continue
+		}
+		hash, ok := canonical[number]
+		if !ok {
+			hash = ReadCanonicalHash(db, number)
+			canonical[number] = hash
+		}
+		if entry.BlockHash != hash {
+			continue
+		}
+		entry.Recipient = recipient
+		entry.BlockNumber = number
+		entry.TxIndex = uint64(binary.BigEndian.Uint16(key[len(prefix)+8:]))
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,80 @@
+package rawdb
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/types/goattypes"
+)
+
+func TestGoatRequestsStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	hash := common.HexToHash("0x01")
+	requests := [][]byte{goattypes.NewGasRequest(1, big.NewInt(100)).Encode(), {goattypes.LockRequestType, 0x01}}
+	if res := ReadGoatRequests(db, hash, 1); res != nil {
+		t.Fatalf("non existent requests returned: %x", res)
+	}
+	WriteGoatRequests(db, hash, 1, requests)
+	res := ReadGoatRequests(db, hash, 1)
+	if len(res) != len(requests) {
+		t.Fatalf("requests length mismatch: have %d want %d", len(res), len(requests))
+	}
+	for i := range requests {
+		if !bytes.Equal(res[i], requests[i]) {
+			t.Fatalf("request %d mismatch: have %x want %x", i, res[i], requests[i])
+		}
+	}
+	DeleteGoatRequests(db, hash, 1)
+	if res := ReadGoatRequests(db, hash, 1); res != nil {
+		t.Fatalf("deleted requests returned: %x", res)
+	}
+}
+
+func TestGoatRewardEntries(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	recipient := common.HexToAddress("0x94d76e24f818426ae84aa404140e8d5f60e10e7e")
+	newBlock := func(number int64, goat int64) *types.Block {
+		reward := &goattypes.DistributeRewardTx{
+			Id:        uint64(number),
+			Recipient: recipient,
+			Goat:      big.NewInt(goat),
+			GasReward: big.NewInt(1),
+		}
+		txs := []*types.Transaction{types.NewTx(types.NewGoatTx(goattypes.LockingModule, goattypes.LockingDistributeRewardAction, uint64(number), reward))}
+		return types.NewBlockWithHeader(&types.Header{Number: big.NewInt(number)}).WithBody(types.Body{Transactions: txs})
+	}
+
+	var canonical []*types.Block
+	for i := int64(1); i <= 3; i++ {
+		block := newBlock(i, 100)
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		WriteGoatRewardEntriesByBlock(db, block)
+		canonical = append(canonical, block)
+	}
+	// A block reorged out of the canonical chain
+	WriteGoatRewardEntriesByBlock(db, newBlock(4, 200))
+
+	entries := ReadGoatRewardEntries(db, recipient, 1, 4)
+	if len(entries) != 3 {
+		t.Fatalf("entries length mismatch: have %d want 3", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.BlockHash != canonical[i].Hash() || entry.BlockNumber != canonical[i].NumberU64() {
+			t.Errorf("entry %d: block mismatch: have %x want %x", i, entry.BlockHash, canonical[i].Hash())
+		}
+		if entry.Recipient != recipient || entry.Goat.Int64() != 100 {
+			t.Errorf("entry %d: reward mismatch: recipient %x goat %s", i, entry.Recipient, entry.Goat)
+		}
+	}
+	if entries := ReadGoatRewardEntries(db, recipient, 2, 2); len(entries) != 1 {
+		t.Errorf("entries length mismatch: have %d want 1", len(entries))
+	}
+	if entries := ReadGoatRewardEntries(db, common.Address{0x1}, 1, 3); len(entries) != 0 {
+		t.Errorf("entries length mismatch: have %d want 0", len(entries))
+	}
+}
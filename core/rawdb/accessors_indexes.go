@@ -179,3 +179,39 @@ func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
 		log.Crit("Failed to delete bloom bits", "err", it.Error())
 	}
 }
+
+// DeleteBloomBitsDb removes the legacy bloombits index and the progress of its
+// chain indexer, returning the number of deleted entries.
+func DeleteBloomBitsDb(db ethdb.KeyValueStore) (int, error) {
+	var deleted int
+	for _, prefix := range [][]byte{bloomBitsPrefix, BloomBitsIndexPrefix} {
+		it := db.NewIterator(prefix, nil)
+		batch := db.NewBatch()
+		for it.Next() {
+			if bytes.Equal(prefix, bloomBitsPrefix) && len(it.Key()) != len(bloomBitsPrefix)+2+8+32 {
+				continue
+			}
+			if err := batch.Delete(it.Key()); err != nil {
+				it.Release()
+				return deleted, err
+			}
+			deleted++
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return deleted, err
+				}
+				batch.Reset()
+			}
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return deleted, err
+		}
+		if err := batch.Write(); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
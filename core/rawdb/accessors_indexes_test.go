@@ -154,3 +154,26 @@ func TestDeleteBloomBits(t *testing.T) {
 	check(1, 1, params.MainnetGenesisHash, true)
 	check(1, 1, params.SepoliaGenesisHash, true)
 }
+
+func TestDeleteBloomBitsDb(t *testing.T) {
+	db := NewMemoryDatabase()
+	for s := uint64(0); s < 3; s++ {
+		WriteBloomBits(db, 0, s, params.MainnetGenesisHash, []byte{0x01, 0x02})
+	}
+	db.Put(append(append([]byte{}, BloomBitsIndexPrefix...), []byte("count")...), []byte{0x03})
+	db.Put([]byte("unrelated"), []byte{0x01})
+
+	deleted, err := DeleteBloomBitsDb(db)
+	if err != nil {
+		t.Fatalf("Failed to delete bloombits: %v", err)
+	}
+	if deleted != 4 {
+		t.Fatalf("Deleted entries mismatch: have %d, want %d", deleted, 4)
+	}
+	if bits, _ := ReadBloomBits(db, 0, 1, params.MainnetGenesisHash); len(bits) > 0 {
+		t.Fatalf("Bloombits should be removed")
+	}
+	if has, _ := db.Has([]byte("unrelated")); !has {
+		t.Fatalf("Unrelated entry is removed")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// The log index maps the emitting addresses and the positional topics of the
+// logs to the numbers of the blocks containing them. The entries are grouped
+// by sections and keyed by the index key first, so that the blocks matching a
+// key over a range of sections can be read by a single iteration.
+
+// logIndexAddressKind is the index key kind of the log addresses, the topics
+// are denoted by their position plus one.
+const logIndexAddressKind = 0
+
+// errInvalidLogIndex is returned if a log index entry can't be decoded.
+var errInvalidLogIndex = errors.New("invalid log index entry")
+
+// LogIndexAddressKey returns the index key of the logs emitted by the address.
+func LogIndexAddressKey(address common.Address) []byte {
+	return append([]byte{logIndexAddressKind}, address.Bytes()...)
+}
+
+// LogIndexTopicKey returns the index key of the logs with the topic at the
+// given position.
+func LogIndexTopicKey(position int, topic common.Hash) []byte {
+	return append([]byte{byte(position + 1)}, topic.Bytes()...)
+}
+
+// logIndexKeyLength returns the length of the index key of the given kind.
+func logIndexKeyLength(kind byte) int {
+	if kind == logIndexAddressKind {
+		return 1 + common.AddressLength
+	}
+	return 1 + common.HashLength
+}
+
+// encodeLogIndexNumbers encodes the ascending block numbers, the first one as
+// is and the others as the delta to the previous one.
+func encodeLogIndexNumbers(numbers []uint64) []byte {
+	var (
+		buf  = make([]byte, 0, len(numbers)*2)
+		prev uint64
+	)
+	for _, number := range numbers {
+		buf = binary.AppendUvarint(buf, number-prev)
+		prev = number
+	}
+	return buf
+}
+
+// decodeLogIndexNumbers decodes the block numbers and appends them to the slice.
+func decodeLogIndexNumbers(numbers []uint64, blob []byte) ([]uint64, error) {
+	var prev uint64
+	for len(blob) > 0 {
+		delta, n := binary.Uvarint(blob)
+		if n <= 0 {
+			return nil, errInvalidLogIndex
+		}
+		prev += delta
+		numbers = append(numbers, prev)
+		blob = blob[n:]
+	}
+	return numbers, nil
+}
+
+// ReadLogIndex retrieves the ascending numbers of the blocks containing the
+// logs matching the index key within the sections [from, to).
+func ReadLogIndex(db ethdb.Iteratee, key []byte, from, to uint64) ([]uint64, error) {
+	prefix := append(append([]byte{}, logIndexPrefix...), key...)
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var (
+		numbers []uint64
+		err     error
+	)
+	for it.Next() {
+		if len(it.Key()) != len(prefix)+8 {
+			continue
+		}
+		if binary.BigEndian.Uint64(it.Key()[len(prefix):]) >= to {
+			break
+		}
+		if numbers, err = decodeLogIndexNumbers(numbers, it.Value()); err != nil {
+			return nil, err
+		}
+	}
+	return numbers, it.Error()
+}
+
+// WriteLogIndexSection stores the log index of a section, which maps the index
+// keys to the ascending numbers of the blocks containing the matching logs.
+func WriteLogIndexSection(db ethdb.KeyValueWriter, section uint64, entries map[string][]uint64) {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var manifest []byte
+	for _, key := range keys {
+		if err := db.Put(logIndexKey([]byte(key), section), encodeLogIndexNumbers(entries[key])); err != nil {
+			log.Crit("Failed to store log index", "err", err)
+		}
+		manifest = append(manifest, key...)
+	}
+	if err := db.Put(logIndexSectionKey(section), manifest); err != nil {
+		log.Crit("Failed to store log index section", "err", err)
+	}
+}
+
+// HasLogIndexSection reports whether the log index of a section is stored.
+func HasLogIndexSection(db ethdb.KeyValueReader, section uint64) bool {
+	has, _ := db.Has(logIndexSectionKey(section))
+	return has
+}
+
+// DeleteLogIndexSection removes the log index of a section. The index keys of
+// the section are read from the given database and the deletions are written
+// into the given writer.
+func DeleteLogIndexSection(db ethdb.KeyValueReader, writer ethdb.KeyValueWriter, section uint64) {
+	manifest, _ := db.Get(logIndexSectionKey(section))
+	for len(manifest) > 0 {
+		n := logIndexKeyLength(manifest[0])
+		if len(manifest) < n {
+			log.Error("Invalid log index section", "section", section)
+			break
+		}
+		if err := writer.Delete(logIndexKey(manifest[:n], section)); err != nil {
+			log.Crit("Failed to delete log index", "err", err)
+		}
+		manifest = manifest[n:]
+	}
+	if err := writer.Delete(logIndexSectionKey(section)); err != nil {
+		log.Crit("Failed to delete log index section", "err", err)
+	}
+}
+
+// ReadLogIndexTail retrieves the number of the oldest indexed section.
+func ReadLogIndexTail(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(logIndexTailKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteLogIndexTail stores the number of the oldest indexed section.
+func WriteLogIndexTail(db ethdb.KeyValueWriter, section uint64) {
+	if err := db.Put(logIndexTailKey, encodeBlockNumber(section)); err != nil {
+		log.Crit("Failed to store the log index tail", "err", err)
+	}
+}
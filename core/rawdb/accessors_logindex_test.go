@@ -0,0 +1,81 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests the log index storage and retrieval operations.
+func TestLogIndexStorage(t *testing.T) {
+	var (
+		db      = NewMemoryDatabase()
+		addr    = LogIndexAddressKey(common.Address{0x1})
+		other   = LogIndexAddressKey(common.Address{0x2})
+		topic0  = LogIndexTopicKey(0, common.Hash{0x1})
+		topic1  = LogIndexTopicKey(1, common.Hash{0x1})
+		numbers = [][]uint64{{1, 5, 9}, {17, 300}, {1024}}
+	)
+	if reflect.DeepEqual(topic0, topic1) {
+		t.Fatal("topic keys of different positions collide")
+	}
+	for section, nums := range numbers {
+		WriteLogIndexSection(db, uint64(section), map[string][]uint64{
+			string(addr):   nums,
+			string(topic1): {nums[0]},
+		})
+	}
+	check := func(key []byte, from, to uint64, want []uint64) {
+		t.Helper()
+		have, err := ReadLogIndex(db, key, from, to)
+		if err != nil {
+			t.Fatalf("failed to read log index: %v", err)
+		}
+		if len(have) != len(want) || (len(want) > 0 && !reflect.DeepEqual(have, want)) {
+			t.Fatalf("log index mismatch [%d, %d): have %v, want %v", from, to, have, want)
+		}
+	}
+	check(addr, 0, 3, []uint64{1, 5, 9, 17, 300, 1024})
+	check(addr, 1, 2, []uint64{17, 300})
+	check(addr, 2, 10, []uint64{1024})
+	check(topic1, 0, 3, []uint64{1, 17, 1024})
+	check(topic0, 0, 3, nil)
+	check(other, 0, 3, nil)
+
+	// Delete a section and check that only its entries are dropped
+	if !HasLogIndexSection(db, 1) {
+		t.Fatal("log index section is missing")
+	}
+	DeleteLogIndexSection(db, db, 1)
+	if HasLogIndexSection(db, 1) {
+		t.Fatal("deleted log index section is present")
+	}
+	check(addr, 0, 3, []uint64{1, 5, 9, 1024})
+	check(topic1, 0, 3, []uint64{1, 1024})
+
+	// Check the index tail
+	if tail := ReadLogIndexTail(db); tail != 0 {
+		t.Fatalf("unexpected log index tail: %d", tail)
+	}
+	WriteLogIndexTail(db, 2)
+	if tail := ReadLogIndexTail(db); tail != 2 {
+		t.Fatalf("log index tail mismatch: have %d, want %d", tail, 2)
+	}
+}
@@ -137,6 +137,101 @@ func WritePersistentStateID(db ethdb.KeyValueWriter, number uint64) {
 	}
 }
 
+// ReadStateHistoryIndexTail retrieves the id of the oldest indexed state history,
+// nil is returned if the state history index is not available.
+func ReadStateHistoryIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(stateHistoryIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	id := binary.BigEndian.Uint64(data)
+	return &id
+}
+
+// WriteStateHistoryIndexTail stores the id of the oldest indexed state history.
+func WriteStateHistoryIndexTail(db ethdb.KeyValueWriter, id uint64) {
+	if err := db.Put(stateHistoryIndexTailKey, encodeBlockNumber(id)); err != nil {
+		log.Crit("Failed to store the state history index tail", "err", err)
+	}
+}
+
+// DeleteStateHistoryIndexTail deletes the id of the oldest indexed state history.
+func DeleteStateHistoryIndexTail(db ethdb.KeyValueWriter) {
+	if err := db.Delete(stateHistoryIndexTailKey); err != nil {
+		log.Crit("Failed to delete the state history index tail", "err", err)
+	}
+}
+
+// WriteStateHistoryAccountIndex marks the account as mutated in the specified
+// state history.
+func WriteStateHistoryAccountIndex(db ethdb.KeyValueWriter, address common.Address, id uint64) {
+	if err := db.Put(stateHistoryAccountIndexKey(address, id), nil); err != nil {
+		log.Crit("Failed to store state history account index", "err", err)
+	}
+}
+
+// DeleteStateHistoryAccountIndex deletes the account mark of the specified
+// state history.
+func DeleteStateHistoryAccountIndex(db ethdb.KeyValueWriter, address common.Address, id uint64) {
+	if err := db.Delete(stateHistoryAccountIndexKey(address, id)); err != nil {
+		log.Crit("Failed to delete state history account index", "err", err)
+	}
+}
+
+// WriteStateHistoryStorageIndex marks the storage slot as mutated in the
+// specified state history.
+func WriteStateHistoryStorageIndex(db ethdb.KeyValueWriter, address common.Address, slot common.Hash, id uint64) {
+	if err := db.Put(stateHistoryStorageIndexKey(address, slot, id), nil); err != nil {
+		log.Crit("Failed to store state history storage index", "err", err)
+	}
+}
+
+// DeleteStateHistoryStorageIndex deletes the storage slot mark of the specified
+// state history.
+func DeleteStateHistoryStorageIndex(db ethdb.KeyValueWriter, address common.Address, slot common.Hash, id uint64) {
+	if err := db.Delete(stateHistoryStorageIndexKey(address, slot, id)); err != nil {
+		log.Crit("Failed to delete state history storage index", "err", err)
+	}
+}
+
+// IterateStateHistoryAccountIndex returns an iterator over the ids of the state
+// histories in which the account is mutated, starting from the given id. The
+// id is the last 8 bytes of the iterated keys.
+func IterateStateHistoryAccountIndex(db ethdb.Iteratee, address common.Address, start uint64) ethdb.Iterator {
+	prefix := append(append([]byte{}, StateHistoryAccountIndexPrefix...), address.Bytes()...)
+	return db.NewIterator(prefix, encodeBlockNumber(start))
+}
+
+// IterateStateHistoryStorageIndex returns an iterator over the ids of the state
+// histories in which the storage slot is mutated, starting from the given id.
+// The id is the last 8 bytes of the iterated keys.
+func IterateStateHistoryStorageIndex(db ethdb.Iteratee, address common.Address, slot common.Hash, start uint64) ethdb.Iterator {
+	prefix := append(append(append([]byte{}, StateHistoryStorageIndexPrefix...), address.Bytes()...), slot.Bytes()...)
+	return db.NewIterator(prefix, encodeBlockNumber(start))
+}
+
+// ReadSchemeMigrationStatus retrieves the serialized progress of the state
+// scheme migration.
+func ReadSchemeMigrationStatus(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(schemeMigrationKey)
+	return data
+}
+
+// WriteSchemeMigrationStatus stores the serialized progress of the state scheme
+// migration.
+func WriteSchemeMigrationStatus(db ethdb.KeyValueWriter, status []byte) {
+	if err := db.Put(schemeMigrationKey, status); err != nil {
+		log.Crit("Failed to store scheme migration status", "err", err)
+	}
+}
+
+// DeleteSchemeMigrationStatus deletes the progress of the state scheme migration.
+func DeleteSchemeMigrationStatus(db ethdb.KeyValueWriter) {
+	if err := db.Delete(schemeMigrationKey); err != nil {
+		log.Crit("Failed to remove scheme migration status", "err", err)
+	}
+}
+
 // ReadTrieJournal retrieves the serialized in-memory trie nodes of layers saved at
 // the last shutdown.
 func ReadTrieJournal(db ethdb.KeyValueReader) []byte {
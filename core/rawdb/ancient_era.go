@@ -0,0 +1,370 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// eraOpenLimit is the maximum number of Era1 archives kept open at once.
+const eraOpenLimit = 16
+
+var (
+	// errEraUnavailable is returned if the requested block is not covered by
+	// any of the Era1 archives.
+	errEraUnavailable = errors.New("block is not available in era archives")
+
+	// errEraMismatch is returned if the block in the Era1 archive doesn't match
+	// the canonical one.
+	errEraMismatch = errors.New("block in era archive is not canonical")
+
+	// errEraCorrupted is returned if the body or the receipts of the block in
+	// the Era1 archive don't match the roots in its header.
+	errEraCorrupted = errors.New("block data in era archive doesn't match the header")
+)
+
+// eraArchive is the block range covered by an Era1 archive.
+type eraArchive struct {
+	path  string
+	start uint64
+	count uint64
+}
+
+// eraStore is the set of Era1 archives in a directory, which are opened on
+// demand for retrieving the pruned block bodies and receipts.
+type eraStore struct {
+	archives  []eraArchive            // Archives sorted by the first block number
+	newHasher func() types.TrieHasher // Constructor of the hasher verifying the bodies and receipts
+
+	lock sync.Mutex                     // Lock protecting the opened archives
+	open lru.BasicLRU[string, *era.Era] // Recently opened archives
+}
+
+// newEraStore indexes the Era1 archives in the given directory.
+func newEraStore(dir string, newHasher func() types.TrieHasher) (*eraStore, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var archives []eraArchive
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".era1") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		e, err := era.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open era archive %s: %w", entry.Name(), err)
+		}
+		archives = append(archives, eraArchive{path: path, start: e.Start(), count: e.Count()})
+		e.Close()
+	}
+	if len(archives) == 0 {
+		return nil, fmt.Errorf("no era archives found in %s", dir)
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].start < archives[j].start
+	})
+	for i := 1; i < len(archives); i++ {
+		if prev := archives[i-1]; prev.start+prev.count > archives[i].start {
+			return nil, fmt.Errorf("era archives %s and %s overlap", prev.path, archives[i].path)
+		}
+	}
+	log.Info("Indexed era archives", "dir", dir, "archives", len(archives),
+		"first", archives[0].start, "last", archives[len(archives)-1].start+archives[len(archives)-1].count-1)
+
+	return &eraStore{
+		archives:  archives,
+		newHasher: newHasher,
+		open:      lru.NewBasicLRU[string, *era.Era](eraOpenLimit),
+	}, nil
+}
+
+// archive returns the archive containing the given block.
+func (s *eraStore) archive(number uint64) (eraArchive, bool) {
+	i := sort.Search(len(s.archives), func(i int) bool {
+		return s.archives[i].start+s.archives[i].count > number
+	})
+	if i == len(s.archives) || s.archives[i].start > number {
+		return eraArchive{}, false
+	}
+	return s.archives[i], true
+}
+
+// has reports whether the given block is covered by the archives.
+func (s *eraStore) has(number uint64) bool {
+	_, ok := s.archive(number)
+	return ok
+}
+
+// read retrieves the item of the given table of the block from the archives,
+// verifying the archived header against the given canonical hash and the item
+// against the roots in the header. The receipts are converted into the storage
+// format used by the freezer.
+func (s *eraStore) read(kind string, number uint64, hash []byte) ([]byte, error) {
+	archive, ok := s.archive(number)
+	if !ok {
+		return nil, errEraUnavailable
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, ok := s.open.Get(archive.path)
+	if !ok {
+		var err error
+		if e, err = era.Open(archive.path); err != nil {
+			return nil, err
+		}
+		if s.open.Len() >= eraOpenLimit {
+			_, old, _ := s.open.RemoveOldest()
+			old.Close()
+		}
+		s.open.Add(archive.path, e)
+	}
+	blob, err := e.GetRawHeaderByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(crypto.Keccak256(blob), hash) {
+		return nil, errEraMismatch
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(blob, header); err != nil {
+		return nil, err
+	}
+	switch kind {
+	case ChainFreezerBodiesTable:
+		blob, err := e.GetRawBodyByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		body := new(types.Body)
+		if err := rlp.DecodeBytes(blob, body); err != nil {
+			return nil, err
+		}
+		if !s.verifyBody(header, body) {
+			return nil, errEraCorrupted
+		}
+		return blob, nil
+
+	case ChainFreezerReceiptTable:
+		blob, err := e.GetRawReceiptsByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		var receipts types.Receipts
+		if err := rlp.DecodeBytes(blob, &receipts); err != nil {
+			return nil, err
+		}
+		if types.DeriveSha(receipts, s.newHasher()) != header.ReceiptHash {
+			return nil, errEraCorrupted
+		}
+		stored := make([]*types.ReceiptForStorage, len(receipts))
+		for i, receipt := range receipts {
+			stored[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		return rlp.EncodeToBytes(stored)
+
+	default:
+		return nil, errUnknownTable
+	}
+}
+
+// verifyBody reports whether the transactions, uncles and withdrawals of the
+// body match the roots in the header.
+func (s *eraStore) verifyBody(header *types.Header, body *types.Body) bool {
+	if types.DeriveSha(types.Transactions(body.Transactions), s.newHasher()) != header.TxHash {
+		return false
+	}
+	if types.CalcUncleHash(body.Uncles) != header.UncleHash {
+		return false
+	}
+	if header.WithdrawalsHash == nil {
+		return body.Withdrawals == nil
+	}
+	return body.Withdrawals != nil && types.DeriveSha(types.Withdrawals(body.Withdrawals), s.newHasher()) == *header.WithdrawalsHash
+}
+
+// close closes all the opened archives.
+func (s *eraStore) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, path := range s.open.Keys() {
+		e, _ := s.open.Peek(path)
+		e.Close()
+	}
+	s.open.Purge()
+}
+
+// eraHistoryReader is an ancient reader serving the pruned block bodies and
+// receipts from the Era1 archives.
+type eraHistoryReader struct {
+	ethdb.AncientReaderOp
+	store *eraStore
+}
+
+// pruned reports whether the item of the given table is pruned from the
+// ancient store and should be served from the archives.
+func (r *eraHistoryReader) pruned(kind string, number uint64) bool {
+	if !chainFreezerPrunable[kind] {
+		return false
+	}
+	tail, err := r.AncientReaderOp.Tail()
+	return err == nil && number < tail
+}
+
+// retrieve reads the item of the given table from the archives, checking it
+// against the canonical block hash in the ancient store.
+func (r *eraHistoryReader) retrieve(kind string, number uint64) ([]byte, error) {
+	hash, err := r.AncientReaderOp.Ancient(ChainFreezerHashTable, number)
+	if err != nil {
+		return nil, err
+	}
+	return r.store.read(kind, number, hash)
+}
+
+// HasAncient returns an indicator whether the specified data exists in the
+// ancient store or the archives.
+func (r *eraHistoryReader) HasAncient(kind string, number uint64) (bool, error) {
+	if r.pruned(kind, number) {
+		return r.store.has(number), nil
+	}
+	return r.AncientReaderOp.HasAncient(kind, number)
+}
+
+// Ancient retrieves an ancient binary blob, falling back to the archives if
+// it has been pruned from the ancient store.
+func (r *eraHistoryReader) Ancient(kind string, number uint64) ([]byte, error) {
+	if r.pruned(kind, number) {
+		return r.retrieve(kind, number)
+	}
+	return r.AncientReaderOp.Ancient(kind, number)
+}
+
+// AncientRange retrieves multiple items in sequence, the pruned ones are read
+// from the archives.
+func (r *eraHistoryReader) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	if !r.pruned(kind, start) {
+		return r.AncientReaderOp.AncientRange(kind, start, count, maxBytes)
+	}
+	var (
+		items [][]byte
+		size  uint64
+	)
+	for number := start; number < start+count; number++ {
+		if !r.pruned(kind, number) {
+			rest := uint64(0)
+			if maxBytes != 0 {
+				if size >= maxBytes {
+					break
+				}
+				rest = maxBytes - size
+			}
+			tail, err := r.AncientReaderOp.AncientRange(kind, number, start+count-number, rest)
+			if err != nil {
+				break
+			}
+			items = append(items, tail...)
+			break
+		}
+		item, err := r.retrieve(kind, number)
+		if err != nil {
+			if len(items) == 0 {
+				return nil, err
+			}
+			break
+		}
+		if len(items) > 0 && maxBytes != 0 && size+uint64(len(item)) > maxBytes {
+			break
+		}
+		items = append(items, item)
+		size += uint64(len(item))
+	}
+	return items, nil
+}
+
+// eraHistoryDB is a database wrapper serving the block bodies and receipts
+// pruned from the ancient store out of a directory of Era1 archives.
+type eraHistoryDB struct {
+	ethdb.Database
+	store *eraStore
+}
+
+// NewEraHistoryDatabase wraps the database to serve the pruned ancient block
+// bodies and receipts from the Era1 archives in the given directory. The
+// archived headers are verified against the canonical hashes, and the bodies
+// and receipts against the header roots computed by the given hasher.
+func NewEraHistoryDatabase(db ethdb.Database, dir string, newHasher func() types.TrieHasher) (ethdb.Database, error) {
+	store, err := newEraStore(dir, newHasher)
+	if err != nil {
+		return nil, err
+	}
+	return &eraHistoryDB{Database: db, store: store}, nil
+}
+
+// reader returns the ancient reader falling back to the archives.
+func (db *eraHistoryDB) reader(op ethdb.AncientReaderOp) *eraHistoryReader {
+	return &eraHistoryReader{AncientReaderOp: op, store: db.store}
+}
+
+// HasAncient returns an indicator whether the specified data exists in the
+// ancient store or the archives.
+func (db *eraHistoryDB) HasAncient(kind string, number uint64) (bool, error) {
+	return db.reader(db.Database).HasAncient(kind, number)
+}
+
+// Ancient retrieves an ancient binary blob, falling back to the archives if
+// it has been pruned from the ancient store.
+func (db *eraHistoryDB) Ancient(kind string, number uint64) ([]byte, error) {
+	return db.reader(db.Database).Ancient(kind, number)
+}
+
+// AncientRange retrieves multiple items in sequence, the pruned ones are read
+// from the archives.
+func (db *eraHistoryDB) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	return db.reader(db.Database).AncientRange(kind, start, count, maxBytes)
+}
+
+// ReadAncients runs the given read operation while ensuring that no writes
+// take place on the ancient store.
+func (db *eraHistoryDB) ReadAncients(fn func(ethdb.AncientReaderOp) error) error {
+	return db.Database.ReadAncients(func(op ethdb.AncientReaderOp) error {
+		return fn(db.reader(op))
+	})
+}
+
+// Close closes the archives as well as the wrapped database.
+func (db *eraHistoryDB) Close() error {
+	db.store.close()
+	return db.Database.Close()
+}
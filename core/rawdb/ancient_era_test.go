@@ -0,0 +1,280 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era"
+)
+
+// writeTestEra writes the given blocks into an Era1 archive in the directory.
+func writeTestEra(t *testing.T, dir string, blocks []*types.Block, receipts []types.Receipts) {
+	t.Helper()
+
+	f, err := os.Create(filepath.Join(dir, era.Filename("test", int(blocks[0].NumberU64())/era.MaxEra1Size, common.Hash{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	builder := era.NewBuilder(f)
+	for i, block := range blocks {
+		if err := builder.Add(block, receipts[i], big.NewInt(int64(block.NumberU64()+1))); err != nil {
+			t.Fatalf("failed to add block %d: %v", block.NumberU64(), err)
+		}
+	}
+	if _, err := builder.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newEraTestHasher creates the hasher verifying the archived test blocks.
+func newEraTestHasher() types.TrieHasher {
+	return newTestHasher()
+}
+
+// makeEraTestBlocks creates blocks with two transactions each, whose headers
+// commit to the transactions and to the receipts of makeEraTestReceipts.
+func makeEraTestBlocks(n int) []*types.Block {
+	blocks := makeTestBlocks(n, 2)
+	receipts := makeEraTestReceipts(blocks)
+	for i, block := range blocks {
+		blocks[i] = types.NewBlock(block.Header(), &types.Body{Transactions: block.Transactions()}, receipts[i], newTestHasher())
+	}
+	return blocks
+}
+
+// makeEraTestReceipts creates the receipts of the blocks with two transactions.
+func makeEraTestReceipts(blocks []*types.Block) []types.Receipts {
+	receipts := make([]types.Receipts, len(blocks))
+	for i := range blocks {
+		receipts[i] = types.Receipts{
+			{
+				Type:              types.LegacyTxType,
+				Status:            types.ReceiptStatusSuccessful,
+				CumulativeGasUsed: uint64(i),
+				Logs:              []*types.Log{{Address: common.Address{byte(i)}, Topics: []common.Hash{{0x1}}, Data: []byte{0x2}}},
+			},
+			{
+				Type:              types.DynamicFeeTxType,
+				Status:            types.ReceiptStatusFailed,
+				CumulativeGasUsed: uint64(i) + 1,
+				Logs:              []*types.Log{},
+			},
+		}
+	}
+	return receipts
+}
+
+func TestEraHistoryDatabase(t *testing.T) {
+	t.Parallel()
+
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database with ancient backend: %v", err)
+	}
+	defer db.Close()
+
+	var (
+		blocks   = makeEraTestBlocks(100)
+		receipts = makeEraTestReceipts(blocks)
+		bodies   [][]byte
+		stored   [][]byte
+	)
+	if _, err := WriteAncientBlocks(db, blocks, receipts, big.NewInt(1)); err != nil {
+		t.Fatalf("failed to write ancient blocks: %v", err)
+	}
+	for _, block := range blocks {
+		bodies = append(bodies, ReadBodyRLP(db, block.Hash(), block.NumberU64()))
+		stored = append(stored, ReadReceiptsRLP(db, block.Hash(), block.NumberU64()))
+	}
+	// Archive the first 64 blocks and prune the first 80 ones
+	dir := t.TempDir()
+	writeTestEra(t, dir, blocks[:64], receipts[:64])
+
+	if _, err := db.TruncateTail(80); err != nil {
+		t.Fatalf("failed to truncate tail: %v", err)
+	}
+	for _, block := range blocks[:80] {
+		if HasBody(db, block.Hash(), block.NumberU64()) || HasReceipts(db, block.Hash(), block.NumberU64()) {
+			t.Fatalf("block %d: pruned history is accessible", block.NumberU64())
+		}
+		if ReadBodyRLP(db, block.Hash(), block.NumberU64()) != nil {
+			t.Fatalf("block %d: pruned body is accessible", block.NumberU64())
+		}
+	}
+	if ReadHeader(db, blocks[0].Hash(), 0) == nil {
+		t.Fatal("header of pruned block is missing")
+	}
+	// Serve the pruned history from the archive
+	edb, err := NewEraHistoryDatabase(db, dir, newEraTestHasher)
+	if err != nil {
+		t.Fatalf("failed to open era history database: %v", err)
+	}
+	for i, block := range blocks {
+		body := ReadBodyRLP(edb, block.Hash(), block.NumberU64())
+		receipt := ReadReceiptsRLP(edb, block.Hash(), block.NumberU64())
+		if i >= 64 && i < 80 {
+			if body != nil || receipt != nil {
+				t.Fatalf("block %d: unavailable history is accessible", i)
+			}
+			continue
+		}
+		if !bytes.Equal(body, bodies[i]) {
+			t.Fatalf("block %d: body mismatch", i)
+		}
+		if !bytes.Equal(receipt, stored[i]) {
+			t.Fatalf("block %d: receipts mismatch", i)
+		}
+	}
+	if block := ReadBlock(edb, blocks[10].Hash(), 10); block == nil || block.Hash() != blocks[10].Hash() {
+		t.Fatal("failed to read block from archive")
+	}
+	// Retrieve a range across the archive and the ancient store
+	items, err := edb.AncientRange(ChainFreezerBodiesTable, 60, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to retrieve range: %v", err)
+	}
+	if len(items) != 4 || !bytes.Equal(items[3], bodies[63]) {
+		t.Fatalf("unexpected range, have %d items", len(items))
+	}
+	if _, err := edb.AncientRange(ChainFreezerReceiptTable, 78, 4, 0); err == nil {
+		t.Fatal("expected error for unavailable range")
+	}
+	items, err = edb.AncientRange(ChainFreezerReceiptTable, 62, 40, 0)
+	if err != nil {
+		t.Fatalf("failed to retrieve range: %v", err)
+	}
+	if len(items) != 2 || !bytes.Equal(items[1], stored[63]) {
+		t.Fatalf("unexpected range, have %d items", len(items))
+	}
+	items, err = edb.AncientRange(ChainFreezerHashTable, 0, 100, 0)
+	if err != nil || len(items) != 100 {
+		t.Fatalf("failed to retrieve hashes: %d items, %v", len(items), err)
+	}
+}
+
+func TestEraHistoryMismatch(t *testing.T) {
+	t.Parallel()
+
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database with ancient backend: %v", err)
+	}
+	defer db.Close()
+
+	blocks := makeTestBlocks(10, 1)
+	if _, err := WriteAncientBlocks(db, blocks, make([]types.Receipts, 10), big.NewInt(1)); err != nil {
+		t.Fatalf("failed to write ancient blocks: %v", err)
+	}
+	if _, err := db.TruncateTail(10); err != nil {
+		t.Fatalf("failed to truncate tail: %v", err)
+	}
+	// Archive a different chain with the same numbers
+	var (
+		dir   = t.TempDir()
+		forks = make([]*types.Block, len(blocks))
+	)
+	for i := range forks {
+		forks[i] = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i)), Extra: []byte("fork")})
+	}
+	writeTestEra(t, dir, forks, make([]types.Receipts, 10))
+
+	edb, err := NewEraHistoryDatabase(db, dir, newEraTestHasher)
+	if err != nil {
+		t.Fatalf("failed to open era history database: %v", err)
+	}
+	if body := ReadBodyRLP(edb, blocks[5].Hash(), 5); body != nil {
+		t.Fatal("non-canonical body is served")
+	}
+	if _, err := edb.Ancient(ChainFreezerBodiesTable, 5); err != errEraMismatch {
+		t.Fatalf("unexpected error, want %v, have %v", errEraMismatch, err)
+	}
+}
+
+func TestEraHistoryCorrupted(t *testing.T) {
+	t.Parallel()
+
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database with ancient backend: %v", err)
+	}
+	defer db.Close()
+
+	var (
+		blocks   = makeEraTestBlocks(10)
+		receipts = makeEraTestReceipts(blocks)
+	)
+	if _, err := WriteAncientBlocks(db, blocks, receipts, big.NewInt(1)); err != nil {
+		t.Fatalf("failed to write ancient blocks: %v", err)
+	}
+	if _, err := db.TruncateTail(10); err != nil {
+		t.Fatalf("failed to truncate tail: %v", err)
+	}
+	// Archive the canonical headers with the transactions of the odd blocks
+	// dropped, and the receipts of the even ones reordered
+	var (
+		dir      = t.TempDir()
+		tampered = make([]*types.Block, len(blocks))
+		archived = make([]types.Receipts, len(blocks))
+	)
+	for i, block := range blocks {
+		tampered[i], archived[i] = block, receipts[i]
+		if i%2 == 1 {
+			tampered[i] = types.NewBlockWithHeader(block.Header()).WithBody(types.Body{Transactions: block.Transactions()[:1]})
+		} else {
+			archived[i] = types.Receipts{receipts[i][1], receipts[i][0]}
+		}
+	}
+	writeTestEra(t, dir, tampered, archived)
+
+	edb, err := NewEraHistoryDatabase(db, dir, newEraTestHasher)
+	if err != nil {
+		t.Fatalf("failed to open era history database: %v", err)
+	}
+	for i, block := range blocks {
+		body, err := edb.Ancient(ChainFreezerBodiesTable, block.NumberU64())
+		if i%2 == 1 {
+			if err != errEraCorrupted {
+				t.Fatalf("block %d: unexpected body error, want %v, have %v", i, errEraCorrupted, err)
+			}
+		} else if err != nil || !bytes.Equal(body, ReadBodyRLP(edb, block.Hash(), block.NumberU64())) {
+			t.Fatalf("block %d: failed to read body: %v", i, err)
+		}
+		_, err = edb.Ancient(ChainFreezerReceiptTable, block.NumberU64())
+		if i%2 == 0 {
+			if err != errEraCorrupted {
+				t.Fatalf("block %d: unexpected receipts error, want %v, have %v", i, errEraCorrupted, err)
+			}
+		} else if err != nil {
+			t.Fatalf("block %d: failed to read receipts: %v", i, err)
+		}
+	}
+	if ReadReceiptsRLP(edb, blocks[0].Hash(), 0) != nil {
+		t.Fatal("tampered receipts are served")
+	}
+	if ReadBodyRLP(edb, blocks[1].Hash(), 1) != nil {
+		t.Fatal("tampered body is served")
+	}
+}
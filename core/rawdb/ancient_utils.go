@@ -17,11 +17,13 @@
 package rawdb
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/gofrs/flock"
 )
 
 type tableSize struct {
@@ -144,3 +146,41 @@ func InspectFreezerTable(ancient string, freezerName string, tableName string, s
 	table.dumpIndexStdout(start, end)
 	return nil
 }
+
+// RecompactFreezerTable rewrites a specific freezer table with the given
+// compression, optionally relocating it to another directory. The passed
+// ancient indicates the path of root ancient directory where the freezers
+// are stored, which must not be in use.
+func RecompactFreezerTable(ancient string, freezerName string, tableName string, config FreezerRecompaction) error {
+	var (
+		path      string
+		tables    map[string]bool
+		tableSize uint32
+	)
+	switch freezerName {
+	case ChainFreezerName:
+		path, tables, tableSize = resolveChainFreezerDir(ancient), chainFreezerNoSnappy, freezerTableSize
+	case MerkleStateFreezerName, VerkleStateFreezerName:
+		path, tables, tableSize = filepath.Join(ancient, freezerName), stateFreezerNoSnappy, stateHistoryTableSize
+	default:
+		return fmt.Errorf("unknown freezer, supported ones: %v", freezers)
+	}
+	noSnappy, exist := tables[tableName]
+	if !exist {
+		var names []string
+		for name := range tables {
+			names = append(names, name)
+		}
+		return fmt.Errorf("unknown table, supported ones: %v", names)
+	}
+	// Hold the lock of the freezer to prevent it from being opened meanwhile
+	lock := flock.New(filepath.Join(path, "FLOCK"))
+	if locked, err := lock.TryLock(); err != nil {
+		return err
+	} else if !locked {
+		return errors.New("locking failed, the freezer is in use")
+	}
+	defer lock.Unlock()
+
+	return recompactTable(path, tableName, tableSize, noSnappy, config)
+}
@@ -0,0 +1,255 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// The compression algorithms supported by the freezer tables. The algorithm
+// of a table is denoted by the extension of its index and data files, so the
+// tables are self-describing regardless of the configuration.
+const (
+	FreezerCompressionNone   = "none"
+	FreezerCompressionSnappy = "snappy"
+	FreezerCompressionZstd   = "zstd"
+)
+
+// zstdRawDictID is the identifier of the zstd dictionaries made of raw content.
+const zstdRawDictID = 0x67657468
+
+// zstdDictMagic is the magic number of the dictionaries trained by zstd.
+var zstdDictMagic = []byte{0x37, 0xa4, 0x30, 0xec}
+
+// FreezerCompression configures the compression of the items in a freezer table.
+type FreezerCompression struct {
+	Algorithm string // Compression algorithm, one of none, snappy and zstd
+	Level     int    // Zstd compression level, the default one is used if zero
+	Dict      []byte // Optional zstd dictionary, either trained by zstd or raw content
+}
+
+// validate checks whether the compression configuration is supported.
+func (c FreezerCompression) validate() error {
+	switch c.Algorithm {
+	case FreezerCompressionNone, FreezerCompressionSnappy:
+		if c.Level != 0 || len(c.Dict) != 0 {
+			return fmt.Errorf("compression level and dictionary are not supported by %s", c.Algorithm)
+		}
+		return nil
+	case FreezerCompressionZstd:
+		if c.Level < 0 {
+			return fmt.Errorf("invalid zstd compression level %d", c.Level)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown compression algorithm %q", c.Algorithm)
+	}
+}
+
+// freezerCodec compresses and decompresses the items of a freezer table. It's
+// shared by the readers and the writer of the table, so it must be safe for
+// concurrent use.
+type freezerCodec interface {
+	// compress compresses the data, reusing the given buffer if possible.
+	compress(dst, data []byte) []byte
+
+	// decompress decompresses the data into a newly allocated slice.
+	decompress(data []byte) ([]byte, error)
+
+	// close releases the resources held by the codec.
+	close()
+}
+
+// snappyCodec compresses the items in snappy block format.
+type snappyCodec struct{}
+
+func (snappyCodec) compress(dst, data []byte) []byte {
+	// The snappy library does not care what the capacity of the buffer is,
+	// but only checks the length. If the length is too small, it will
+	// allocate a brand new buffer.
+	// To avoid that, we check the required size here, and grow the size of the
+	// buffer to utilize the full capacity.
+	if n := snappy.MaxEncodedLen(len(data)); len(dst) < n {
+		if cap(dst) < n {
+			dst = make([]byte, n)
+		}
+		dst = dst[:n]
+	}
+	return snappy.Encode(dst, data)
+}
+
+func (snappyCodec) decompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
+
+func (snappyCodec) close() {}
+
+// zstdConfig is the configuration of a zstd compressed table, persisted along
+// with the table as the dictionary is required for decompression.
+type zstdConfig struct {
+	Level uint64
+	Dict  []byte
+}
+
+// zstdCodec compresses the items as zstd frames, with an optional dictionary.
+type zstdCodec struct {
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+func newZstdCodec(level int, dict []byte) (*zstdCodec, error) {
+	var (
+		eopts = []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		dopts []zstd.DOption
+	)
+	if level != 0 {
+		eopts = append(eopts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	if len(dict) != 0 {
+		if bytes.HasPrefix(dict, zstdDictMagic) {
+			eopts = append(eopts, zstd.WithEncoderDict(dict))
+			dopts = append(dopts, zstd.WithDecoderDicts(dict))
+		} else {
+			eopts = append(eopts, zstd.WithEncoderDictRaw(zstdRawDictID, dict))
+			dopts = append(dopts, zstd.WithDecoderDictRaw(zstdRawDictID, dict))
+		}
+	}
+	enc, err := zstd.NewWriter(nil, eopts...)
+	if err != nil {
+		return nil, err
+	}
+	dec, err := zstd.NewReader(nil, dopts...)
+	if err != nil {
+		enc.Close()
+		return nil, err
+	}
+	return &zstdCodec{enc: enc, dec: dec}, nil
+}
+
+func (c *zstdCodec) compress(dst, data []byte) []byte {
+	return c.enc.EncodeAll(data, dst[:0])
+}
+
+func (c *zstdCodec) decompress(data []byte) ([]byte, error) {
+	// Empty items are encoded as nothing at all.
+	if len(data) == 0 {
+		return []byte{}, nil
+	}
+	return c.dec.DecodeAll(data, nil)
+}
+
+func (c *zstdCodec) close() {
+	c.enc.Close()
+	c.dec.Close()
+}
+
+// indexFileName returns the name of the index file of the table compressed by
+// the given algorithm.
+func indexFileName(name string, algorithm string) string {
+	switch algorithm {
+	case FreezerCompressionNone:
+		return fmt.Sprintf("%s.ridx", name) // raw index file
+	case FreezerCompressionZstd:
+		return fmt.Sprintf("%s.zidx", name) // zstd index file
+	default:
+		return fmt.Sprintf("%s.cidx", name) // compressed index file
+	}
+}
+
+// dataFileName returns the name of the numbered data file of the table
+// compressed by the given algorithm.
+func dataFileName(name string, algorithm string, num uint32) string {
+	switch algorithm {
+	case FreezerCompressionNone:
+		return fmt.Sprintf("%s.%04d.rdat", name, num)
+	case FreezerCompressionZstd:
+		return fmt.Sprintf("%s.%04d.zdat", name, num)
+	default:
+		return fmt.Sprintf("%s.%04d.cdat", name, num)
+	}
+}
+
+// zstdConfigFileName returns the name of the file storing the zstd configuration.
+func zstdConfigFileName(name string) string {
+	return fmt.Sprintf("%s.zstd", name)
+}
+
+// tableCompression returns the compression algorithm of the table stored in
+// the given directory. The tables recompacted with zstd are detected by their
+// index file, otherwise the given default is used.
+func tableCompression(path, name string, def string) string {
+	if _, err := os.Stat(filepath.Join(path, indexFileName(name, FreezerCompressionZstd))); err == nil {
+		return FreezerCompressionZstd
+	}
+	return def
+}
+
+// openCodec opens the codec of the table compressed by the given algorithm.
+// Nil is returned if the table is not compressed.
+func openCodec(path, name string, algorithm string) (freezerCodec, error) {
+	switch algorithm {
+	case FreezerCompressionNone:
+		return nil, nil
+	case FreezerCompressionSnappy:
+		return snappyCodec{}, nil
+	case FreezerCompressionZstd:
+		blob, err := os.ReadFile(filepath.Join(path, zstdConfigFileName(name)))
+		if err != nil {
+			return nil, err
+		}
+		var config zstdConfig
+		if err := rlp.DecodeBytes(blob, &config); err != nil {
+			return nil, err
+		}
+		return newZstdCodec(int(config.Level), config.Dict)
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q", algorithm)
+	}
+}
+
+// writeZstdConfig persists the zstd configuration of the table.
+func writeZstdConfig(path, name string, level int, dict []byte) error {
+	blob, err := rlp.EncodeToBytes(&zstdConfig{Level: uint64(level), Dict: dict})
+	if err != nil {
+		return err
+	}
+	return writeFileSync(filepath.Join(path, zstdConfigFileName(name)), blob)
+}
+
+// writeFileSync writes the data into the file and flushes it to disk.
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -214,11 +214,19 @@ type MemoryFreezer struct {
 	readonly   bool                    // Flag if the freezer is only for reading
 	lock       sync.RWMutex            // Lock to protect fields
 	tables     map[string]*memoryTable // Tables for storing everything
+	prunable   map[string]bool         // Tables pruned by tail truncation, nil means all
 	writeBatch *memoryBatch            // Pre-allocated write batch
 }
 
 // NewMemoryFreezer initializes an in-memory freezer instance.
 func NewMemoryFreezer(readonly bool, tableName map[string]bool) *MemoryFreezer {
+	return newMemoryFreezer(readonly, tableName, nil)
+}
+
+// newMemoryFreezer initializes an in-memory freezer instance in which only the
+// given prunable tables are truncated from the tail. All the tables are prunable
+// if nil is given.
+func newMemoryFreezer(readonly bool, tableName map[string]bool, prunable map[string]bool) *MemoryFreezer {
 	tables := make(map[string]*memoryTable)
 	for name := range tableName {
 		tables[name] = newMemoryTable(name)
@@ -227,6 +235,7 @@ func NewMemoryFreezer(readonly bool, tableName map[string]bool) *MemoryFreezer {
 		writeBatch: newMemoryBatch(),
 		readonly:   readonly,
 		tables:     tables,
+		prunable:   prunable,
 	}
 }
 
@@ -380,7 +389,10 @@ func (f *MemoryFreezer) TruncateTail(tail uint64) (uint64, error) {
 	if old >= tail {
 		return old, nil
 	}
-	for _, table := range f.tables {
+	for kind, table := range f.tables {
+		if f.prunable != nil && !f.prunable[kind] {
+			continue
+		}
 		if err := table.truncateTail(tail); err != nil {
 			return 0, err
 		}
@@ -0,0 +1,388 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// FreezerRecompaction configures the rewriting of a freezer table.
+type FreezerRecompaction struct {
+	// Compression is the compression applied to the rewritten items. The
+	// current algorithm of the table is kept if it's left empty.
+	Compression FreezerCompression
+
+	// DictSize is the size of the raw zstd dictionary sampled from the items
+	// of the table, used only if no dictionary is given.
+	DictSize int
+
+	// Directory is the directory to store the rewritten table in, e.g. a
+	// faster disk for the frequently accessed tables. The current directory
+	// of the table is kept if it's left empty.
+	Directory string
+}
+
+// recompactMarker is persisted once the rewritten table has been fully staged.
+// It's the point of no return of the recompaction: the original table is only
+// replaced afterwards, and the replacement is resumed on the next open if it's
+// interrupted.
+type recompactMarker struct {
+	Dir   string   // Directory to store the rewritten table in
+	Files []string // Names of the files belonging to the rewritten table
+}
+
+// tablePointerFile returns the path of the file recording the directory of a
+// relocated table, stored in the freezer directory.
+func tablePointerFile(path, name string) string {
+	return filepath.Join(path, fmt.Sprintf("%s.path", name))
+}
+
+// recompactMarkerFile returns the path of the recompaction marker of a table,
+// stored in the freezer directory.
+func recompactMarkerFile(path, name string) string {
+	return filepath.Join(path, fmt.Sprintf("%s.recompact", name))
+}
+
+// recompactStagingDir returns the directory for staging the rewritten table.
+func recompactStagingDir(dir, name string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.staging", name))
+}
+
+// tableDir resolves the directory where the table is stored, which is the
+// freezer directory unless the table has been relocated.
+func tableDir(path, name string) (string, error) {
+	blob, err := os.ReadFile(tablePointerFile(path, name))
+	if os.IsNotExist(err) {
+		return path, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	dir := string(blob)
+
+	// The relocated table must be present, otherwise an empty one would be
+	// created and all the other tables be truncated to match it.
+	for _, algorithm := range []string{FreezerCompressionNone, FreezerCompressionSnappy, FreezerCompressionZstd} {
+		if _, err := os.Stat(filepath.Join(dir, indexFileName(name, algorithm))); err == nil {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("freezer table %s is missing in %s", name, dir)
+}
+
+// isTableFile reports whether the file with the given name belongs to the table.
+func isTableFile(name, file string) bool {
+	rest, ok := strings.CutPrefix(file, name+".")
+	if !ok {
+		return false
+	}
+	switch rest {
+	case "ridx", "cidx", "zidx", "meta", "zstd":
+		return true
+	}
+	num, ext, ok := strings.Cut(rest, ".")
+	if !ok || len(num) < 4 || strings.Trim(num, "0123456789") != "" {
+		return false
+	}
+	return ext == "rdat" || ext == "cdat" || ext == "zdat"
+}
+
+// removeTableFiles deletes the files of the table in the given directory,
+// except the ones to keep.
+func removeTableFiles(dir, name string, keep map[string]bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || keep[entry.Name()] || !isTableFile(name, entry.Name()) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameDir reports whether the two paths denote the same directory.
+func sameDir(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && a == b
+}
+
+// finishRecompaction replaces the table with the staged one if the marker of
+// a committed recompaction is present. It's idempotent, so that it can be
+// resumed after a crash at any point.
+func finishRecompaction(path, name string, readonly bool) error {
+	blob, err := os.ReadFile(recompactMarkerFile(path, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if readonly {
+		return fmt.Errorf("freezer table %s has an interrupted recompaction, open it in writable mode", name)
+	}
+	var marker recompactMarker
+	if err := rlp.DecodeBytes(blob, &marker); err != nil {
+		return err
+	}
+	log.Info("Finishing freezer table recompaction", "table", name, "dir", marker.Dir)
+
+	// Resolve the original location of the table, without checking its
+	// presence as it might have been partially deleted already.
+	old := path
+	if blob, err := os.ReadFile(tablePointerFile(path, name)); err == nil {
+		old = string(blob)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	// Move the staged files into place, the ones moved by the interrupted
+	// attempt are no longer in the staging directory.
+	staging := recompactStagingDir(marker.Dir, name)
+	entries, err := os.ReadDir(staging)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(staging, entry.Name()), filepath.Join(marker.Dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	// Delete the files of the original table and the stale ones in the
+	// target directory.
+	keep := make(map[string]bool)
+	for _, file := range marker.Files {
+		keep[file] = true
+	}
+	if sameDir(old, marker.Dir) {
+		if err := removeTableFiles(old, name, keep); err != nil {
+			return err
+		}
+	} else {
+		if err := removeTableFiles(old, name, nil); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := removeTableFiles(marker.Dir, name, keep); err != nil {
+			return err
+		}
+	}
+	// Record the new location of the table
+	if sameDir(path, marker.Dir) {
+		if err := os.Remove(tablePointerFile(path, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		if err := writeFileSync(tablePointerFile(path, name), []byte(marker.Dir)); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	return os.Remove(recompactMarkerFile(path, name))
+}
+
+// recompactTable rewrites all the items of the table in the given freezer
+// directory with the configured compression, optionally relocating it.
+//
+// The rewritten table is staged aside first and the original one is left
+// untouched, so an interruption during the staging leaves nothing but some
+// garbage to be cleaned up by the next attempt. Once the staging is complete,
+// a marker is persisted and the original table is replaced, which is resumed
+// when the table is opened next time if it's interrupted.
+func recompactTable(path, name string, maxFileSize uint32, noSnappy bool, config FreezerRecompaction) error {
+	if err := finishRecompaction(path, name, false); err != nil {
+		return err
+	}
+	if err := stageRecompaction(path, name, maxFileSize, noSnappy, config); err != nil {
+		return err
+	}
+	return finishRecompaction(path, name, false)
+}
+
+// stageRecompaction rewrites the table into the staging directory and commits
+// the recompaction by persisting the marker.
+func stageRecompaction(path, name string, maxFileSize uint32, noSnappy bool, config FreezerRecompaction) error {
+	src, err := newTable(path, name, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, maxFileSize, noSnappy, true)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// Resolve the compression and the location of the rewritten table,
+	// inheriting the current ones if they are not specified.
+	comp := config.Compression
+	if comp.Algorithm == "" {
+		comp.Algorithm = src.compression
+		if src.compression == FreezerCompressionZstd && comp.Level == 0 && len(comp.Dict) == 0 && config.DictSize == 0 {
+			blob, err := os.ReadFile(filepath.Join(src.path, zstdConfigFileName(name)))
+			if err != nil {
+				return err
+			}
+			var current zstdConfig
+			if err := rlp.DecodeBytes(blob, &current); err != nil {
+				return err
+			}
+			comp.Level, comp.Dict = int(current.Level), current.Dict
+		}
+	}
+	if err := comp.validate(); err != nil {
+		return err
+	}
+	// The algorithm of the table is otherwise implied by the configuration,
+	// so that only zstd can be opted in.
+	def := FreezerCompressionSnappy
+	if noSnappy {
+		def = FreezerCompressionNone
+	}
+	if comp.Algorithm != def && comp.Algorithm != FreezerCompressionZstd {
+		return fmt.Errorf("table %s can only be compressed by %s or %s", name, def, FreezerCompressionZstd)
+	}
+	dir := config.Directory
+	if dir == "" {
+		dir = src.path
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return err
+	}
+	// Drop the leftover of any uncommitted attempt and stage the new table
+	staging := recompactStagingDir(dir, name)
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return err
+	}
+	if comp.Algorithm == FreezerCompressionZstd {
+		dict := comp.Dict
+		if len(dict) == 0 && config.DictSize > 0 {
+			if dict, err = sampleDictionary(src, config.DictSize); err != nil {
+				return err
+			}
+		}
+		if err := writeZstdConfig(staging, name, comp.Level, dict); err != nil {
+			return err
+		}
+	}
+	// The new table starts from the first visible item, the hidden ones have
+	// been deleted already. The initial index entry denotes the tail, also
+	// determining the compression algorithm of the new table.
+	var (
+		tail  = src.itemHidden.Load()
+		items = src.items.Load()
+		first = indexEntry{filenum: 0, offset: uint32(tail)}
+	)
+	if err := writeFileSync(filepath.Join(staging, indexFileName(name, comp.Algorithm)), first.append(nil)); err != nil {
+		return err
+	}
+	dst, err := newTable(staging, name, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, maxFileSize, comp.Algorithm == FreezerCompressionNone, false)
+	if err != nil {
+		return err
+	}
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		batch  = dst.newBatch()
+	)
+	log.Info("Recompacting freezer table", "table", name, "compression", comp.Algorithm, "items", items-tail, "dir", dir)
+	for number := tail; number < items; {
+		blobs, err := src.RetrieveItems(number, 1024, 16*1024*1024)
+		if err != nil {
+			dst.Close()
+			return err
+		}
+		for _, blob := range blobs {
+			if err := batch.AppendRaw(number, blob); err != nil {
+				dst.Close()
+				return err
+			}
+			number++
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Recompacting freezer table", "table", name, "done", number-tail, "total", items-tail, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := batch.commit(); err != nil {
+		dst.Close()
+		return err
+	}
+	dstSize, _ := dst.size()
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	// Commit the recompaction and replace the original table
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		return err
+	}
+	marker := recompactMarker{Dir: dir}
+	for _, entry := range entries {
+		marker.Files = append(marker.Files, entry.Name())
+	}
+	blob, err := rlp.EncodeToBytes(&marker)
+	if err != nil {
+		return err
+	}
+	if err := writeFileSync(recompactMarkerFile(path, name)+".tmp", blob); err != nil {
+		return err
+	}
+	if err := os.Rename(recompactMarkerFile(path, name)+".tmp", recompactMarkerFile(path, name)); err != nil {
+		return err
+	}
+	srcSize, _ := src.size()
+	log.Info("Recompacted freezer table", "table", name, "items", items-tail, "before", common.StorageSize(srcSize), "after", common.StorageSize(dstSize), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// sampleDictionary builds a raw zstd dictionary of the given size from the
+// items evenly sampled across the table.
+func sampleDictionary(t *freezerTable, size int) ([]byte, error) {
+	var (
+		tail  = t.itemHidden.Load()
+		items = t.items.Load()
+		dict  []byte
+	)
+	if items == tail {
+		return nil, nil
+	}
+	step := max((items-tail)/1024, 1)
+	for number := tail; number < items && len(dict) < size; number += step {
+		blob, err := t.Retrieve(number)
+		if err != nil {
+			return nil, err
+		}
+		dict = append(dict, blob...)
+	}
+	if len(dict) > size {
+		dict = dict[len(dict)-size:]
+	}
+	return dict, nil
+}
@@ -0,0 +1,271 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+var recompactTestTableDef = map[string]bool{"hot": false, "cold": false, "raw": true}
+
+// fillRecompactTestFreezer creates a freezer with the given number of items
+// and deletes the first ones from the tail.
+func fillRecompactTestFreezer(t *testing.T, items, tail uint64) string {
+	t.Helper()
+
+	f, dir := newFreezerForTesting(t, recompactTestTableDef)
+	_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < items; i++ {
+			for name := range recompactTestTableDef {
+				if err := op.AppendRaw(name, i, recompactTestItem(name, i)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("failed to write items", err)
+	}
+	if _, err := f.TruncateTail(tail); err != nil {
+		t.Fatal("failed to truncate tail", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func recompactTestItem(name string, i uint64) []byte {
+	return append([]byte(name), getChunk(64+int(i%64), int(i))...)
+}
+
+// checkRecompactTestFreezer opens the freezer and verifies the items, then
+// checks the freezer is still writable.
+func checkRecompactTestFreezer(t *testing.T, dir string, items, tail uint64) {
+	t.Helper()
+
+	f, err := NewFreezer(dir, "", false, 2049, recompactTestTableDef)
+	if err != nil {
+		t.Fatal("failed to open freezer", err)
+	}
+	defer f.Close()
+
+	if frozen, _ := f.Ancients(); frozen != items {
+		t.Fatalf("unexpected item count, want %d, have %d", items, frozen)
+	}
+	if have, _ := f.Tail(); have != tail {
+		t.Fatalf("unexpected tail, want %d, have %d", tail, have)
+	}
+	for name := range recompactTestTableDef {
+		if _, err := f.Ancient(name, tail-1); err == nil {
+			t.Fatalf("table %s: deleted item %d is accessible", name, tail-1)
+		}
+		for i := tail; i < items; i++ {
+			blob, err := f.Ancient(name, i)
+			if err != nil {
+				t.Fatalf("table %s: failed to read item %d: %v", name, i, err)
+			}
+			if !bytes.Equal(blob, recompactTestItem(name, i)) {
+				t.Fatalf("table %s: item %d mismatch", name, i)
+			}
+		}
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for name := range recompactTestTableDef {
+			if err := op.AppendRaw(name, items, recompactTestItem(name, items)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("failed to append items", err)
+	}
+	for name := range recompactTestTableDef {
+		blob, err := f.Ancient(name, items)
+		if err != nil || !bytes.Equal(blob, recompactTestItem(name, items)) {
+			t.Fatalf("table %s: appended item mismatch: %v", name, err)
+		}
+	}
+}
+
+func TestFreezerRecompactZstd(t *testing.T) {
+	t.Parallel()
+
+	dir := fillRecompactTestFreezer(t, 100, 10)
+	config := FreezerRecompaction{
+		Compression: FreezerCompression{Algorithm: FreezerCompressionZstd, Level: 19},
+		DictSize:    1024,
+	}
+	for name := range recompactTestTableDef {
+		if err := recompactTable(dir, name, 2049, recompactTestTableDef[name], config); err != nil {
+			t.Fatalf("failed to recompact table %s: %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, indexFileName(name, FreezerCompressionZstd))); err != nil {
+			t.Fatalf("table %s: zstd index is missing: %v", name, err)
+		}
+	}
+	// The original files must all be deleted
+	for _, pattern := range []string{"*.cidx", "*.ridx", "*.cdat", "*.rdat", "*.staging", "*.recompact"} {
+		if files, _ := filepath.Glob(filepath.Join(dir, pattern)); len(files) != 0 {
+			t.Fatalf("stale files left: %v", files)
+		}
+	}
+	checkRecompactTestFreezer(t, dir, 100, 10)
+
+	// Recompact the zstd tables again, inheriting the configuration
+	if err := recompactTable(dir, "cold", 2049, false, FreezerRecompaction{}); err != nil {
+		t.Fatalf("failed to recompact table: %v", err)
+	}
+	checkRecompactTestFreezer(t, dir, 101, 10)
+
+	// Switch back to snappy, the raw table can't be compressed by it
+	config = FreezerRecompaction{Compression: FreezerCompression{Algorithm: FreezerCompressionSnappy}}
+	if err := recompactTable(dir, "raw", 2049, true, config); err == nil {
+		t.Fatal("expected error for compressing raw table by snappy")
+	}
+	if err := recompactTable(dir, "cold", 2049, false, config); err != nil {
+		t.Fatalf("failed to recompact table: %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "cold.z*")); len(files) != 0 {
+		t.Fatalf("stale files left: %v", files)
+	}
+	checkRecompactTestFreezer(t, dir, 102, 10)
+}
+
+func TestFreezerRecompactRelocate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir = fillRecompactTestFreezer(t, 100, 10)
+		hot = t.TempDir()
+	)
+	if err := recompactTable(dir, "hot", 2049, false, FreezerRecompaction{Directory: hot}); err != nil {
+		t.Fatalf("failed to relocate table: %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "hot.*")); len(files) != 1 || files[0] != tablePointerFile(dir, "hot") {
+		t.Fatalf("unexpected files in freezer directory: %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(hot, "hot.cidx")); err != nil {
+		t.Fatalf("relocated table is missing: %v", err)
+	}
+	checkRecompactTestFreezer(t, dir, 100, 10)
+
+	// The freezer must refuse to open if the relocated table is gone
+	os.Rename(hot, hot+".bak")
+	if f, err := NewFreezer(dir, "", false, 2049, recompactTestTableDef); err == nil {
+		f.Close()
+		t.Fatal("expected error for missing relocated table")
+	}
+	os.Rename(hot+".bak", hot)
+
+	// Move the table back into the freezer directory
+	if err := recompactTable(dir, "hot", 2049, false, FreezerRecompaction{Directory: dir}); err != nil {
+		t.Fatalf("failed to relocate table: %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(hot, "*")); len(files) != 0 {
+		t.Fatalf("stale files left: %v", files)
+	}
+	if _, err := os.Stat(tablePointerFile(dir, "hot")); !os.IsNotExist(err) {
+		t.Fatalf("table pointer is not deleted: %v", err)
+	}
+	checkRecompactTestFreezer(t, dir, 101, 10)
+}
+
+func TestFreezerRecompactRecovery(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir    = fillRecompactTestFreezer(t, 100, 10)
+		hot    = t.TempDir()
+		config = FreezerRecompaction{
+			Compression: FreezerCompression{Algorithm: FreezerCompressionZstd},
+			Directory:   hot,
+		}
+	)
+	// An uncommitted recompaction leaves the table intact
+	if err := stageRecompaction(dir, "cold", 2049, false, config); err != nil {
+		t.Fatalf("failed to stage recompaction: %v", err)
+	}
+	os.Remove(recompactMarkerFile(dir, "cold"))
+	checkRecompactTestFreezer(t, dir, 100, 10)
+
+	// Interrupt the committed recompaction halfway through the replacement
+	if err := stageRecompaction(dir, "cold", 2049, false, config); err != nil {
+		t.Fatalf("failed to stage recompaction: %v", err)
+	}
+	staging := recompactStagingDir(hot, "cold")
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries[:len(entries)/2] {
+		if err := os.Rename(filepath.Join(staging, entry.Name()), filepath.Join(hot, entry.Name())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The readonly freezer can't resume the replacement
+	if f, err := NewFreezer(dir, "", true, 2049, recompactTestTableDef); err == nil {
+		f.Close()
+		t.Fatal("expected error for interrupted recompaction")
+	}
+	checkRecompactTestFreezer(t, dir, 101, 10)
+
+	if _, err := os.Stat(recompactMarkerFile(dir, "cold")); !os.IsNotExist(err) {
+		t.Fatalf("recompaction marker is not deleted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(hot, "cold.zidx")); err != nil {
+		t.Fatalf("recompacted table is missing: %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "cold.*")); len(files) != 1 {
+		t.Fatalf("unexpected files in freezer directory: %v", files)
+	}
+}
+
+func TestFreezerCodecs(t *testing.T) {
+	t.Parallel()
+
+	var (
+		raw  = bytes.Repeat([]byte("freezer"), 100)
+		dict = bytes.Repeat([]byte("zstd"), 100)
+	)
+	for _, level := range []int{0, 1, 3, 7, 19} {
+		for _, d := range [][]byte{nil, dict} {
+			codec, err := newZstdCodec(level, d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, data := range [][]byte{{}, {0x1}, raw} {
+				enc := codec.compress(nil, data)
+				dec, err := codec.decompress(enc)
+				if err != nil {
+					t.Fatalf("level %d: failed to decompress: %v", level, err)
+				}
+				if !bytes.Equal(dec, data) {
+					t.Fatalf("level %d: data mismatch, want %x, have %x", level, data, dec)
+				}
+			}
+			codec.close()
+		}
+	}
+}
@@ -32,7 +32,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
-	"github.com/golang/snappy"
 )
 
 var (
@@ -87,8 +86,8 @@ func (i *indexEntry) bounds(end *indexEntry) (startOffset, endOffset, fileId uin
 }
 
 // freezerTable represents a single chained data table within the freezer (e.g. blocks).
-// It consists of a data file (snappy encoded arbitrary data blobs) and an indexEntry
-// file (uncompressed 64 bit indices into the data file).
+// It consists of a data file (optionally compressed arbitrary data blobs) and an
+// indexEntry file (uncompressed 64 bit indices into the data file).
 type freezerTable struct {
 	items      atomic.Uint64 // Number of items stored in the table (including items removed from tail)
 	itemOffset atomic.Uint64 // Number of items removed from the table
@@ -100,11 +99,12 @@ type freezerTable struct {
 	// should never be lower than itemOffset.
 	itemHidden atomic.Uint64
 
-	noCompression bool // if true, disables snappy compression. Note: does not work retroactively
-	readonly      bool
-	maxFileSize   uint32 // Max file size for data-files
-	name          string
-	path          string
+	compression string       // Compression algorithm of the items, determined by the existing files
+	codec       freezerCodec // Codec for (de)compressing the items, nil if uncompressed
+	readonly    bool
+	maxFileSize uint32 // Max file size for data-files
+	name        string
+	path        string
 
 	head   *os.File            // File descriptor for the data head of the table
 	index  *os.File            // File descriptor for the indexEntry file of the table
@@ -130,19 +130,33 @@ func newFreezerTable(path, name string, disableSnappy, readonly bool) (*freezerT
 // newTable opens a freezer table, creating the data and index files if they are
 // non-existent. Both files are truncated to the shortest common length to ensure
 // they don't go out of sync.
+//
+// The given path is the directory of the freezer, the table itself might be
+// relocated to another directory by recompaction. The compression flag only
+// applies to new tables, the existing ones are opened with the compression
+// algorithm they were written with.
 func newTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, noCompression, readonly bool) (*freezerTable, error) {
 	// Ensure the containing directory exists and open the indexEntry file
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
 	}
-	var idxName string
+	// Complete the interrupted recompaction if there is any, and resolve the
+	// directory where the table is stored.
+	if err := finishRecompaction(path, name, readonly); err != nil {
+		return nil, err
+	}
+	path, err := tableDir(path, name)
+	if err != nil {
+		return nil, err
+	}
+	compression := FreezerCompressionSnappy
 	if noCompression {
-		idxName = fmt.Sprintf("%s.ridx", name) // raw index file
-	} else {
-		idxName = fmt.Sprintf("%s.cidx", name) // compressed index file
+		compression = FreezerCompressionNone
 	}
+	compression = tableCompression(path, name, compression)
+	idxName := indexFileName(name, compression)
+
 	var (
-		err   error
 		index *os.File
 		meta  *os.File
 	)
@@ -166,20 +180,27 @@ func newTable(path string, name string, readMeter metrics.Meter, writeMeter metr
 			return nil, err
 		}
 	}
+	codec, err := openCodec(path, name, compression)
+	if err != nil {
+		index.Close()
+		meta.Close()
+		return nil, err
+	}
 	// Create the table and repair any past inconsistency
 	tab := &freezerTable{
-		index:         index,
-		meta:          meta,
-		files:         make(map[uint32]*os.File),
-		readMeter:     readMeter,
-		writeMeter:    writeMeter,
-		sizeGauge:     sizeGauge,
-		name:          name,
-		path:          path,
-		logger:        log.New("database", path, "table", name),
-		noCompression: noCompression,
-		readonly:      readonly,
-		maxFileSize:   maxFilesize,
+		index:       index,
+		meta:        meta,
+		files:       make(map[uint32]*os.File),
+		readMeter:   readMeter,
+		writeMeter:  writeMeter,
+		sizeGauge:   sizeGauge,
+		name:        name,
+		path:        path,
+		logger:      log.New("database", path, "table", name),
+		compression: compression,
+		codec:       codec,
+		readonly:    readonly,
+		maxFileSize: maxFilesize,
 	}
 	if err := tab.repair(); err != nil {
 		tab.Close()
@@ -760,6 +781,9 @@ func (t *freezerTable) Close() error {
 	t.meta = nil
 	t.head = nil
 
+	if t.codec != nil {
+		t.codec.close()
+	}
 	if errs != nil {
 		return fmt.Errorf("%v", errs)
 	}
@@ -770,13 +794,7 @@ func (t *freezerTable) Close() error {
 func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error)) (f *os.File, err error) {
 	var exist bool
 	if f, exist = t.files[num]; !exist {
-		var name string
-		if t.noCompression {
-			name = fmt.Sprintf("%s.%04d.rdat", t.name, num)
-		} else {
-			name = fmt.Sprintf("%s.%04d.cdat", t.name, num)
-		}
-		f, err = opener(filepath.Join(t.path, name))
+		f, err = opener(filepath.Join(t.path, dataFileName(t.name, t.compression, num)))
 		if err != nil {
 			return nil, err
 		}
@@ -872,6 +890,11 @@ func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
 // 'maxBytes' argument. However, if the 'maxBytes' is smaller than the size of one
 // item, it _will_ return one element and possibly overflow the maxBytes.
 func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, error) {
+	// The lock is held during the decompression as well, as the codec is
+	// released once the table is closed.
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
 	// First we read the 'raw' data, which might be compressed.
 	diskData, sizes, err := t.retrieveItems(start, count, maxBytes)
 	if err != nil {
@@ -886,23 +909,16 @@ func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, e
 	for i, diskSize := range sizes {
 		item := diskData[offset : offset+diskSize]
 		offset += diskSize
-		decompressedSize := diskSize
-		if !t.noCompression {
-			decompressedSize, _ = snappy.DecodedLen(item)
-		}
-		if i > 0 && maxBytes != 0 && uint64(outputSize+decompressedSize) > maxBytes {
-			break
-		}
-		if !t.noCompression {
-			data, err := snappy.Decode(nil, item)
-			if err != nil {
+		if t.codec != nil {
+			if item, err = t.codec.decompress(item); err != nil {
 				return nil, err
 			}
-			output = append(output, data)
-		} else {
-			output = append(output, item)
 		}
-		outputSize += decompressedSize
+		if i > 0 && maxBytes != 0 && uint64(outputSize+len(item)) > maxBytes {
+			break
+		}
+		output = append(output, item)
+		outputSize += len(item)
 	}
 	return output, nil
 }
@@ -911,11 +927,8 @@ func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, e
 // one item, but otherwise avoids reading more than maxBytes bytes. Freezer
 // will ignore the size limitation and continuously allocate memory to store
 // data if maxBytes is 0. It returns the (potentially compressed) data, and
-// the sizes.
+// the sizes. The caller must hold the read lock.
 func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []int, error) {
-	t.lock.RLock()
-	defer t.lock.RUnlock()
-
 	// Ensure the table and the item are accessible
 	if t.index == nil || t.head == nil || t.meta == nil {
 		return nil, nil, errClosed
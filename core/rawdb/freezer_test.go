@@ -282,6 +282,53 @@ func TestFreezerReadonlyValidate(t *testing.T) {
 	}
 }
 
+func TestFreezerPrunableTables(t *testing.T) {
+	var (
+		tables   = map[string]bool{"a": true, "b": false}
+		prunable = map[string]bool{"b": true}
+		dir      = t.TempDir()
+	)
+	f, err := newFreezer(dir, "", false, 2049, tables, prunable)
+	if err != nil {
+		t.Fatal("can't open freezer", err)
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 10; i++ {
+			if err := op.AppendRaw("a", i, []byte{byte(i)}); err != nil {
+				return err
+			}
+			if err := op.AppendRaw("b", i, []byte{byte(i)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = f.TruncateTail(5)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Re-open the freezer, only the prunable table is truncated
+	for _, readonly := range []bool{true, false} {
+		f, err = newFreezer(dir, "", readonly, 2049, tables, prunable)
+		if err != nil {
+			t.Fatal("can't reopen freezer", err)
+		}
+		if tail, _ := f.Tail(); tail != 5 {
+			t.Fatalf("unexpected tail, want 5, have %d", tail)
+		}
+		if _, err := f.Ancient("b", 4); err == nil {
+			t.Fatal("pruned item is accessible")
+		}
+		for i := uint64(0); i < 10; i++ {
+			if blob, err := f.Ancient("a", i); err != nil || !bytes.Equal(blob, []byte{byte(i)}) {
+				t.Fatalf("item %d of unprunable table is inaccessible: %v", i, err)
+			}
+		}
+		require.NoError(t, f.Close())
+	}
+}
+
 func TestFreezerConcurrentReadonly(t *testing.T) {
 	t.Parallel()
 
@@ -76,6 +76,15 @@ var (
 	// trieJournalKey tracks the in-memory trie node layers across restarts.
 	trieJournalKey = []byte("TrieJournal")
 
+	// stateHistoryIndexTailKey tracks the id of the oldest indexed state history.
+	stateHistoryIndexTailKey = []byte("StateHistoryIndexTail")
+
+	// schemeMigrationKey tracks the progress of the state scheme migration.
+	schemeMigrationKey = []byte("SchemeMigration")
+
+	// logIndexTailKey tracks the oldest section whose logs have been indexed.
+	logIndexTailKey = []byte("LogIndexTail")
+
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
@@ -117,6 +126,10 @@ var (
 	TrieNodeStoragePrefix = []byte("O") // TrieNodeStoragePrefix + accountHash + hexPath -> trie node
 	stateIDPrefix         = []byte("L") // stateIDPrefix + state root -> state id
 
+	// Path-based state history index
+	StateHistoryAccountIndexPrefix = []byte("ma") // StateHistoryAccountIndexPrefix + address + state id (uint64 big endian) -> nil
+	StateHistoryStorageIndexPrefix = []byte("ms") // StateHistoryStorageIndexPrefix + address + slot hash + state id (uint64 big endian) -> nil
+
 	// VerklePrefix is the database prefix for Verkle trie data, which includes:
 	// (a) Trie nodes
 	// (b) In-memory trie node journal
@@ -131,6 +144,13 @@ var (
 	// BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 	BloomBitsIndexPrefix = []byte("iB")
 
+	// LogIndexTablePrefix is the data table of the log indexer to track its progress
+	LogIndexTablePrefix = []byte("iL")
+
+	// Log index of the blocks containing the log addresses and topics
+	logIndexPrefix        = []byte("xi") // logIndexPrefix + index key + section (uint64 big endian) -> block numbers
+	logIndexSectionPrefix = []byte("xs") // logIndexSectionPrefix + section (uint64 big endian) -> index keys of the section
+
 	ChtPrefix           = []byte("chtRootV2-") // ChtPrefix + chtNum (uint64 big endian) -> trie root hash
 	ChtTablePrefix      = []byte("cht-")
 	ChtIndexTablePrefix = []byte("chtIndexV2-")
@@ -141,6 +161,9 @@ var (
 
 	CliqueSnapshotPrefix = []byte("clique-")
 
+	goatRequestsPrefix = []byte("goat-requests-") // goatRequestsPrefix + num (uint64 big endian) + hash -> goat requests
+	goatRewardPrefix   = []byte("goat-reward-")   // goatRewardPrefix + recipient + num (uint64 big endian) + tx index (uint16 big endian) -> reward entry
+
 	BestUpdateKey         = []byte("update-")    // bigEndian64(syncPeriod) -> RLP(types.LightClientUpdate)  (nextCommittee only referenced by root hash)
 	FixedCommitteeRootKey = []byte("fixedRoot-") // bigEndian64(syncPeriod) -> committee root hash
 	SyncCommitteeKey      = []byte("committee-") // bigEndian64(syncPeriod) -> serialized committee
@@ -199,6 +222,17 @@ func blockReceiptsKey(number uint64, hash common.Hash) []byte {
 	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+// goatRequestsKey = goatRequestsPrefix + num (uint64 big endian) + hash
+func goatRequestsKey(number uint64, hash common.Hash) []byte {
+	return append(append(goatRequestsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// goatRewardKey = goatRewardPrefix + recipient + num (uint64 big endian) + tx index (uint16 big endian)
+func goatRewardKey(recipient common.Address, number uint64, index uint16) []byte {
+	key := append(append(goatRewardPrefix, recipient.Bytes()...), encodeBlockNumber(number)...)
+	return binary.BigEndian.AppendUint16(key, index)
+}
+
 // txLookupKey = txLookupPrefix + hash
 func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
@@ -233,6 +267,16 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// logIndexKey = logIndexPrefix + index key + section (uint64 big endian)
+func logIndexKey(key []byte, section uint64) []byte {
+	return append(append(append([]byte{}, logIndexPrefix...), key...), encodeBlockNumber(section)...)
+}
+
+// logIndexSectionKey = logIndexSectionPrefix + section (uint64 big endian)
+func logIndexSectionKey(section uint64) []byte {
+	return append(logIndexSectionPrefix, encodeBlockNumber(section)...)
+}
+
 // skeletonHeaderKey = skeletonHeaderPrefix + num (uint64 big endian)
 func skeletonHeaderKey(number uint64) []byte {
 	return append(skeletonHeaderPrefix, encodeBlockNumber(number)...)
@@ -272,6 +316,20 @@ func stateIDKey(root common.Hash) []byte {
 	return append(stateIDPrefix, root.Bytes()...)
 }
 
+// stateHistoryAccountIndexKey = StateHistoryAccountIndexPrefix + address + id (uint64 big endian)
+func stateHistoryAccountIndexKey(address common.Address, id uint64) []byte {
+	return append(append(append([]byte{}, StateHistoryAccountIndexPrefix...), address.Bytes()...), encodeBlockNumber(id)...)
+}
+
+// stateHistoryStorageIndexKey = StateHistoryStorageIndexPrefix + address + slot hash + id (uint64 big endian)
+func stateHistoryStorageIndexKey(address common.Address, slot common.Hash, id uint64) []byte {
+	buf := make([]byte, 0, len(StateHistoryStorageIndexPrefix)+common.AddressLength+common.HashLength+8)
+	buf = append(buf, StateHistoryStorageIndexPrefix...)
+	buf = append(buf, address.Bytes()...)
+	buf = append(buf, slot.Bytes()...)
+	return append(buf, encodeBlockNumber(id)...)
+}
+
 // accountTrieNodeKey = TrieNodeAccountPrefix + nodePath.
 func accountTrieNodeKey(path []byte) []byte {
 	return append(TrieNodeAccountPrefix, path...)
@@ -0,0 +1,351 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"maps"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// AccessSet records the state items read and written by a single transaction.
+// It is used for detecting the conflicts among the transactions executed
+// speculatively in parallel.
+//
+// The state of an account is tracked in three granularities:
+//
+//   - existence: whether the account is present in the state
+//   - fields: the balance, nonce and code of the account
+//   - slots: the individual storage slots of the account
+//
+// Creating or deleting an account (a reset) invalidates all of them. A reset
+// also depends on the entire account, as the merged object replaces the one in
+// the destination state.
+type AccessSet struct {
+	// Items read by the transaction
+	existReads map[common.Address]struct{}
+	fieldReads map[common.Address]struct{}
+	slotReads  map[common.Address]map[common.Hash]struct{}
+
+	// Items written by the transaction
+	fieldWrites map[common.Address]struct{}
+	slotWrites  map[common.Address]map[common.Hash]struct{}
+	resets      map[common.Address]struct{}
+
+	// The balance credits to the commutative account (usually the coinbase)
+	// are deferred and accumulated if the account is never read, so that the
+	// transactions paying fees aren't all conflicted with each other.
+	commutative *common.Address
+	credit      *uint256.Int
+	credited    bool
+
+	// invalid is set if the speculative execution can't be trusted, e.g. the
+	// deferred credits are observed or reverted.
+	invalid bool
+}
+
+// NewAccessSet creates an empty access set. The balance credits to the given
+// commutative account are deferred if it's not nil.
+func NewAccessSet(commutative *common.Address) *AccessSet {
+	return &AccessSet{
+		existReads:  make(map[common.Address]struct{}),
+		fieldReads:  make(map[common.Address]struct{}),
+		slotReads:   make(map[common.Address]map[common.Hash]struct{}),
+		fieldWrites: make(map[common.Address]struct{}),
+		slotWrites:  make(map[common.Address]map[common.Hash]struct{}),
+		resets:      make(map[common.Address]struct{}),
+		commutative: commutative,
+		credit:      new(uint256.Int),
+	}
+}
+
+func (a *AccessSet) readExist(addr common.Address) {
+	if a.credited && *a.commutative == addr {
+		a.invalid = true
+	}
+	a.existReads[addr] = struct{}{}
+}
+
+func (a *AccessSet) readField(addr common.Address) {
+	a.fieldReads[addr] = struct{}{}
+}
+
+func (a *AccessSet) readSlot(addr common.Address, key common.Hash) {
+	addSlot(a.slotReads, addr, key)
+}
+
+// deferCredit accumulates the balance credit to the commutative account if it
+// hasn't been accessed by the transaction yet. False is returned if the credit
+// should be applied directly.
+func (a *AccessSet) deferCredit(addr common.Address, amount *uint256.Int) bool {
+	if a.commutative == nil || *a.commutative != addr {
+		return false
+	}
+	if _, ok := a.existReads[addr]; ok {
+		return false
+	}
+	a.credit.Add(a.credit, amount)
+	a.credited = true
+	return true
+}
+
+// revert is called when the state is reverted to a previous snapshot. The
+// deferred credits are not journalled, the speculation is thus invalidated.
+func (a *AccessSet) revert() {
+	if a.credited {
+		a.invalid = true
+	}
+}
+
+// collectWrites records the items written by the transaction according to the
+// journal entries. It must be called before the journal is cleared.
+func (a *AccessSet) collectWrites(j *journal) {
+	for _, entry := range j.entries {
+		switch ch := entry.(type) {
+		case createObjectChange:
+			a.resets[ch.account] = struct{}{}
+		case createContractChange:
+			a.fieldWrites[ch.account] = struct{}{}
+		case selfDestructChange:
+			a.fieldWrites[ch.account] = struct{}{}
+		case balanceChange:
+			a.fieldWrites[ch.account] = struct{}{}
+		case nonceChange:
+			a.fieldWrites[ch.account] = struct{}{}
+		case codeChange:
+			a.fieldWrites[ch.account] = struct{}{}
+		case touchChange:
+			a.fieldWrites[ch.account] = struct{}{}
+		case storageChange:
+			addSlot(a.slotWrites, ch.account, ch.key)
+		}
+	}
+	// The accounts dirtied explicitly without any journal entry are regarded
+	// as field-written.
+	for addr := range j.dirties {
+		if _, ok := a.fieldWrites[addr]; ok {
+			continue
+		}
+		if _, ok := a.slotWrites[addr]; ok {
+			continue
+		}
+		if _, ok := a.resets[addr]; ok {
+			continue
+		}
+		a.fieldWrites[addr] = struct{}{}
+	}
+}
+
+// Invalid reports whether the speculative execution must be discarded.
+func (a *AccessSet) Invalid() bool {
+	return a.invalid
+}
+
+// Conflicts reports whether the items read or written by the transaction have
+// been modified by the given writes.
+func (a *AccessSet) Conflicts(w *AccessSet) bool {
+	if a.invalid {
+		return true
+	}
+	for addr := range w.resets {
+		if touched(a, addr) {
+			return true
+		}
+	}
+	for addr := range w.fieldWrites {
+		if _, ok := a.fieldReads[addr]; ok {
+			return true
+		}
+		if _, ok := a.fieldWrites[addr]; ok {
+			return true
+		}
+		if _, ok := a.resets[addr]; ok {
+			return true
+		}
+	}
+	for addr, slots := range w.slotWrites {
+		if _, ok := a.resets[addr]; ok {
+			return true
+		}
+		for key := range slots {
+			if _, ok := a.slotReads[addr][key]; ok {
+				return true
+			}
+			if _, ok := a.slotWrites[addr][key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// touched reports whether the account is accessed in any way by the set.
+func touched(a *AccessSet, addr common.Address) bool {
+	for _, m := range []map[common.Address]struct{}{a.existReads, a.fieldReads, a.fieldWrites, a.resets} {
+		if _, ok := m[addr]; ok {
+			return true
+		}
+	}
+	if _, ok := a.slotReads[addr]; ok {
+		return true
+	}
+	_, ok := a.slotWrites[addr]
+	return ok
+}
+
+// AddWrites merges the items written by another transaction into the set. The
+// deferred credits are regarded as a reset of the commutative account, as they
+// might create or delete it.
+func (a *AccessSet) AddWrites(b *AccessSet) {
+	maps.Copy(a.fieldWrites, b.fieldWrites)
+	maps.Copy(a.resets, b.resets)
+	for addr, slots := range b.slotWrites {
+		for key := range slots {
+			addSlot(a.slotWrites, addr, key)
+		}
+	}
+	if b.credited {
+		a.resets[*b.commutative] = struct{}{}
+	}
+}
+
+func addSlot(m map[common.Address]map[common.Hash]struct{}, addr common.Address, key common.Hash) {
+	slots, ok := m[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		m[addr] = slots
+	}
+	slots[key] = struct{}{}
+}
+
+// StartAccessTracking starts recording the state items accessed by the next
+// transaction. The tracking is stopped and the items are returned by
+// StopAccessTracking, which must be called after finalising the transaction.
+func (s *StateDB) StartAccessTracking(commutative *common.Address) {
+	s.tracker = NewAccessSet(commutative)
+}
+
+// StopAccessTracking stops the tracking and returns the recorded items.
+func (s *StateDB) StopAccessTracking() *AccessSet {
+	set := s.tracker
+	s.tracker = nil
+	return set
+}
+
+// MergeTransaction applies the state changes made by a transaction, which has
+// been executed and finalised on a copy of the state, to the current state.
+// The caller must ensure that none of the items accessed by the transaction
+// has been modified in the current state since the copy was made, and that
+// the transaction context has been set by SetTxContext.
+func (s *StateDB) MergeTransaction(src *StateDB, set *AccessSet) {
+	for addr := range set.resets {
+		s.mergeReset(src, addr)
+	}
+	for addr := range set.fieldWrites {
+		if _, ok := set.resets[addr]; ok {
+			continue
+		}
+		obj := s.mergeTarget(src, addr)
+		if obj == nil {
+			continue
+		}
+		srcObj := src.stateObjects[addr]
+		obj.data.Balance = new(uint256.Int).Set(srcObj.data.Balance)
+		obj.data.Nonce = srcObj.data.Nonce
+		obj.data.CodeHash = srcObj.data.CodeHash
+		obj.code = srcObj.code
+		obj.dirtyCode = obj.dirtyCode || srcObj.dirtyCode
+		s.markUpdate(addr)
+	}
+	for addr, slots := range set.slotWrites {
+		if _, ok := set.resets[addr]; ok {
+			continue
+		}
+		obj := s.mergeTarget(src, addr)
+		if obj == nil {
+			continue
+		}
+		srcObj := src.stateObjects[addr]
+		for key := range slots {
+			mergeSlot(obj.originStorage, srcObj.originStorage, key)
+			mergeSlot(obj.pendingStorage, srcObj.pendingStorage, key)
+			mergeSlot(obj.uncommittedStorage, srcObj.uncommittedStorage, key)
+		}
+		s.markUpdate(addr)
+	}
+	// Apply the deferred credits, they are finalised along with the touch
+	// semantics of EIP-158 in the same way as sequential execution.
+	if set.credited {
+		s.AddBalance(*set.commutative, set.credit, tracing.BalanceIncreaseRewardTransactionFee)
+		s.Finalise(true)
+	}
+	for _, l := range src.logs[src.thash] {
+		cpy := new(types.Log)
+		*cpy = *l
+		cpy.TxHash = s.thash
+		cpy.TxIndex = uint(s.txIndex)
+		cpy.Index = s.logSize
+		s.logs[s.thash] = append(s.logs[s.thash], cpy)
+		s.logSize++
+	}
+	for hash, preimage := range src.preimages {
+		if _, ok := s.preimages[hash]; !ok {
+			s.preimages[hash] = preimage
+		}
+	}
+}
+
+// mergeTarget returns the live object in the current state for merging the
+// changes made to the given account. The account is merged as a whole if it's
+// not available, which can only happen if it was reset in the source.
+func (s *StateDB) mergeTarget(src *StateDB, addr common.Address) *stateObject {
+	obj := s.getStateObject(addr)
+	if obj == nil || src.stateObjects[addr] == nil {
+		s.mergeReset(src, addr)
+		return nil
+	}
+	return obj
+}
+
+// mergeReset replaces the account in the current state with the one in the
+// source, including the deletion markers.
+func (s *StateDB) mergeReset(src *StateDB, addr common.Address) {
+	if obj := src.stateObjects[addr]; obj != nil {
+		s.stateObjects[addr] = obj.deepCopy(s)
+	} else {
+		delete(s.stateObjects, addr)
+	}
+	if obj, ok := src.stateObjectsDestruct[addr]; ok {
+		if _, exist := s.stateObjectsDestruct[addr]; !exist {
+			s.stateObjectsDestruct[addr] = obj.deepCopy(s)
+		}
+	}
+	if op, ok := src.mutations[addr]; ok {
+		s.mutations[addr] = op.copy()
+	}
+}
+
+func mergeSlot(dst, src Storage, key common.Hash) {
+	if value, ok := src[key]; ok {
+		dst[key] = value
+	} else {
+		delete(dst, key)
+	}
+}
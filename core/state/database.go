@@ -292,6 +292,8 @@ func mustCopyTrie(t Trie) Trie {
 		return t.Copy()
 	case *trie.VerkleTrie:
 		return t.Copy()
+	case *historicTrie:
+		return &historicTrie{root: t.root}
 	default:
 		panic(fmt.Errorf("unknown trie type %T", t))
 	}
@@ -0,0 +1,236 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+)
+
+// historicReadRetries is the number of attempts for resolving an unmodified
+// item from the disk layer, which might be advanced concurrently.
+const historicReadRetries = 3
+
+// errHistoricTrie is returned if the trie of a historical state is accessed.
+var errHistoricTrie = errors.New("trie is not available for historical state")
+
+// HistoricDB is an implementation of Database interface, providing read-only
+// access to the historical states served by the state histories of path-based
+// trie database. The trie of these states is not available, only the accounts,
+// storage slots and contract codes can be accessed.
+type HistoricDB struct {
+	*CachingDB
+}
+
+// NewHistoricDatabase creates a historical state database with the provided
+// trie database.
+func NewHistoricDatabase(triedb *triedb.Database) *HistoricDB {
+	return &HistoricDB{CachingDB: NewDatabase(triedb, nil)}
+}
+
+// Reader returns a state reader associated with the specified historical state.
+func (db *HistoricDB) Reader(stateRoot common.Hash) (Reader, error) {
+	if db.triedb.IsVerkle() {
+		return nil, errors.New("historical state is not supported by verkle")
+	}
+	r, err := db.triedb.HistoricReader(stateRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newHistoricReader(r, db.triedb), nil
+}
+
+// OpenTrie returns a placeholder trie of the historical state, which is only
+// used for tracking the state root.
+func (db *HistoricDB) OpenTrie(root common.Hash) (Trie, error) {
+	if _, err := db.triedb.HistoricReader(root); err != nil {
+		return nil, err
+	}
+	return &historicTrie{root: root}, nil
+}
+
+// OpenStorageTrie returns a placeholder storage trie of the historical state.
+func (db *HistoricDB) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, self Trie) (Trie, error) {
+	return &historicTrie{root: root}, nil
+}
+
+// historicReader implements the Reader interface, providing access to the
+// historical state. The items which are not modified since the historical
+// state are resolved from the disk layer.
+type historicReader struct {
+	reader *pathdb.HistoricalStateReader
+	db     *triedb.Database
+	buff   crypto.KeccakState
+	disk   *trieReader // Reader of the disk layer, recreated if it's advanced
+}
+
+// newHistoricReader constructs a reader of the specified historical state.
+func newHistoricReader(r *pathdb.HistoricalStateReader, db *triedb.Database) *historicReader {
+	return &historicReader{
+		reader: r,
+		db:     db,
+		buff:   crypto.NewKeccakState(),
+	}
+}
+
+// diskReader returns the trie reader of the disk layer with the given root.
+func (r *historicReader) diskReader(root common.Hash) (*trieReader, error) {
+	if r.disk != nil && r.disk.root == root {
+		return r.disk, nil
+	}
+	tr, err := newTrieReader(root, r.db, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.disk = tr
+	return tr, nil
+}
+
+// Account implements Reader, retrieving the account specified by the address.
+func (r *historicReader) Account(addr common.Address) (*types.StateAccount, error) {
+	var err error
+	for i := 0; i < historicReadRetries; i++ {
+		var (
+			blob []byte
+			root common.Hash
+			tr   *trieReader
+		)
+		blob, root, err = r.reader.Account(addr)
+		if err != nil {
+			return nil, err
+		}
+		if root == (common.Hash{}) {
+			if len(blob) == 0 {
+				return nil, nil
+			}
+			return types.FullAccount(blob)
+		}
+		tr, err = r.diskReader(root)
+		if err != nil {
+			continue
+		}
+		var acct *types.StateAccount
+		if acct, err = tr.Account(addr); err == nil {
+			return acct, nil
+		}
+	}
+	return nil, err
+}
+
+// Storage implements Reader, retrieving the storage slot specified by the
+// address and slot key.
+func (r *historicReader) Storage(addr common.Address, key common.Hash) (common.Hash, error) {
+	var (
+		err      error
+		slotHash = crypto.HashData(r.buff, key.Bytes())
+	)
+	for i := 0; i < historicReadRetries; i++ {
+		var (
+			blob []byte
+			root common.Hash
+			tr   *trieReader
+		)
+		blob, root, err = r.reader.Storage(addr, slotHash)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if root == (common.Hash{}) {
+			if len(blob) == 0 {
+				return common.Hash{}, nil
+			}
+			_, content, _, err := rlp.Split(blob)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			var value common.Hash
+			value.SetBytes(content)
+			return value, nil
+		}
+		tr, err = r.diskReader(root)
+		if err != nil {
+			continue
+		}
+		var value common.Hash
+		if value, err = tr.Storage(addr, key); err == nil {
+			return value, nil
+		}
+	}
+	return common.Hash{}, err
+}
+
+// Copy implements Reader, returning a deep-copied historical state reader.
+func (r *historicReader) Copy() Reader {
+	return newHistoricReader(r.reader, r.db)
+}
+
+// historicTrie is a placeholder trie of the historical state. It doesn't hold
+// any trie nodes, the state access is served by the historicReader. Mutations
+// are silently discarded as the historical state is never committed.
+type historicTrie struct {
+	root common.Hash
+}
+
+func (t *historicTrie) GetKey([]byte) []byte { return nil }
+
+func (t *historicTrie) GetAccount(address common.Address) (*types.StateAccount, error) {
+	return nil, errHistoricTrie
+}
+
+func (t *historicTrie) GetStorage(addr common.Address, key []byte) ([]byte, error) {
+	return nil, errHistoricTrie
+}
+
+func (t *historicTrie) UpdateAccount(address common.Address, account *types.StateAccount, codeLen int) error {
+	return nil
+}
+
+func (t *historicTrie) UpdateStorage(addr common.Address, key, value []byte) error { return nil }
+
+func (t *historicTrie) DeleteAccount(address common.Address) error { return nil }
+
+func (t *historicTrie) DeleteStorage(addr common.Address, key []byte) error { return nil }
+
+func (t *historicTrie) UpdateContractCode(address common.Address, codeHash common.Hash, code []byte) error {
+	return nil
+}
+
+func (t *historicTrie) Hash() common.Hash { return t.root }
+
+func (t *historicTrie) Commit(collectLeaf bool) (common.Hash, *trienode.NodeSet) {
+	return t.root, nil
+}
+
+func (t *historicTrie) Witness() map[string]struct{} { return nil }
+
+func (t *historicTrie) NodeIterator(startKey []byte) (trie.NodeIterator, error) {
+	return nil, errHistoricTrie
+}
+
+func (t *historicTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
+	return errHistoricTrie
+}
+
+func (t *historicTrie) IsVerkle() bool { return false }
@@ -0,0 +1,91 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/holiman/uint256"
+)
+
+func TestHistoricState(t *testing.T) {
+	disk, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tdb := triedb.NewDatabase(disk, &triedb.Config{PathDB: &pathdb.Config{HistoryIndex: true}})
+	defer tdb.Close()
+
+	var (
+		db       = NewDatabase(tdb, nil)
+		roots    = []common.Hash{types.EmptyRootHash}
+		addr     = common.BytesToAddress([]byte("addr"))
+		unchange = common.BytesToAddress([]byte("unchange"))
+		slot     = common.HexToHash("0x01")
+	)
+	for i := 1; i <= 5; i++ {
+		state, _ := New(roots[len(roots)-1], db)
+		state.SetBalance(addr, uint256.NewInt(uint64(i)), tracing.BalanceChangeUnspecified)
+		state.SetState(addr, slot, common.BigToHash(uint256.NewInt(uint64(i)).ToBig()))
+		if i == 1 {
+			state.SetBalance(unchange, uint256.NewInt(100), tracing.BalanceChangeUnspecified)
+			state.SetCode(unchange, []byte{0x1})
+		}
+		root, err := state.Commit(uint64(i), false)
+		if err != nil {
+			t.Fatalf("Failed to commit state %d: %v", i, err)
+		}
+		if err := tdb.Commit(root, false); err != nil {
+			t.Fatalf("Failed to flatten state %d: %v", i, err)
+		}
+		roots = append(roots, root)
+	}
+	historic := NewHistoricDatabase(tdb)
+	for i := 1; i < 5; i++ {
+		state, err := New(roots[i], historic)
+		if err != nil {
+			t.Fatalf("Failed to open historical state %d: %v", i, err)
+		}
+		if got := state.GetBalance(addr).Uint64(); got != uint64(i) {
+			t.Fatalf("Unexpected balance at state %d, want %d, got %d", i, i, got)
+		}
+		if got := state.GetState(addr, slot).Big().Uint64(); got != uint64(i) {
+			t.Fatalf("Unexpected slot at state %d, want %d, got %d", i, i, got)
+		}
+		if got := state.GetBalance(unchange).Uint64(); got != 100 {
+			t.Fatalf("Unexpected balance of unchanged account at state %d: %d", i, got)
+		}
+		if got := state.GetCode(unchange); len(got) != 1 {
+			t.Fatalf("Unexpected code of unchanged account at state %d: %x", i, got)
+		}
+		// Mutations are allowed but never persisted.
+		state.SetBalance(addr, uint256.NewInt(1000), tracing.BalanceChangeUnspecified)
+		if got := state.IntermediateRoot(false); got != roots[i] {
+			t.Fatalf("Unexpected root of historical state, want %x, got %x", roots[i], got)
+		}
+	}
+	// The state in the disk layer is served by the live database.
+	if _, err := New(roots[5], historic); err == nil {
+		t.Fatal("Unexpected historical state of the disk layer")
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+)
+
+// migrationStatus is the progress of the state scheme migration, persisted
+// in the database for resuming the migration after an interruption.
+type migrationStatus struct {
+	Root   common.Hash // The state root being migrated
+	Number uint64      // The number of the block the state belongs to
+	Done   bool        // Whether all the trie nodes have been migrated
+	Marker []byte      // The hash of the last account which is fully migrated
+}
+
+// MigrateScheme converts the persisted hash-based state into the path-based
+// layout offline. The workflow is:
+//
+//   - iterate the state trie with the specified root, write all the trie nodes
+//     in the path-based layout along with the contract codes in the prefixed
+//     format. The root node is written at last, which flips the state scheme.
+//   - rebuild the state snapshot on top of the path-based state if it's not
+//     matched with the migrated state.
+//   - iterate the database, delete all the legacy trie nodes.
+//
+// The progress is persisted periodically, the migration can be resumed by
+// invoking the function again after an interruption. If the root is not
+// specified, the latest persisted state of the canonical chain is used.
+func MigrateScheme(db ethdb.Database, root common.Hash) error {
+	start := time.Now()
+
+	var status *migrationStatus
+	if blob := rawdb.ReadSchemeMigrationStatus(db); len(blob) > 0 {
+		status = new(migrationStatus)
+		if err := rlp.DecodeBytes(blob, status); err != nil {
+			return fmt.Errorf("invalid migration status: %v", err)
+		}
+		if root != (common.Hash{}) && root != status.Root {
+			return fmt.Errorf("migration of state %x is in progress", status.Root)
+		}
+		log.Info("Resuming state scheme migration", "root", status.Root, "number", status.Number, "done", status.Done)
+	} else {
+		if scheme := rawdb.ReadStateScheme(db); scheme != rawdb.HashScheme {
+			return fmt.Errorf("state scheme is not hash, got: %q", scheme)
+		}
+		number, err := findPersistedState(db, root)
+		if err != nil {
+			return err
+		}
+		if root == (common.Hash{}) {
+			root = rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, number), number).Root
+		}
+		status = &migrationStatus{Root: root, Number: number}
+		writeMigrationStatus(db, status)
+		log.Info("Starting state scheme migration", "root", root, "number", number)
+	}
+	if !status.Done {
+		if err := migrateNodes(db, status); err != nil {
+			return err
+		}
+	}
+	if err := rebuildSnapshot(db, status.Root); err != nil {
+		return err
+	}
+	if err := removeLegacyNodes(db); err != nil {
+		return err
+	}
+	rawdb.DeleteSchemeMigrationStatus(db)
+
+	if head := rawdb.ReadHeadBlock(db); head != nil && head.NumberU64() > status.Number {
+		log.Warn("Chain head will be rewound to the migrated state", "head", head.NumberU64(), "number", status.Number)
+	}
+	log.Info("State scheme migration successful", "root", status.Root, "number", status.Number, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// findPersistedState returns the number of the block whose state is persisted.
+// If the root is not specified, the canonical chain is searched from the head
+// for the latest persisted state.
+func findPersistedState(db ethdb.Database, root common.Hash) (uint64, error) {
+	head := rawdb.ReadHeadBlock(db)
+	if head == nil {
+		return 0, errors.New("head block is not available")
+	}
+	for number := head.NumberU64(); ; number-- {
+		header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, number), number)
+		if header == nil {
+			return 0, fmt.Errorf("header %d is not available", number)
+		}
+		if root == (common.Hash{}) && rawdb.HasLegacyTrieNode(db, header.Root) {
+			return number, nil
+		}
+		if root != (common.Hash{}) && header.Root == root {
+			if !rawdb.HasLegacyTrieNode(db, root) {
+				return 0, fmt.Errorf("state %x is not persisted", root)
+			}
+			return number, nil
+		}
+		if number == 0 {
+			break
+		}
+	}
+	if root == (common.Hash{}) {
+		return 0, errors.New("no persisted state")
+	}
+	return 0, fmt.Errorf("state %x is not in the canonical chain", root)
+}
+
+func writeMigrationStatus(db ethdb.KeyValueWriter, status *migrationStatus) {
+	blob, err := rlp.EncodeToBytes(status)
+	if err != nil {
+		panic(err) // can't happen
+	}
+	rawdb.WriteSchemeMigrationStatus(db, blob)
+}
+
+// migrateNodes writes the trie nodes of the given state in the path-based
+// layout. The progress marker is persisted together with the migrated nodes,
+// so that the migration can be resumed from the last fully migrated account.
+func migrateNodes(db ethdb.Database, status *migrationStatus) error {
+	hashdb := triedb.NewDatabase(db, triedb.HashDefaults)
+	defer hashdb.Close()
+
+	tr, err := trie.NewStateTrie(trie.StateTrieID(status.Root), hashdb)
+	if err != nil {
+		return err
+	}
+	iter, err := tr.NodeIterator(status.Marker)
+	if err != nil {
+		return err
+	}
+	var (
+		accounts, nodes int
+		size            common.StorageSize
+		start           = time.Now()
+		logged          = time.Now()
+		batch           = db.NewBatch()
+	)
+	for iter.Next(true) {
+		if hash := iter.Hash(); hash != (common.Hash{}) {
+			// The root node is written at last, it flips the state scheme
+			// to path once it's present.
+			if len(iter.Path()) > 0 {
+				blob := iter.NodeBlob()
+				rawdb.WriteAccountTrieNode(batch, iter.Path(), blob)
+				nodes++
+				size += common.StorageSize(len(iter.Path()) + len(blob))
+			}
+		}
+		if !iter.Leaf() {
+			continue
+		}
+		key := iter.LeafKey()
+		if bytes.Equal(key, status.Marker) {
+			continue // already migrated
+		}
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(iter.LeafBlob(), &account); err != nil {
+			return err
+		}
+		owner := common.BytesToHash(key)
+		if account.Root != types.EmptyRootHash {
+			st, err := trie.NewStateTrie(trie.StorageTrieID(status.Root, owner, account.Root), hashdb)
+			if err != nil {
+				return err
+			}
+			siter, err := st.NodeIterator(nil)
+			if err != nil {
+				return err
+			}
+			for siter.Next(true) {
+				if siter.Hash() == (common.Hash{}) {
+					continue
+				}
+				blob := siter.NodeBlob()
+				rawdb.WriteStorageTrieNode(batch, owner, siter.Path(), blob)
+				nodes++
+				size += common.StorageSize(common.HashLength + len(siter.Path()) + len(blob))
+
+				if batch.ValueSize() >= ethdb.IdealBatchSize {
+					if err := batch.Write(); err != nil {
+						return err
+					}
+					batch.Reset()
+				}
+			}
+			if siter.Error() != nil {
+				return siter.Error()
+			}
+		}
+		// Contract codes are always stored with prefix in path-based scheme,
+		// convert the legacy one as it will be deleted along with trie nodes.
+		codeHash := common.BytesToHash(account.CodeHash)
+		if codeHash != types.EmptyCodeHash && !rawdb.HasCodeWithPrefix(db, codeHash) {
+			code := rawdb.ReadCode(db, codeHash)
+			if len(code) == 0 {
+				return fmt.Errorf("missing code %x of account %x", codeHash, owner)
+			}
+			rawdb.WriteCode(batch, codeHash, code)
+		}
+		accounts++
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			status.Marker = common.CopyBytes(key)
+			writeMigrationStatus(batch, status)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Migrating state data", "accounts", accounts, "nodes", nodes, "size", size,
+				"marker", common.BytesToHash(key), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if iter.Error() != nil {
+		return iter.Error()
+	}
+	// All the nodes are migrated, write the root node and mark the
+	// persistent state as the initial state of path-based database.
+	blob := rawdb.ReadLegacyTrieNode(db, status.Root)
+	if len(blob) == 0 {
+		return fmt.Errorf("missing state root %x", status.Root)
+	}
+	rawdb.WriteAccountTrieNode(batch, nil, blob)
+	rawdb.WritePersistentStateID(batch, 0)
+	rawdb.DeleteTrieJournal(batch)
+
+	status.Done, status.Marker = true, nil
+	writeMigrationStatus(batch, status)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Migrated trie nodes", "accounts", accounts, "nodes", nodes, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// rebuildSnapshot regenerates the state snapshot on top of the migrated state
+// if the existing one is not matched.
+func rebuildSnapshot(db ethdb.Database, root common.Hash) error {
+	tdb := triedb.NewDatabase(db, &triedb.Config{PathDB: pathdb.Defaults})
+	defer tdb.Close()
+
+	// The snapshot is kept if it's already matched with the migrated state,
+	// otherwise it's regenerated and the construction is waited here.
+	snaptree, err := snapshot.New(snapshot.Config{CacheSize: 256}, db, tdb, root)
+	if err != nil {
+		return err
+	}
+	defer snaptree.Release()
+
+	_, err = snaptree.Journal(root)
+	return err
+}
+
+// removeLegacyNodes deletes all the trie nodes and contract codes stored in the
+// legacy hash-based layout.
+func removeLegacyNodes(db ethdb.Database) error {
+	var (
+		count  int
+		size   common.StorageSize
+		start  = time.Now()
+		logged = time.Now()
+		batch  = db.NewBatch()
+		iter   = db.NewIterator(nil, nil)
+	)
+	for iter.Next() {
+		key := iter.Key()
+		if len(key) != common.HashLength || !bytes.Equal(crypto.Keccak256(iter.Value()), key) {
+			continue
+		}
+		count += 1
+		size += common.StorageSize(len(key) + len(iter.Value()))
+		batch.Delete(key)
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Removing legacy state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		// Recreate the iterator after every batch commit in order
+		// to allow the underlying compactor to delete the entries.
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+
+			iter.Release()
+			iter = db.NewIterator(nil, key)
+		}
+	}
+	iter.Release()
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if count >= rangeCompactionThreshold {
+		cstart := time.Now()
+		log.Info("Compacting database", "elapsed", common.PrettyDuration(time.Since(cstart)))
+		if err := db.Compact(nil, nil); err != nil {
+			return err
+		}
+		log.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	}
+	log.Info("Removed legacy state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
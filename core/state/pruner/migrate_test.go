@@ -0,0 +1,119 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/holiman/uint256"
+)
+
+func TestMigrateScheme(t *testing.T) {
+	var (
+		db   = rawdb.NewMemoryDatabase()
+		tdb  = triedb.NewDatabase(db, triedb.HashDefaults)
+		code = []byte{0x60, 0x00, 0x60, 0x00}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(tdb, nil))
+	for i := byte(1); i <= 100; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		statedb.SetBalance(addr, uint256.NewInt(uint64(i)), tracing.BalanceChangeUnspecified)
+		if i%10 == 0 {
+			statedb.SetCode(addr, code)
+			for j := byte(1); j <= 20; j++ {
+				statedb.SetState(addr, common.BytesToHash([]byte{j}), common.BytesToHash([]byte{i, j}))
+			}
+		}
+	}
+	root, err := statedb.Commit(0, false)
+	if err != nil {
+		t.Fatalf("Failed to commit state: %v", err)
+	}
+	if err := tdb.Commit(root, false); err != nil {
+		t.Fatalf("Failed to flush state: %v", err)
+	}
+	tdb.Close()
+
+	// Store the code in the legacy format, which should be converted.
+	codeHash := crypto.Keccak256Hash(code)
+	rawdb.DeleteCode(db, codeHash)
+	db.Put(codeHash.Bytes(), code)
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), Root: root})
+	rawdb.WriteBlock(db, block)
+	rawdb.WriteCanonicalHash(db, block.Hash(), 0)
+	rawdb.WriteHeadBlockHash(db, block.Hash())
+
+	if err := MigrateScheme(db, common.Hash{}); err != nil {
+		t.Fatalf("Failed to migrate state: %v", err)
+	}
+	if scheme := rawdb.ReadStateScheme(db); scheme != rawdb.PathScheme {
+		t.Fatalf("Unexpected state scheme %q", scheme)
+	}
+	if rawdb.HasLegacyTrieNode(db, root) {
+		t.Fatal("Legacy trie node is not removed")
+	}
+	if !bytes.Equal(rawdb.ReadCodeWithPrefix(db, codeHash), code) {
+		t.Fatal("Contract code is not migrated")
+	}
+	if got := rawdb.ReadSnapshotRoot(db); got != root {
+		t.Fatalf("Unexpected snapshot root, want %x, got %x", root, got)
+	}
+	if len(rawdb.ReadSchemeMigrationStatus(db)) != 0 {
+		t.Fatal("Migration status is not removed")
+	}
+	pdb := triedb.NewDatabase(db, &triedb.Config{PathDB: pathdb.Defaults})
+	defer pdb.Close()
+
+	statedb, err = state.New(root, state.NewDatabase(pdb, nil))
+	if err != nil {
+		t.Fatalf("Failed to open migrated state: %v", err)
+	}
+	for i := byte(1); i <= 100; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		if got := statedb.GetBalance(addr).Uint64(); got != uint64(i) {
+			t.Fatalf("Unexpected balance of %x, want %d, got %d", addr, i, got)
+		}
+		if i%10 != 0 {
+			continue
+		}
+		if !bytes.Equal(statedb.GetCode(addr), code) {
+			t.Fatalf("Unexpected code of %x", addr)
+		}
+		for j := byte(1); j <= 20; j++ {
+			if got := statedb.GetState(addr, common.BytesToHash([]byte{j})); got != common.BytesToHash([]byte{i, j}) {
+				t.Fatalf("Unexpected slot of %x, got %x", addr, got)
+			}
+		}
+	}
+	if err := statedb.Error(); err != nil {
+		t.Fatalf("Failed to read migrated state: %v", err)
+	}
+	if got := statedb.IntermediateRoot(false); got != root {
+		t.Fatalf("Unexpected state root, want %x, got %x", root, got)
+	}
+}
@@ -0,0 +1,455 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// The state file is a portable representation of the entire state at a given
+// root. It starts with a magic marker and the RLP-encoded StateFileHeader,
+// followed by a stream of RLP-encoded chunks. Every chunk carries a batch of
+// state records along with its keccak256 checksum, and an empty chunk marks
+// the end of the stream.
+//
+// The records are ordered as the snapshot iteration: every account is followed
+// by its contract code (unless it was already exported) and its storage slots,
+// all ordered by their hashes.
+
+const (
+	// stateFileVersion is the current version of the state file format.
+	stateFileVersion = 1
+
+	// stateChunkSize is the approximate size of the records in a chunk.
+	stateChunkSize = 4 * 1024 * 1024
+)
+
+// stateFileMagic is the marker at the beginning of the state files.
+var stateFileMagic = []byte("GETHSTATE")
+
+var (
+	// errInvalidStateFile is returned if the state file is malformed.
+	errInvalidStateFile = errors.New("invalid state file")
+
+	// errStateChecksum is returned if the checksum of a state chunk mismatches.
+	errStateChecksum = errors.New("state chunk checksum mismatch")
+)
+
+// The kinds of the state records.
+const (
+	stateRecordAccount = iota // Key is the account hash, Value is the slim account
+	stateRecordCode           // Key is the code hash, Value is the contract code
+	stateRecordStorage        // Key is the slot hash, Value is the slot of the last account
+)
+
+// StateFileHeader is the metadata of a state file.
+type StateFileHeader struct {
+	Version uint64
+	Root    common.Hash // Root of the exported state
+	Number  uint64      // Number of the block the state belongs to
+	Hash    common.Hash // Hash of the block the state belongs to
+}
+
+// stateRecord is an entry of the exported state.
+type stateRecord struct {
+	Kind  uint8
+	Key   common.Hash
+	Value []byte
+}
+
+// stateChunk is a batch of RLP-encoded state records with its checksum.
+type stateChunk struct {
+	Records  []byte
+	Checksum common.Hash
+}
+
+// stateChunkWriter batches the state records into checksummed chunks.
+type stateChunkWriter struct {
+	w       io.Writer
+	records []stateRecord
+	size    int
+	chunks  int
+}
+
+// add appends a record to the current chunk, flushing it if it's full.
+func (cw *stateChunkWriter) add(kind uint8, key common.Hash, value []byte) error {
+	cw.records = append(cw.records, stateRecord{Kind: kind, Key: key, Value: value})
+	cw.size += common.HashLength + len(value)
+	if cw.size >= stateChunkSize {
+		return cw.flush()
+	}
+	return nil
+}
+
+// flush writes out the current chunk. An empty chunk terminates the stream.
+func (cw *stateChunkWriter) flush() error {
+	var records []byte
+	if len(cw.records) > 0 {
+		var err error
+		if records, err = rlp.EncodeToBytes(cw.records); err != nil {
+			return err
+		}
+	}
+	chunk := stateChunk{Records: records, Checksum: crypto.Keccak256Hash(records)}
+	if err := rlp.Encode(cw.w, &chunk); err != nil {
+		return err
+	}
+	cw.records, cw.size = cw.records[:0], 0
+	cw.chunks++
+	return nil
+}
+
+// ExportState streams the accounts, contract codes and storage slots of the
+// state with the root in the header from the snapshot into the writer. The
+// contract codes are read from the given database.
+func ExportState(w io.Writer, snaptree *Tree, db ethdb.KeyValueReader, header *StateFileHeader) error {
+	header.Version = stateFileVersion
+	if _, err := w.Write(stateFileMagic); err != nil {
+		return err
+	}
+	if err := rlp.Encode(w, header); err != nil {
+		return err
+	}
+	accIt, err := snaptree.AccountIterator(header.Root, common.Hash{})
+	if err != nil {
+		return err
+	}
+	defer accIt.Release()
+
+	var (
+		cw     = &stateChunkWriter{w: w}
+		codes  = make(map[common.Hash]struct{})
+		start  = time.Now()
+		logged = time.Now()
+
+		accounts, slots uint64
+	)
+	for accIt.Next() {
+		account, err := types.FullAccount(accIt.Account())
+		if err != nil {
+			return err
+		}
+		if err := cw.add(stateRecordAccount, accIt.Hash(), accIt.Account()); err != nil {
+			return err
+		}
+		accounts++
+
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != types.EmptyCodeHash {
+			if _, ok := codes[codeHash]; !ok {
+				code := rawdb.ReadCode(db, codeHash)
+				if len(code) == 0 {
+					return fmt.Errorf("missing code %x of account %x", codeHash, accIt.Hash())
+				}
+				if err := cw.add(stateRecordCode, codeHash, code); err != nil {
+					return err
+				}
+				codes[codeHash] = struct{}{}
+			}
+		}
+		if account.Root != types.EmptyRootHash {
+			stIt, err := snaptree.StorageIterator(header.Root, accIt.Hash(), common.Hash{})
+			if err != nil {
+				return err
+			}
+			for stIt.Next() {
+				if err := cw.add(stateRecordStorage, stIt.Hash(), stIt.Slot()); err != nil {
+					stIt.Release()
+					return err
+				}
+				slots++
+			}
+			err = stIt.Error()
+			stIt.Release()
+			if err != nil {
+				return err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting state", "at", accIt.Hash(), "accounts", accounts, "slots", slots,
+				"codes", len(codes), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIt.Error(); err != nil {
+		return err
+	}
+	// Flush the last records and terminate the stream
+	if len(cw.records) > 0 {
+		if err := cw.flush(); err != nil {
+			return err
+		}
+	}
+	if err := cw.flush(); err != nil {
+		return err
+	}
+	log.Info("Exported state", "root", header.Root, "accounts", accounts, "slots", slots,
+		"codes", len(codes), "chunks", cw.chunks, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// stateImporter rebuilds the tries of the imported state.
+type stateImporter struct {
+	batch  ethdb.Batch // Batch to write the state into, nil if only verifying
+	scheme string
+
+	accTrie *trie.StackTrie // Account trie being rebuilt
+
+	account     common.Hash         // Hash of the account being imported
+	accountData *types.StateAccount // Account being imported, nil if none
+	storageTrie *trie.StackTrie     // Storage trie of the account being imported
+
+	accounts, slots, codes uint64
+}
+
+// newTrie creates a stack trie writing the nodes of the given owner into the
+// current batch, or only hashing them if verifying.
+func (imp *stateImporter) newTrie(owner common.Hash) *trie.StackTrie {
+	if imp.batch == nil {
+		return trie.NewStackTrie(nil)
+	}
+	return trie.NewStackTrie(func(path []byte, hash common.Hash, blob []byte) {
+		rawdb.WriteTrieNode(imp.batch, owner, path, hash, blob, imp.scheme)
+	})
+}
+
+// finishAccount verifies the storage of the account being imported and inserts
+// the account into the account trie.
+func (imp *stateImporter) finishAccount() error {
+	if imp.accountData == nil {
+		return nil
+	}
+	root := types.EmptyRootHash
+	if imp.storageTrie != nil {
+		root = imp.storageTrie.Hash()
+	}
+	if root != imp.accountData.Root {
+		return fmt.Errorf("storage root mismatch of account %x: have %x, want %x", imp.account, root, imp.accountData.Root)
+	}
+	blob, err := rlp.EncodeToBytes(imp.accountData)
+	if err != nil {
+		return err
+	}
+	if err := imp.accTrie.Update(imp.account.Bytes(), blob); err != nil {
+		return err
+	}
+	imp.accountData, imp.storageTrie = nil, nil
+	return nil
+}
+
+// process imports a state record.
+func (imp *stateImporter) process(record *stateRecord) error {
+	switch record.Kind {
+	case stateRecordAccount:
+		if err := imp.finishAccount(); err != nil {
+			return err
+		}
+		account, err := types.FullAccount(record.Value)
+		if err != nil {
+			return err
+		}
+		imp.account, imp.accountData = record.Key, account
+		if account.Root != types.EmptyRootHash {
+			imp.storageTrie = imp.newTrie(record.Key)
+		}
+		if imp.batch != nil {
+			rawdb.WriteAccountSnapshot(imp.batch, record.Key, record.Value)
+		}
+		imp.accounts++
+
+	case stateRecordCode:
+		if crypto.Keccak256Hash(record.Value) != record.Key {
+			return fmt.Errorf("code hash mismatch: %x", record.Key)
+		}
+		if imp.batch != nil {
+			rawdb.WriteCode(imp.batch, record.Key, record.Value)
+		}
+		imp.codes++
+
+	case stateRecordStorage:
+		if imp.storageTrie == nil {
+			return fmt.Errorf("%w: storage slot %x without account", errInvalidStateFile, record.Key)
+		}
+		if err := imp.storageTrie.Update(record.Key.Bytes(), record.Value); err != nil {
+			return err
+		}
+		if imp.batch != nil {
+			rawdb.WriteStorageSnapshot(imp.batch, imp.account, record.Key, record.Value)
+		}
+		imp.slots++
+
+	default:
+		return fmt.Errorf("%w: unknown record kind %d", errInvalidStateFile, record.Kind)
+	}
+	if imp.batch != nil && imp.batch.ValueSize() >= ethdb.IdealBatchSize {
+		if err := imp.batch.Write(); err != nil {
+			return err
+		}
+		imp.batch.Reset()
+	}
+	return nil
+}
+
+// ImportState reads the state file opened by the given function, rebuilds the
+// account and storage tries of the state with the given node scheme and writes
+// them along with the contract codes and the snapshot into the database.
+//
+// The file is read twice: the rebuilt state root is checked against the header
+// before anything is written, so a corrupted or mismatching file leaves the
+// database untouched. The header is returned on success.
+func ImportState(open func() (io.ReadCloser, error), db ethdb.KeyValueStore, scheme string) (*StateFileHeader, error) {
+	start := time.Now()
+	verified, err := readStateFile(open, &stateImporter{scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Verified state file", "root", verified.Root, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Drop the snapshot of any previous state, the imported one replaces it
+	if err := wipeSnapshot(db); err != nil {
+		return nil, err
+	}
+	imp := &stateImporter{batch: db.NewBatch(), scheme: scheme}
+	header, err := readStateFile(open, imp)
+	if err != nil {
+		return nil, err
+	}
+	if *header != *verified {
+		return nil, errors.New("state file changed during import")
+	}
+	// Mark the snapshot of the imported state as fully generated
+	rawdb.WriteSnapshotRoot(imp.batch, header.Root)
+	rawdb.DeleteSnapshotDisabled(imp.batch)
+	rawdb.DeleteSnapshotRecoveryNumber(imp.batch)
+	journalProgress(imp.batch, nil, &generatorStats{accounts: imp.accounts, slots: imp.slots})
+	if err := imp.batch.Write(); err != nil {
+		return nil, err
+	}
+	log.Info("Imported state", "root", header.Root, "accounts", imp.accounts, "slots", imp.slots,
+		"codes", imp.codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return header, nil
+}
+
+// readStateFile streams the records of the state file into the importer and
+// checks the rebuilt state root against the header.
+func readStateFile(open func() (io.ReadCloser, error), imp *stateImporter) (*StateFileHeader, error) {
+	r, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(stateFileMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || !bytes.Equal(magic, stateFileMagic) {
+		return nil, fmt.Errorf("%w: missing magic", errInvalidStateFile)
+	}
+	var (
+		stream = rlp.NewStream(br, 0)
+		header StateFileHeader
+	)
+	if err := stream.Decode(&header); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidStateFile, err)
+	}
+	if header.Version != stateFileVersion {
+		return nil, fmt.Errorf("unsupported state file version %d", header.Version)
+	}
+	imp.accTrie = imp.newTrie(common.Hash{})
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for chunks := 0; ; chunks++ {
+		var chunk stateChunk
+		if err := stream.Decode(&chunk); err != nil {
+			return nil, fmt.Errorf("%w: chunk %d: %v", errInvalidStateFile, chunks, err)
+		}
+		if crypto.Keccak256Hash(chunk.Records) != chunk.Checksum {
+			return nil, fmt.Errorf("%w: chunk %d", errStateChecksum, chunks)
+		}
+		if len(chunk.Records) == 0 {
+			break
+		}
+		var records []stateRecord
+		if err := rlp.DecodeBytes(chunk.Records, &records); err != nil {
+			return nil, fmt.Errorf("%w: chunk %d: %v", errInvalidStateFile, chunks, err)
+		}
+		for i := range records {
+			if err := imp.process(&records[i]); err != nil {
+				return nil, err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing state", "verify", imp.batch == nil, "chunks", chunks, "accounts", imp.accounts,
+				"slots", imp.slots, "codes", imp.codes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := imp.finishAccount(); err != nil {
+		return nil, err
+	}
+	if root := imp.accTrie.Hash(); root != header.Root {
+		return nil, fmt.Errorf("state root mismatch: have %x, want %x", root, header.Root)
+	}
+	return &header, nil
+}
+
+// wipeSnapshot deletes the snapshot accounts and storage slots from the
+// database.
+func wipeSnapshot(db ethdb.KeyValueStore) error {
+	batch := db.NewBatch()
+	for _, prefix := range [][]byte{rawdb.SnapshotAccountPrefix, rawdb.SnapshotStoragePrefix} {
+		keylen := len(prefix) + common.HashLength
+		if bytes.Equal(prefix, rawdb.SnapshotStoragePrefix) {
+			keylen += common.HashLength
+		}
+		it := db.NewIterator(prefix, nil)
+		for it.Next() {
+			if len(it.Key()) != keylen {
+				continue
+			}
+			batch.Delete(it.Key())
+			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		it.Release()
+		if err := it.Error(); err != nil {
+			return err
+		}
+	}
+	rawdb.DeleteSnapshotRoot(batch)
+	return batch.Write()
+}
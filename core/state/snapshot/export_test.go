@@ -0,0 +1,148 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/hashdb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/holiman/uint256"
+)
+
+func TestExportImportState(t *testing.T) {
+	testExportImportState(t, rawdb.HashScheme)
+	testExportImportState(t, rawdb.PathScheme)
+}
+
+func testExportImportState(t *testing.T, scheme string) {
+	var (
+		helper   = newHelper(scheme)
+		code     = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+		codeHash = crypto.Keccak256Hash(code)
+	)
+	rawdb.WriteCode(helper.diskdb, codeHash, code)
+
+	stRoot := helper.makeStorageTrie(hashData([]byte("acc-1")), []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"}, true)
+	helper.addTrieAccount("acc-1", &types.StateAccount{Balance: uint256.NewInt(1), Root: stRoot, CodeHash: codeHash.Bytes()})
+	helper.addTrieAccount("acc-2", &types.StateAccount{Balance: uint256.NewInt(2), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()})
+	helper.addTrieAccount("acc-3", &types.StateAccount{Balance: uint256.NewInt(3), Root: types.EmptyRootHash, CodeHash: codeHash.Bytes()})
+
+	root, snap := helper.CommitAndGenerate()
+	select {
+	case <-snap.genPending:
+	case <-time.After(3 * time.Second):
+		t.Fatal("snapshot generation failed")
+	}
+	snaptree := &Tree{layers: map[common.Hash]snapshot{root: snap}}
+
+	var buf bytes.Buffer
+	header := &StateFileHeader{Root: root, Number: 10, Hash: common.Hash{0x1}}
+	if err := ExportState(&buf, snaptree, helper.diskdb, header); err != nil {
+		t.Fatalf("failed to export state: %v", err)
+	}
+	stop := make(chan *generatorStats)
+	snap.genAbort <- stop
+	<-stop
+
+	// Import the state into a fresh database and check its content
+	db := rawdb.NewMemoryDatabase()
+	imported, err := ImportState(stateFile(buf.Bytes()), db, scheme)
+	if err != nil {
+		t.Fatalf("failed to import state: %v", err)
+	}
+	if imported.Root != root || imported.Number != 10 || imported.Hash != (common.Hash{0x1}) {
+		t.Fatalf("header mismatch: %+v", imported)
+	}
+	if !bytes.Equal(rawdb.ReadCode(db, codeHash), code) {
+		t.Fatal("contract code is missing")
+	}
+	config := &triedb.Config{HashDB: &hashdb.Config{}}
+	if scheme == rawdb.PathScheme {
+		config = &triedb.Config{PathDB: &pathdb.Config{}}
+	}
+	tdb := triedb.NewDatabase(db, config)
+	accTrie, err := trie.NewStateTrie(trie.StateTrieID(root), tdb)
+	if err != nil {
+		t.Fatalf("failed to open account trie: %v", err)
+	}
+	if hash := accTrie.Hash(); hash != root {
+		t.Fatalf("account trie root mismatch: have %x, want %x", hash, root)
+	}
+	stTrie, err := trie.NewStateTrie(trie.StorageTrieID(root, hashData([]byte("acc-1")), stRoot), tdb)
+	if err != nil {
+		t.Fatalf("failed to open storage trie: %v", err)
+	}
+	if val := stTrie.MustGet([]byte("key-2")); string(val) != "val-2" {
+		t.Fatalf("storage slot mismatch: %q", val)
+	}
+	// The snapshot of the imported state is complete
+	if rawdb.ReadSnapshotRoot(db) != root {
+		t.Fatal("snapshot root is missing")
+	}
+	if len(rawdb.ReadAccountSnapshot(db, hashData([]byte("acc-2")))) == 0 {
+		t.Fatal("account snapshot is missing")
+	}
+	if len(rawdb.ReadStorageSnapshot(db, hashData([]byte("acc-1")), hashData([]byte("key-1")))) == 0 {
+		t.Fatal("storage snapshot is missing")
+	}
+	loaded, _, err := loadSnapshot(db, tdb, root, 16, false, true)
+	if err != nil {
+		t.Fatalf("failed to load imported snapshot: %v", err)
+	}
+	if marker := loaded.(*diskLayer).genMarker; marker != nil {
+		t.Fatalf("imported snapshot is not complete: marker %x", marker)
+	}
+	// Corrupt a chunk of the file
+	corrupt := bytes.Clone(buf.Bytes())
+	corrupt[len(corrupt)-60] ^= 0xff
+	if _, err := ImportState(stateFile(corrupt), rawdb.NewMemoryDatabase(), scheme); !errors.Is(err, errStateChecksum) {
+		t.Fatalf("unexpected error for corrupted file: %v", err)
+	}
+	// Drop the terminating chunk of the file
+	if _, err := ImportState(stateFile(buf.Bytes()[:buf.Len()-34]), rawdb.NewMemoryDatabase(), scheme); !errors.Is(err, errInvalidStateFile) {
+		t.Fatalf("unexpected error for truncated file: %v", err)
+	}
+	// Claim a different state root in the header, nothing must be written
+	tampered := bytes.Replace(buf.Bytes(), root.Bytes(), common.Hash{0x1}.Bytes(), 1)
+	empty := rawdb.NewMemoryDatabase()
+	if _, err := ImportState(stateFile(tampered), empty, scheme); err == nil {
+		t.Fatal("state with mismatching root imported")
+	}
+	it := empty.NewIterator(nil, nil)
+	defer it.Release()
+	if it.Next() {
+		t.Fatalf("mismatching state partially imported: key %x", it.Key())
+	}
+}
+
+// stateFile returns an opener of the given state file content.
+func stateFile(data []byte) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
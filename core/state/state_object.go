@@ -87,6 +87,9 @@ type stateObject struct {
 
 // empty returns whether the account is considered empty.
 func (s *stateObject) empty() bool {
+	if s.db.tracker != nil {
+		s.db.tracker.readField(s.address)
+	}
 	return s.data.Nonce == 0 && s.data.Balance.IsZero() && bytes.Equal(s.data.CodeHash, types.EmptyCodeHash.Bytes())
 }
 
@@ -170,6 +173,9 @@ func (s *stateObject) getState(key common.Hash) (common.Hash, common.Hash) {
 // GetCommittedState retrieves the value associated with the specific key
 // without any mutations caused in the current execution.
 func (s *stateObject) GetCommittedState(key common.Hash) common.Hash {
+	if s.db.tracker != nil {
+		s.db.tracker.readSlot(s.address, key)
+	}
 	// If we have a pending write or clean cached, return that
 	if value, pending := s.pendingStorage[key]; pending {
 		return value
@@ -183,7 +189,7 @@ func (s *stateObject) GetCommittedState(key common.Hash) common.Hash {
 	//   1) resurrect happened, and new slot values were set -- those should
 	//      have been handles via pendingStorage above.
 	//   2) we don't have new values, and can deliver empty response back
-	if _, destructed := s.db.stateObjectsDestruct[s.address]; destructed {
+	if s.db.destructed(s.address) {
 		s.originStorage[key] = common.Hash{} // track the empty slot as origin value
 		return common.Hash{}
 	}
@@ -514,6 +520,9 @@ func (s *stateObject) Address() common.Address {
 
 // Code returns the contract code associated with this object, if any.
 func (s *stateObject) Code() []byte {
+	if s.db.tracker != nil {
+		s.db.tracker.readField(s.address)
+	}
 	if len(s.code) != 0 {
 		return s.code
 	}
@@ -532,6 +541,9 @@ func (s *stateObject) Code() []byte {
 // or zero if none. This method is an almost mirror of Code, but uses a cache
 // inside the database to avoid loading codes seen recently.
 func (s *stateObject) CodeSize() int {
+	if s.db.tracker != nil {
+		s.db.tracker.readField(s.address)
+	}
 	if len(s.code) != 0 {
 		return len(s.code)
 	}
@@ -573,17 +585,29 @@ func (s *stateObject) setNonce(nonce uint64) {
 }
 
 func (s *stateObject) CodeHash() []byte {
+	if s.db.tracker != nil {
+		s.db.tracker.readField(s.address)
+	}
 	return s.data.CodeHash
 }
 
 func (s *stateObject) Balance() *uint256.Int {
+	if s.db.tracker != nil {
+		s.db.tracker.readField(s.address)
+	}
 	return s.data.Balance
 }
 
 func (s *stateObject) Nonce() uint64 {
+	if s.db.tracker != nil {
+		s.db.tracker.readField(s.address)
+	}
 	return s.data.Nonce
 }
 
 func (s *stateObject) Root() common.Hash {
+	if s.db.tracker != nil {
+		s.db.tracker.readField(s.address)
+	}
 	return s.data.Root
 }
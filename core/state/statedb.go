@@ -141,6 +141,14 @@ type StateDB struct {
 	// State witness if cross validation is needed
 	witness *stateless.Witness
 
+	// Tracker of the state items accessed by the current transaction, only
+	// enabled for parallel execution
+	tracker *AccessSet
+
+	// State the accounts are copied from on first access, only set for the
+	// lazy copies made by LazyCopy
+	parent *StateDB
+
 	// Measurements gathered during execution for debugging purposes
 	AccountReads    time.Duration
 	AccountHashes   time.Duration
@@ -411,6 +419,9 @@ func (s *StateDB) HasSelfDestructed(addr common.Address) bool {
 
 // AddBalance adds amount to the account associated with addr.
 func (s *StateDB) AddBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) {
+	if s.tracker != nil && s.tracker.deferCredit(addr, amount) {
+		return
+	}
 	stateObject := s.getOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.AddBalance(amount, reason)
@@ -564,6 +575,9 @@ func (s *StateDB) deleteStateObject(addr common.Address) {
 // getStateObject retrieves a state object given by the address, returning nil if
 // the object is not found or was deleted in this execution context.
 func (s *StateDB) getStateObject(addr common.Address) *stateObject {
+	if s.tracker != nil {
+		s.tracker.readExist(addr)
+	}
 	// Prefer live objects if any is available
 	if obj := s.stateObjects[addr]; obj != nil {
 		return obj
@@ -572,6 +586,17 @@ func (s *StateDB) getStateObject(addr common.Address) *stateObject {
 	if _, ok := s.stateObjectsDestruct[addr]; ok {
 		return nil
 	}
+	// Copy the account from the original state if this is a lazy copy
+	if s.parent != nil {
+		if obj := s.parent.stateObjects[addr]; obj != nil {
+			obj = obj.deepCopy(s)
+			s.setStateObject(obj)
+			return obj
+		}
+		if _, ok := s.parent.stateObjectsDestruct[addr]; ok {
+			return nil
+		}
+	}
 	s.AccountLoaded++
 
 	start := time.Now()
@@ -702,6 +727,49 @@ func (s *StateDB) Copy() *StateDB {
 	return state
 }
 
+// LazyCopy creates a copy of the state for executing a single transaction on.
+// Unlike Copy, the accounts are only copied from the original state when they
+// are accessed, which makes it cheap regardless of the size of the state.
+//
+// The original state must not be modified while the copy is in use, and the
+// copy must not be committed or hashed. It's meant for executing transactions
+// speculatively, with the changes applied to the original state through
+// MergeTransaction.
+func (s *StateDB) LazyCopy() *StateDB {
+	return &StateDB{
+		db:                   s.db,
+		trie:                 mustCopyTrie(s.trie),
+		reader:               s.reader.Copy(),
+		originalRoot:         s.originalRoot,
+		stateObjects:         make(map[common.Address]*stateObject),
+		stateObjectsDestruct: make(map[common.Address]*stateObject),
+		mutations:            make(map[common.Address]*mutation),
+		dbErr:                s.dbErr,
+		refund:               s.refund,
+		thash:                s.thash,
+		txIndex:              s.txIndex,
+		logs:                 make(map[common.Hash][]*types.Log),
+		logSize:              s.logSize,
+		preimages:            make(map[common.Hash][]byte),
+		accessList:           newAccessList(),
+		transientStorage:     newTransientStorage(),
+		journal:              newJournal(),
+		parent:               s,
+	}
+}
+
+// destructed reports whether the account was destructed in this block.
+func (s *StateDB) destructed(addr common.Address) bool {
+	if _, ok := s.stateObjectsDestruct[addr]; ok {
+		return true
+	}
+	if s.parent != nil {
+		_, ok := s.parent.stateObjectsDestruct[addr]
+		return ok
+	}
+	return false
+}
+
 // Snapshot returns an identifier for the current revision of the state.
 func (s *StateDB) Snapshot() int {
 	return s.journal.snapshot()
@@ -709,6 +777,9 @@ func (s *StateDB) Snapshot() int {
 
 // RevertToSnapshot reverts all state changes made since the given revision.
 func (s *StateDB) RevertToSnapshot(revid int) {
+	if s.tracker != nil {
+		s.tracker.revert()
+	}
 	s.journal.revertToSnapshot(revid, s)
 }
 
@@ -721,6 +792,14 @@ func (s *StateDB) GetRefund() uint64 {
 // the journal as well as the refunds. Finalise, however, will not push any updates
 // into the tries just yet. Only IntermediateRoot or Commit will do that.
 func (s *StateDB) Finalise(deleteEmptyObjects bool) {
+	// Collect the writes of the transaction if the tracking is enabled. The
+	// reads made by the finalisation itself are not tracked.
+	tracker := s.tracker
+	if tracker != nil {
+		tracker.collectWrites(s.journal)
+		s.tracker = nil
+		defer func() { s.tracker = tracker }()
+	}
 	addressesToPrefetch := make([][]byte, 0, len(s.journal.dirties))
 	for addr := range s.journal.dirties {
 		obj, exist := s.stateObjects[addr]
@@ -736,6 +815,9 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 		if obj.selfDestructed || (deleteEmptyObjects && obj.empty()) {
 			delete(s.stateObjects, obj.address)
 			s.markDelete(addr)
+			if tracker != nil {
+				tracker.resets[addr] = struct{}{}
+			}
 
 			// If ether was sent to account post-selfdestruct it is burnt.
 			if bal := obj.Balance(); s.logger != nil && s.logger.OnBalanceChange != nil && obj.selfDestructed && bal.Sign() != 0 {
@@ -229,6 +229,63 @@ func TestCopy(t *testing.T) {
 
 // TestCopyWithDirtyJournal tests if Copy can correct create a equal copied
 // stateDB with dirty journal present.
+// TestLazyCopy tests that the lazy copies see the uncommitted changes of the
+// original state, including the destructions, and are modified independently.
+func TestLazyCopy(t *testing.T) {
+	var (
+		db   = NewDatabaseForTesting()
+		a    = common.Address{0xa}
+		b    = common.Address{0xb}
+		c    = common.Address{0xc}
+		slot = common.Hash{0x1}
+	)
+	orig, _ := New(types.EmptyRootHash, db)
+	for _, addr := range []common.Address{a, b, c} {
+		orig.SetBalance(addr, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+		orig.SetState(addr, slot, common.Hash{0x1})
+	}
+	root, _ := orig.Commit(0, true)
+	orig, _ = New(root, db)
+
+	// Modify the state in the block without committing
+	orig.SetState(a, slot, common.Hash{0x2})
+	orig.SelfDestruct(c)
+	orig.Finalise(true)
+
+	lazy := orig.LazyCopy()
+	if have := lazy.GetState(a, slot); have != (common.Hash{0x2}) {
+		t.Fatalf("uncommitted slot mismatch: have %x, want %x", have, common.Hash{0x2})
+	}
+	if have := lazy.GetBalance(b); have.Cmp(uint256.NewInt(1)) != 0 {
+		t.Fatalf("balance mismatch: have %v, want 1", have)
+	}
+	if lazy.Exist(c) {
+		t.Fatal("destructed account exists in the lazy copy")
+	}
+	// The storage of the destructed account is not read after resurrection
+	lazy.AddBalance(c, uint256.NewInt(5), tracing.BalanceChangeUnspecified)
+	if have := lazy.GetState(c, slot); have != (common.Hash{}) {
+		t.Fatalf("destructed slot mismatch: have %x, want empty", have)
+	}
+	// Changes made to the copy are not visible in the original state
+	lazy.SetState(a, slot, common.Hash{0x3})
+	lazy.AddBalance(b, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+	lazy.Finalise(true)
+
+	if have := orig.GetState(a, slot); have != (common.Hash{0x2}) {
+		t.Fatalf("original slot modified: have %x, want %x", have, common.Hash{0x2})
+	}
+	if have := orig.GetBalance(b); have.Cmp(uint256.NewInt(1)) != 0 {
+		t.Fatalf("original balance modified: have %v, want 1", have)
+	}
+	if orig.Exist(c) {
+		t.Fatal("original destructed account resurrected")
+	}
+	if have := lazy.GetState(a, slot); have != (common.Hash{0x3}) {
+		t.Fatalf("copy slot mismatch: have %x, want %x", have, common.Hash{0x3})
+	}
+}
+
 func TestCopyWithDirtyJournal(t *testing.T) {
 	db := NewDatabaseForTesting()
 	orig, _ := New(types.EmptyRootHash, db)
@@ -62,9 +62,6 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		blockNumber = block.Number()
 		allLogs     []*types.Log
 		gp          = new(GasPool).AddGas(block.GasLimit())
-
-		// gas reward to validators and delegators
-		gasReward = new(big.Int)
 	)
 
 	// Mutate the block and state according to any hard-fork specs
@@ -76,6 +73,10 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		signer  = types.MakeSigner(p.config, header.Number, header.Time)
 	)
 
+	if err := ValidateGoatCLRoot(p.config, header); err != nil {
+		return nil, err
+	}
+
 	// Apply pre-execution system calls.
 	context = NewEVMBlockContext(header, p.chain, nil)
 	vmenv := vm.NewEVM(context, vm.TxContext{}, statedb, p.config, cfg)
@@ -87,41 +88,40 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	}
 
 	// Iterate over and process the individual transactions
-	for i, tx := range block.Transactions() {
-		msg, err := TransactionToMessage(tx, signer, header.BaseFee)
-		if err != nil {
-			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+	if p.parallelizable(block, statedb, cfg) {
+		var err error
+		if receipts, err = p.applyTransactionsParallel(block, statedb, cfg, gp, usedGas); err != nil {
+			return nil, err
 		}
-		statedb.SetTxContext(tx.Hash(), i)
+	} else {
+		for i, tx := range block.Transactions() {
+			msg, err := TransactionToMessage(tx, signer, header.BaseFee)
+			if err != nil {
+				return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			statedb.SetTxContext(tx.Hash(), i)
 
-		receipt, err := ApplyTransactionWithEVM(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
-		if err != nil {
-			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			receipt, err := ApplyTransactionWithEVM(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+			if err != nil {
+				return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			receipts = append(receipts, receipt)
 		}
-		receipts = append(receipts, receipt)
+	}
+	for _, receipt := range receipts {
 		allLogs = append(allLogs, receipt.Logs...)
-
-		if receipt.GasUsed > 0 { // non-goatTx case
-			tipFee := new(big.Int).SetUint64(receipt.GasUsed)
-			tipFee.Mul(tipFee, tx.EffectiveGasTipValue(context.BaseFee))
-			gasReward.Add(gasReward, tipFee)
-		}
 	}
 
 	// Read requests if Prague is enabled.
 	var requests [][]byte
 
 	if p.config.Goat != nil {
-		burntFees := new(big.Int)
-		if context.BaseFee != nil && header.GasUsed > 0 {
-			burntFees.Mul(context.BaseFee, new(big.Int).SetUint64(header.GasUsed))
-		}
-		if gasUsed := header.BlobGasUsed; context.BlobBaseFee != nil && gasUsed != nil && *gasUsed > 0 {
-			blobUsed := new(big.Int).SetUint64(*gasUsed)
-			burntFees.Add(burntFees, blobUsed.Mul(blobUsed, context.BlobBaseFee))
+		// gas reward to validators and delegators
+		gasUsed := make([]uint64, len(receipts))
+		for i, receipt := range receipts {
+			gasUsed[i] = receipt.GasUsed
 		}
-		gasReward.Add(gasReward, burntFees)
-		reward := ProcessGoatGasFee(statedb, gasReward)
+		reward := ProcessGoatGasFee(statedb, goatGasFees(header, block.Transactions(), gasUsed))
 		goatRequests, err := ProcessGoatRequests(block.NumberU64(), reward, allLogs)
 		if err != nil {
 			return nil, err
@@ -239,7 +239,11 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 // ProcessBeaconBlockRoot applies the EIP-4788 system call to the beacon block root
 // contract. This method is exported to be used in tests.
 func ProcessBeaconBlockRoot(beaconRoot common.Hash, vmenv *vm.EVM, statedb *state.StateDB) {
-	if vmenv.ChainConfig().Goat != nil { // reenable it when we have a correct beacon root
+	if vmenv.ChainConfig().Goat != nil {
+		// goat chains store the CL block root instead of the beacon root
+		if vmenv.ChainConfig().IsGoatCLRoot(vmenv.Context.Time) {
+			ProcessGoatCLRoot(beaconRoot, vmenv, statedb)
+		}
 		return
 	}
 
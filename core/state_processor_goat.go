@@ -1,29 +1,81 @@
 package core
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/types/goattypes"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
 )
 
 var (
-	gfBasePoint    = big.NewInt(200)
-	gfMaxBasePoint = big.NewInt(1e4)
+	errMissingGoatCLRoot = errors.New("missing goat CL block root")
+	errEmptyGoatCLRoot   = errors.New("empty goat CL block root")
 )
 
-func ProcessGoatGasFee(statedb *state.StateDB, gasFees *big.Int) *big.Int {
-	if gasFees.BitLen() == 0 {
-		return new(big.Int)
+// ValidateGoatCLRoot checks the CL block root is provided by the header after
+// the CL root fork, the root is carried by the parent beacon root field.
+func ValidateGoatCLRoot(config *params.ChainConfig, header *types.Header) error {
+	if !config.IsGoatCLRoot(header.Time) {
+		return nil
+	}
+	if header.ParentBeaconRoot == nil {
+		return errMissingGoatCLRoot
+	}
+	if *header.ParentBeaconRoot == (common.Hash{}) {
+		return errEmptyGoatCLRoot
 	}
+	return nil
+}
 
-	// foundation tax 2%
-	tax := new(big.Int).Mul(gasFees, gfBasePoint)
-	tax.Div(tax, gfMaxBasePoint)
+// ProcessGoatCLRoot stores the CL block root in the CL roots contract, it's the
+// same as the EIP-4788 system call except the contract is deployed on the fly
+// if it doesn't exist.
+func ProcessGoatCLRoot(root common.Hash, vmenv *vm.EVM, statedb *state.StateDB) {
+	if tracer := vmenv.Config.Tracer; tracer != nil {
+		if tracer.OnSystemCallStart != nil {
+			tracer.OnSystemCallStart()
+		}
+		if tracer.OnSystemCallEnd != nil {
+			defer tracer.OnSystemCallEnd()
+		}
+	}
+
+	if statedb.GetCodeSize(goattypes.CLRootsContract) == 0 {
+		statedb.SetNonce(goattypes.CLRootsContract, 1)
+		statedb.SetCode(goattypes.CLRootsContract, params.BeaconRootsCode)
+	}
+
+	msg := &Message{
+		From:      params.SystemAddress,
+		GasLimit:  30_000_000,
+		GasPrice:  common.Big0,
+		GasFeeCap: common.Big0,
+		GasTipCap: common.Big0,
+		To:        &goattypes.CLRootsContract,
+		Data:      root[:],
+	}
+	vmenv.Reset(NewEVMTxContext(msg), statedb)
+	statedb.AddAddressToAccessList(goattypes.CLRootsContract)
+	_, _, _ = vmenv.Call(vm.AccountRef(msg.From), *msg.To, msg.Data, 30_000_000, common.U2560)
+	statedb.Finalise(true)
+}
+
+var (
+	gfBasePoint    = big.NewInt(200)
+	gfMaxBasePoint = big.NewInt(1e4)
+)
 
+func ProcessGoatGasFee(statedb *state.StateDB, gasFees *big.Int) *big.Int {
+	tax, gas := splitGoatGasFee(gasFees)
 	if tax.BitLen() != 0 {
 		f, _ := uint256.FromBig(tax)
 		statedb.AddBalance(goattypes.GoatFoundationContract, f, tracing.BalanceIncreaseRewardTransactionFee)
@@ -31,7 +83,6 @@ func ProcessGoatGasFee(statedb *state.StateDB, gasFees *big.Int) *big.Int {
 
 	// add gas revenue to locking contract
 	// if the validator withdraws the gas reward, we will subtract it from locking contract then
-	gas := new(big.Int).Sub(gasFees, tax)
 	if gas.BitLen() != 0 {
 		f, _ := uint256.FromBig(gas)
 		statedb.AddBalance(goattypes.LockingContract, f, tracing.BalanceIncreaseRewardTransactionFee)
@@ -39,6 +90,72 @@ func ProcessGoatGasFee(statedb *state.StateDB, gasFees *big.Int) *big.Int {
 	return gas
 }
 
+// splitGoatGasFee splits the gas fees into the foundation tax and the gas
+// revenue of the locking contract.
+func splitGoatGasFee(gasFees *big.Int) (*big.Int, *big.Int) {
+	if gasFees.BitLen() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	// foundation tax 2%
+	tax := new(big.Int).Mul(gasFees, gfBasePoint)
+	tax.Div(tax, gfMaxBasePoint)
+	return tax, new(big.Int).Sub(gasFees, tax)
+}
+
+// goatGasFees sums the gas fees of a goat block: the tips of the transactions
+// given the gas used by each of them, plus the burnt base and blob fees.
+func goatGasFees(header *types.Header, txs types.Transactions, gasUsed []uint64) *big.Int {
+	fees := new(big.Int)
+	for i, used := range gasUsed {
+		if used > 0 { // non-goatTx case
+			tip := new(big.Int).SetUint64(used)
+			fees.Add(fees, tip.Mul(tip, txs[i].EffectiveGasTipValue(header.BaseFee)))
+		}
+	}
+	if header.BaseFee != nil && header.GasUsed > 0 {
+		burnt := new(big.Int).SetUint64(header.GasUsed)
+		fees.Add(fees, burnt.Mul(burnt, header.BaseFee))
+	}
+	if header.ExcessBlobGas != nil && header.BlobGasUsed != nil && *header.BlobGasUsed > 0 {
+		burnt := new(big.Int).SetUint64(*header.BlobGasUsed)
+		fees.Add(fees, burnt.Mul(burnt, eip4844.CalcBlobFee(*header.ExcessBlobGas)))
+	}
+	return fees
+}
+
+// DeriveGoatRequests rebuilds the goat requests of a block from its receipts,
+// it's used for the blocks which are not executed locally(e.g. snap sync). The
+// requests are checked against the requests hash of the header.
+func DeriveGoatRequests(block *types.Block, receipts types.Receipts) ([][]byte, error) {
+	var (
+		header  = block.Header()
+		txs     = block.Transactions()
+		gasUsed = make([]uint64, len(receipts))
+		logs    []*types.Log
+	)
+	if len(txs) != len(receipts) {
+		return nil, fmt.Errorf("receipt count mismatch: have %d, want %d", len(receipts), len(txs))
+	}
+	var cumulative uint64
+	for i, receipt := range receipts {
+		// The downloaded receipts carry the consensus fields only
+		gasUsed[i] = receipt.CumulativeGasUsed - cumulative
+		cumulative = receipt.CumulativeGasUsed
+		logs = append(logs, receipt.Logs...)
+	}
+	_, gas := splitGoatGasFee(goatGasFees(header, txs, gasUsed))
+	requests, err := ProcessGoatRequests(block.NumberU64(), gas, logs)
+	if err != nil {
+		return nil, err
+	}
+	if header.RequestsHash != nil {
+		if hash := types.CalcRequestsHash(requests); hash != *header.RequestsHash {
+			return nil, fmt.Errorf("goat requests hash mismatch: have %x, want %x", hash, *header.RequestsHash)
+		}
+	}
+	return requests, nil
+}
+
 // ProcessGoatRequests processes goat requests
 // It's not same with the eip-7685, the order is by it's emitted in the block
 // and every request has its type prefix
@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"errors"
 	"math/big"
 	"reflect"
 	"testing"
@@ -293,3 +294,63 @@ func TestProcessGoatGasFee(t *testing.T) {
 		t.Errorf("RequestsHash expected %x got %x", requestsHash, *gotRequestshash)
 	}
 }
+
+func TestProcessGoatCLRoot(t *testing.T) {
+	var (
+		engine = beacon.NewFaker()
+		config = *params.AllGoatDebugChainConfig
+		gspec  = &Genesis{Config: &config}
+	)
+	// The generated blocks are 10s apart, the fork happens at block 2
+	config.Goat = &params.GoatConfig{CLRootTime: u64(20)}
+
+	roots := []common.Hash{{}, {0x01}, {0x02}, {0x03}}
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 4, func(i int, b *BlockGen) {
+		if i > 0 {
+			b.SetParentBeaconRoot(roots[i])
+		}
+	})
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+
+	// The roots contract isn't deployed before the fork
+	state, _ := chain.StateAt(blocks[0].Root())
+	if state.GetCodeSize(goattypes.CLRootsContract) != 0 {
+		t.Fatalf("CL roots contract deployed before the fork")
+	}
+	state, _ = chain.State()
+	if code := state.GetCode(goattypes.CLRootsContract); !bytes.Equal(code, params.BeaconRootsCode) {
+		t.Fatalf("CL roots contract code mismatch: have %x", code)
+	}
+	for i, block := range blocks[1:] {
+		var (
+			slot     = block.Time() % 8191
+			timeSlot = common.BigToHash(new(big.Int).SetUint64(slot))
+			rootSlot = common.BigToHash(new(big.Int).SetUint64(slot + 8191))
+		)
+		if have := state.GetState(goattypes.CLRootsContract, timeSlot); have.Big().Uint64() != block.Time() {
+			t.Errorf("block %d: timestamp mismatch: have %d want %d", block.NumberU64(), have.Big(), block.Time())
+		}
+		if have := state.GetState(goattypes.CLRootsContract, rootSlot); have != roots[i+1] {
+			t.Errorf("block %d: CL root mismatch: have %x want %x", block.NumberU64(), have, roots[i+1])
+		}
+	}
+
+	// The blocks after the fork must provide a CL root
+	_, blocks, _ = GenerateChainWithGenesis(gspec, engine, 2, func(i int, b *BlockGen) {})
+	chain, err = NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); !errors.Is(err, errEmptyGoatCLRoot) || n != 1 {
+		t.Fatalf("block %d: unexpected error: %v", n, err)
+	}
+}
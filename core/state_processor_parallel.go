@@ -0,0 +1,180 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	parallelTxMeter       = metrics.NewRegisteredMeter("chain/parallel/txs", nil)
+	parallelConflictMeter = metrics.NewRegisteredMeter("chain/parallel/conflicts", nil)
+	parallelFallbackMeter = metrics.NewRegisteredMeter("chain/parallel/fallbacks", nil)
+)
+
+// parallelWorkers is the number of transactions executed concurrently in each
+// round of the parallel execution.
+var parallelWorkers = runtime.NumCPU()
+
+// speculation is the outcome of a transaction executed on a private copy of
+// the state, in parallel with the other transactions of the block.
+type speculation struct {
+	state  *state.StateDB
+	evm    *vm.EVM
+	result *ExecutionResult
+	set    *state.AccessSet
+	gas    uint64 // The gas consumed from the block gas pool
+	err    error
+}
+
+// parallelizable reports whether the transactions of the block can be executed
+// in parallel. The parallel execution is only supported post-byzantium as the
+// intermediate roots are required in receipts before, and is disabled if the
+// execution is traced or witnessed as the accessed state must be observed in
+// order.
+func (p *StateProcessor) parallelizable(block *types.Block, statedb *state.StateDB, cfg vm.Config) bool {
+	return cfg.ParallelExecution && cfg.Tracer == nil && len(block.Transactions()) > 1 &&
+		p.config.IsByzantium(block.Number()) && statedb.Witness() == nil && !statedb.GetTrie().IsVerkle()
+}
+
+// applyTransactionsParallel applies the transactions of the block on top of the
+// given state with optimistic concurrency control, producing the exact same
+// state and receipts as the sequential execution.
+//
+// The transactions are processed in rounds. In each round, a window of them is
+// executed concurrently, each on a lazy copy of the state. The speculative results
+// are then committed in order: a transaction is accepted if none of the state
+// it accessed has been modified by the preceding transactions committed in the
+// same round, otherwise it's re-executed on the live state. If too many of the
+// transactions conflict, the remaining ones are executed sequentially.
+func (p *StateProcessor) applyTransactionsParallel(block *types.Block, statedb *state.StateDB, cfg vm.Config, gp *GasPool, usedGas *uint64) (types.Receipts, error) {
+	var (
+		header      = block.Header()
+		blockHash   = block.Hash()
+		blockNumber = block.Number()
+		txs         = block.Transactions()
+		signer      = types.MakeSigner(p.config, header.Number, header.Time)
+		msgs        = make([]*Message, len(txs))
+		receipts    = make(types.Receipts, 0, len(txs))
+		workers     = max(parallelWorkers, 2)
+		parallel    = true
+	)
+	for i, tx := range txs {
+		msg, err := TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		msgs[i] = msg
+	}
+	// The evm for executing the transactions on the live state
+	vmenv := vm.NewEVM(NewEVMBlockContext(header, p.chain, nil), vm.TxContext{}, statedb, p.config, cfg)
+	coinbase := vmenv.Context.Coinbase
+
+	for start := 0; start < len(txs); start += workers {
+		end := min(start+workers, len(txs))
+
+		// Fall back to the sequential execution if the transactions are
+		// largely dependent on each other.
+		if !parallel {
+			for i := start; i < end; i++ {
+				statedb.SetTxContext(txs[i].Hash(), i)
+				receipt, err := ApplyTransactionWithEVM(msgs[i], p.config, gp, statedb, blockNumber, blockHash, txs[i], usedGas, vmenv)
+				if err != nil {
+					return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, txs[i].Hash().Hex(), err)
+				}
+				receipts = append(receipts, receipt)
+			}
+			continue
+		}
+		var (
+			wg    sync.WaitGroup
+			specs = make([]*speculation, end-start)
+		)
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				spec := &speculation{state: statedb.LazyCopy()}
+				spec.state.SetTxContext(txs[i].Hash(), i)
+				spec.state.StartAccessTracking(&coinbase)
+
+				pool := new(GasPool).AddGas(gp.Gas())
+				spec.evm = vm.NewEVM(NewEVMBlockContext(header, p.chain, nil), NewEVMTxContext(msgs[i]), spec.state, p.config, cfg)
+				spec.result, spec.err = ApplyMessage(spec.evm, msgs[i], pool)
+				spec.state.Finalise(true)
+				spec.set = spec.state.StopAccessTracking()
+				spec.gas = gp.Gas() - pool.Gas()
+				if spec.err == nil {
+					spec.err = spec.state.Error()
+				}
+				specs[i-start] = spec
+			}(i)
+		}
+		wg.Wait()
+
+		var (
+			conflicts int
+			written   = state.NewAccessSet(nil)
+		)
+		for i := start; i < end; i++ {
+			var (
+				tx   = txs[i]
+				msg  = msgs[i]
+				spec = specs[i-start]
+			)
+			statedb.SetTxContext(tx.Hash(), i)
+
+			if spec.err == nil && !spec.set.Conflicts(written) && (msg.IsGoatTx || gp.Gas() >= msg.GasLimit) {
+				statedb.MergeTransaction(spec.state, spec.set)
+				written.AddWrites(spec.set)
+
+				gp.SetGas(gp.Gas() - spec.gas)
+				*usedGas += spec.result.UsedGas
+				receipts = append(receipts, MakeReceipt(spec.evm, spec.result, statedb, blockNumber, blockHash, tx, *usedGas, nil))
+				continue
+			}
+			// The speculation is invalidated, execute the transaction on the
+			// live state and record its writes for validating the following
+			// ones.
+			conflicts++
+			statedb.StartAccessTracking(nil)
+			receipt, err := ApplyTransactionWithEVM(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+			set := statedb.StopAccessTracking()
+			if err != nil {
+				return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			written.AddWrites(set)
+			receipts = append(receipts, receipt)
+		}
+		parallelTxMeter.Mark(int64(end - start))
+		parallelConflictMeter.Mark(int64(conflicts))
+
+		if conflicts*2 > end-start {
+			parallel = false
+			parallelFallbackMeter.Mark(1)
+		}
+	}
+	return receipts, nil
+}
@@ -0,0 +1,237 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the parallel execution produces the same state and receipts as
+// the sequential one, with both independent and conflicting transactions.
+func TestParallelExecution(t *testing.T) {
+	defer func(n int) { parallelWorkers = n }(parallelWorkers)
+	parallelWorkers = 4
+
+	var (
+		counter  = common.HexToAddress("0xc0")
+		coinbase = common.HexToAddress("0xcb")
+		keys     []*ecdsa.PrivateKey
+		funds    = big.NewInt(params.Ether)
+		alloc    = types.GenesisAlloc{
+			// The counter increments the slot specified by the calldata and
+			// emits an empty log.
+			counter: {
+				Code: []byte{
+					byte(vm.PUSH1), 0,
+					byte(vm.CALLDATALOAD),
+					byte(vm.DUP1),
+					byte(vm.SLOAD),
+					byte(vm.PUSH1), 1,
+					byte(vm.ADD),
+					byte(vm.SWAP1),
+					byte(vm.SSTORE),
+					byte(vm.PUSH1), 0,
+					byte(vm.PUSH1), 0,
+					byte(vm.LOG0),
+					byte(vm.STOP),
+				},
+				Balance: common.Big0,
+			},
+		}
+	)
+	for i := 0; i < 8; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = types.Account{Balance: funds}
+	}
+	gspec := &Genesis{Config: params.AllEthashProtocolChanges, Alloc: alloc}
+	signer := types.LatestSigner(gspec.Config)
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 8, func(n int, b *BlockGen) {
+		b.SetCoinbase(coinbase)
+		gasPrice := new(big.Int).Mul(b.BaseFee(), common.Big2)
+
+		send := func(key *ecdsa.PrivateKey, to *common.Address, value *big.Int, data []byte) {
+			tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+				Nonce:    b.TxNonce(crypto.PubkeyToAddress(key.PublicKey)),
+				To:       to,
+				Value:    value,
+				Gas:      200000,
+				GasPrice: gasPrice,
+				Data:     data,
+			})
+			if err != nil {
+				t.Fatalf("failed to sign tx: %v", err)
+			}
+			b.AddTx(tx)
+		}
+		for i, key := range keys {
+			var (
+				to    common.Address
+				data  []byte
+				value = big.NewInt(1000)
+			)
+			switch (n + i) % 4 {
+			case 0: // transfer to a fresh account
+				to = common.BigToAddress(big.NewInt(int64(0x1000 + n*len(keys) + i)))
+			case 1: // transfer to another sender
+				to = crypto.PubkeyToAddress(keys[(i+1)%len(keys)].PublicKey)
+			case 2: // bump one of the shared counters
+				to, value = counter, common.Big0
+				data = common.LeftPadBytes([]byte{byte(i % 2)}, 32)
+			case 3: // pay the coinbase directly
+				to = coinbase
+			}
+			send(key, &to, value, data)
+		}
+		// Consecutive transactions from the same sender, and a contract
+		// deployment leaving storage behind.
+		send(keys[0], &counter, common.Big0, common.LeftPadBytes([]byte{byte(n)}, 32))
+		send(keys[1], nil, common.Big0, alloc[counter].Code)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{ParallelExecution: true}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	for _, block := range blocks {
+		parent := chain.GetHeaderByHash(block.ParentHash())
+
+		serial, _ := chain.StateAt(parent.Root)
+		want, err := chain.Processor().Process(block, serial, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: sequential execution failed: %v", block.NumberU64(), err)
+		}
+		parallel, _ := chain.StateAt(parent.Root)
+		have, err := chain.Processor().Process(block, parallel, vm.Config{ParallelExecution: true})
+		if err != nil {
+			t.Fatalf("block %d: parallel execution failed: %v", block.NumberU64(), err)
+		}
+		if want, have := serial.IntermediateRoot(true), parallel.IntermediateRoot(true); want != have {
+			t.Fatalf("block %d: state root mismatch, want %x, have %x", block.NumberU64(), want, have)
+		}
+		if want.GasUsed != have.GasUsed {
+			t.Fatalf("block %d: gas used mismatch, want %d, have %d", block.NumberU64(), want.GasUsed, have.GasUsed)
+		}
+		wantBlob, _ := json.Marshal(want.Receipts)
+		haveBlob, _ := json.Marshal(have.Receipts)
+		if string(wantBlob) != string(haveBlob) {
+			t.Fatalf("block %d: receipts mismatch\nwant %s\nhave %s", block.NumberU64(), wantBlob, haveBlob)
+		}
+	}
+}
+
+// BenchmarkParallelExecution compares the sequential and the parallel execution
+// of blocks of independent transactions, each hashing in a loop for the rounds
+// given in the calldata and storing the result in its own slot.
+func BenchmarkParallelExecution(b *testing.B) {
+	var (
+		hasher = common.HexToAddress("0xc0")
+		keys   []*ecdsa.PrivateKey
+		funds  = big.NewInt(params.Ether)
+		alloc  = types.GenesisAlloc{
+			hasher: {
+				Code: []byte{
+					byte(vm.PUSH1), 0,
+					byte(vm.CALLDATALOAD),
+					byte(vm.JUMPDEST),
+					byte(vm.PUSH1), 0x20,
+					byte(vm.PUSH1), 0,
+					byte(vm.KECCAK256),
+					byte(vm.PUSH1), 0,
+					byte(vm.MSTORE),
+					byte(vm.PUSH1), 1,
+					byte(vm.SWAP1),
+					byte(vm.SUB),
+					byte(vm.DUP1),
+					byte(vm.PUSH1), 3,
+					byte(vm.JUMPI),
+					byte(vm.POP),
+					byte(vm.PUSH1), 0,
+					byte(vm.MLOAD),
+					byte(vm.CALLER),
+					byte(vm.SSTORE),
+					byte(vm.STOP),
+				},
+				Balance: common.Big0,
+			},
+		}
+	)
+	for i := 0; i < 128; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = types.Account{Balance: funds}
+	}
+	gspec := &Genesis{Config: params.AllEthashProtocolChanges, Alloc: alloc, GasLimit: 30_000_000}
+	signer := types.LatestSigner(gspec.Config)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		b.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	for _, rounds := range []int64{1, 2000} {
+		_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, func(n int, gen *BlockGen) {
+			for _, key := range keys {
+				tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+					Nonce:    gen.TxNonce(crypto.PubkeyToAddress(key.PublicKey)),
+					To:       &hasher,
+					Gas:      200000,
+					GasPrice: gen.BaseFee(),
+					Data:     common.LeftPadBytes(big.NewInt(rounds).Bytes(), 32),
+				})
+				if err != nil {
+					b.Fatalf("failed to sign tx: %v", err)
+				}
+				gen.AddTx(tx)
+			}
+		})
+		block := blocks[0]
+		parent := chain.GetHeaderByHash(block.ParentHash())
+
+		for _, parallel := range []bool{false, true} {
+			name := fmt.Sprintf("rounds=%d/sequential", rounds)
+			if parallel {
+				name = fmt.Sprintf("rounds=%d/parallel", rounds)
+			}
+			b.Run(name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					statedb, _ := chain.StateAt(parent.Root)
+					if _, err := chain.Processor().Process(block, statedb, vm.Config{ParallelExecution: parallel}); err != nil {
+						b.Fatalf("execution failed: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
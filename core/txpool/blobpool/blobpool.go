@@ -27,6 +27,7 @@ import (
 	"path/filepath"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -302,6 +303,9 @@ type BlobPool struct {
 	signer types.Signer // Transaction signer to use for sender recovery
 	chain  BlockChain   // Chain object to access the state through
 
+	history *txpool.TxHistory                // Lifecycle history to record the transactions in
+	policy  atomic.Pointer[txpool.Admission] // Admission policy of the new transactions, nil if disabled
+
 	head   *types.Header  // Current head of the chain
 	state  *state.StateDB // Current state at the head of the chain
 	gasTip *uint256.Int   // Currently accepted minimum gas tip
@@ -339,6 +343,18 @@ func (p *BlobPool) Filter(tx *types.Transaction) bool {
 	return tx.Type() == types.BlobTxType
 }
 
+// SetHistory sets the lifecycle history the pool records its transactions in.
+func (p *BlobPool) SetHistory(history *txpool.TxHistory) {
+	p.history = history
+}
+
+// SetPolicy replaces the admission policy of the new transactions, nil disables
+// it. The transactions already in the pool are not affected.
+func (p *BlobPool) SetPolicy(admission *txpool.Admission) {
+	p.policy.Store(admission)
+	log.Info("Blob pool admission policy updated", "enabled", admission != nil)
+}
+
 // Init sets the gas price needed to keep a transaction in the pool and the chain
 // head to allow balance / nonce checks. The transaction journal will be loaded
 // from disk and filtered based on the provided starting settings.
@@ -525,13 +541,18 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 		var (
 			ids    []uint64
 			nonces []uint64
+			reason = "nonce too low"
 		)
+		if gapped {
+			reason = "nonce gap"
+		}
 		for i := 0; i < len(txs); i++ {
 			ids = append(ids, txs[i].id)
 			nonces = append(nonces, txs[i].nonce)
 
 			p.stored -= uint64(txs[i].size)
 			delete(p.lookup, txs[i].hash)
+			p.history.Evicted(txs[i].hash, reason)
 
 			// Included transactions blobs need to be moved to the limbo
 			if filled && inclusions != nil {
@@ -573,6 +594,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], txs[0].costCap)
 			p.stored -= uint64(txs[0].size)
 			delete(p.lookup, txs[0].hash)
+			p.history.Evicted(txs[0].hash, "nonce too low")
 
 			// Included transactions blobs need to be moved to the limbo
 			if inclusions != nil {
@@ -629,6 +651,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], txs[i].costCap)
 			p.stored -= uint64(txs[i].size)
 			delete(p.lookup, txs[i].hash)
+			p.history.Evicted(txs[i].hash, "repeated nonce")
 
 			if err := p.store.Delete(id); err != nil {
 				log.Error("Failed to delete blob transaction", "from", addr, "id", id, "err", err)
@@ -651,6 +674,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], txs[j].costCap)
 			p.stored -= uint64(txs[j].size)
 			delete(p.lookup, txs[j].hash)
+			p.history.Evicted(txs[j].hash, "nonce gap")
 		}
 		txs = txs[:i]
 
@@ -689,6 +713,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], last.costCap)
 			p.stored -= uint64(last.size)
 			delete(p.lookup, last.hash)
+			p.history.Evicted(last.hash, "insufficient funds")
 		}
 		if len(txs) == 0 {
 			delete(p.index, addr)
@@ -729,6 +754,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], last.costCap)
 			p.stored -= uint64(last.size)
 			delete(p.lookup, last.hash)
+			p.history.Evicted(last.hash, "account limit exceeded")
 		}
 		p.index[addr] = txs
 
@@ -1034,6 +1060,7 @@ func (p *BlobPool) SetGasTip(tip *big.Int) {
 					p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], txs[i].costCap)
 					p.stored -= uint64(tx.size)
 					delete(p.lookup, tx.hash)
+					p.history.Evicted(tx.hash, "below minimum tip")
 					txs[i] = nil
 
 					// Drop everything afterwards, no gaps allowed
@@ -1044,6 +1071,7 @@ func (p *BlobPool) SetGasTip(tip *big.Int) {
 						p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], tx.costCap)
 						p.stored -= uint64(tx.size)
 						delete(p.lookup, tx.hash)
+						p.history.Evicted(tx.hash, "below minimum tip")
 						txs[i+1+j] = nil
 					}
 					// Clear out the dropped transactions from the index
@@ -1262,9 +1290,18 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 		}
 		return err
 	}
+	// Enforce the admission policy on top of the validity rules
+	from, _ := types.Sender(p.signer, tx) // already validated above
+	admission := p.policy.Load()
+	if admission != nil {
+		if err := admission.Check(tx, from); err != nil {
+			log.Trace("Transaction rejected by policy", "hash", tx.Hash(), "from", from, "err", err)
+			addPolicyMeter.Mark(1)
+			return err
+		}
+	}
 	// If the address is not yet known, request exclusivity to track the account
 	// only by this subpool until all transactions are evicted
-	from, _ := types.Sender(p.signer, tx) // already validated above
 	if _, ok := p.index[from]; !ok {
 		if err := p.reserve(from, true); err != nil {
 			addNonExclusiveMeter.Mark(1)
@@ -1322,6 +1359,7 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 		delete(p.lookup, prev.hash)
 		p.lookup[meta.hash] = meta.id
 		p.stored += uint64(meta.size) - uint64(prev.size)
+		p.history.Replaced(prev.hash, meta.hash)
 	} else {
 		// Transaction extends previously scheduled ones
 		p.index[from] = append(p.index[from], meta)
@@ -1380,6 +1418,14 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 			heap.Fix(p.evict, p.evict.index[from])
 		}
 	}
+	p.history.Validated(meta.hash)
+
+	// Only the transactions accepted by the pool count against the rate limit
+	// of their sender
+	if admission != nil {
+		admission.Admit(from)
+	}
+
 	// If the pool went over the allowed data limit, evict transactions until
 	// we're again below the threshold
 	for p.stored > p.config.Datacap {
@@ -1420,6 +1466,7 @@ func (p *BlobPool) drop() {
 	}
 	p.stored -= uint64(drop.size)
 	delete(p.lookup, drop.hash)
+	p.history.Evicted(drop.hash, "pool capacity exceeded")
 
 	// Remove the transaction from the pool's eviction heap:
 	//   - If the entire account was dropped, pop off the address
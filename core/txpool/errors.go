@@ -60,4 +60,12 @@ var (
 	// input transaction of non-blob type when a blob transaction from this sender
 	// remains pending (and vice-versa).
 	ErrAlreadyReserved = errors.New("address already reserved")
+
+	// ErrPrivateTxUnsupported is returned if a private transaction is submitted,
+	// but none of the subpools accepting its type supports private transactions.
+	ErrPrivateTxUnsupported = errors.New("private transaction type not supported")
+
+	// ErrPrivateTxExpired is returned if a private transaction is loaded after
+	// the block it was supposed to be included by.
+	ErrPrivateTxExpired = errors.New("private transaction expired")
 )
@@ -0,0 +1,231 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// historyTxs is the number of transactions the lifecycle history is kept
+	// for, the least recently updated ones are forgotten first.
+	historyTxs = 16384
+
+	// historyEvents is the maximum number of lifecycle events kept for a single
+	// transaction, the oldest ones are dropped first.
+	historyEvents = 32
+
+	// historyFeedBuffer is the number of lifecycle events waiting to be fed to
+	// the subscribers, the events are dropped for slow subscribers beyond it.
+	historyFeedBuffer = 1024
+)
+
+// TxEventKind is the type of a transaction lifecycle event.
+type TxEventKind string
+
+const (
+	TxEventReceived  TxEventKind = "received"  // Submitted locally or received from the network
+	TxEventRejected  TxEventKind = "rejected"  // Refused by the pool, never tracked
+	TxEventValidated TxEventKind = "validated" // Accepted into the pool
+	TxEventPromoted  TxEventKind = "promoted"  // Moved into the executable set
+	TxEventDemoted   TxEventKind = "demoted"   // Moved back into the non-executable set
+	TxEventReplaced  TxEventKind = "replaced"  // Superseded by another transaction with the same nonce
+	TxEventEvicted   TxEventKind = "evicted"   // Dropped from the pool
+	TxEventIncluded  TxEventKind = "included"  // Included in a block
+)
+
+// TxEvent is a step in the lifecycle of a transaction in the pool.
+type TxEvent struct {
+	Hash       common.Hash // Hash of the transaction
+	Kind       TxEventKind // Type of the event
+	Time       time.Time   // Time the event happened
+	Source     string      // Origin of received transactions, "local" or "remote"
+	Reason     string      // Cause of rejections and evictions
+	ReplacedBy common.Hash // Transaction superseding a replaced one
+	Block      uint64      // Number of the block including the transaction
+}
+
+// TxHistory records the recent lifecycle events of the transactions seen by the
+// pool, bounded both in the number of transactions and of events per each.
+// All the methods are safe to call on a nil history, which records nothing.
+type TxHistory struct {
+	txs    lru.BasicLRU[common.Hash, []TxEvent]
+	hidden lru.BasicLRU[common.Hash, struct{}] // Private transactions kept out of the history
+	lock   sync.Mutex
+
+	feed   event.Feed
+	events chan TxEvent  // Events waiting to be fed to the subscribers
+	quit   chan struct{} // Termination channel to stop the feeder
+	wg     sync.WaitGroup
+}
+
+// NewTxHistory creates the lifecycle history of the pool, which needs to be
+// closed to stop feeding the subscribers.
+func NewTxHistory() *TxHistory {
+	h := &TxHistory{
+		txs:    lru.NewBasicLRU[common.Hash, []TxEvent](historyTxs),
+		hidden: lru.NewBasicLRU[common.Hash, struct{}](historyTxs),
+		events: make(chan TxEvent, historyFeedBuffer),
+		quit:   make(chan struct{}),
+	}
+	h.wg.Add(1)
+	go h.loop()
+	return h
+}
+
+// Close stops feeding the lifecycle events to the subscribers.
+func (h *TxHistory) Close() {
+	if h == nil {
+		return
+	}
+	close(h.quit)
+	h.wg.Wait()
+}
+
+// loop feeds the recorded events to the subscribers, decoupling the pools from
+// the consumers of the events.
+func (h *TxHistory) loop() {
+	defer h.wg.Done()
+
+	for {
+		select {
+		case ev := <-h.events:
+			h.feed.Send(ev)
+		case <-h.quit:
+			return
+		}
+	}
+}
+
+// record appends the event to the history of the transaction and queues it for
+// the subscribers. Events of unknown transactions are only recorded if create
+// is set.
+func (h *TxHistory) record(ev TxEvent, create bool) {
+	if h == nil {
+		return
+	}
+	ev.Time = time.Now()
+
+	h.lock.Lock()
+	if h.hidden.Contains(ev.Hash) {
+		h.lock.Unlock()
+		return
+	}
+	events, ok := h.txs.Get(ev.Hash)
+	if !ok && !create {
+		h.lock.Unlock()
+		return
+	}
+	// Evictions are implied by the inclusion, e.g. the stale nonce cleanup
+	// after a block, don't record them after the fact.
+	if ev.Kind == TxEventEvicted && len(events) > 0 && events[len(events)-1].Kind == TxEventIncluded {
+		h.lock.Unlock()
+		return
+	}
+	if len(events) >= historyEvents {
+		events = slices.Delete(events, 0, len(events)-historyEvents+1)
+	}
+	h.txs.Add(ev.Hash, append(events, ev))
+	h.lock.Unlock()
+
+	select {
+	case h.events <- ev:
+	default:
+		log.Trace("Dropping transaction lifecycle event", "hash", ev.Hash, "kind", ev.Kind)
+	}
+}
+
+// Hide keeps the events of the transaction out of the history and away from the
+// subscribers, e.g. for private transactions which must not be revealed.
+func (h *TxHistory) Hide(hash common.Hash) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.hidden.Add(hash, struct{}{})
+	h.txs.Remove(hash)
+}
+
+// Received records that the transaction arrived from the given source.
+func (h *TxHistory) Received(hash common.Hash, source string) {
+	h.record(TxEvent{Hash: hash, Kind: TxEventReceived, Source: source}, true)
+}
+
+// Rejected records that the transaction was refused for the given reason.
+func (h *TxHistory) Rejected(hash common.Hash, err error) {
+	h.record(TxEvent{Hash: hash, Kind: TxEventRejected, Reason: err.Error()}, true)
+}
+
+// Validated records that the transaction was accepted into the pool.
+func (h *TxHistory) Validated(hash common.Hash) {
+	h.record(TxEvent{Hash: hash, Kind: TxEventValidated}, true)
+}
+
+// Promoted records that the transaction became executable.
+func (h *TxHistory) Promoted(hash common.Hash) {
+	h.record(TxEvent{Hash: hash, Kind: TxEventPromoted}, true)
+}
+
+// Demoted records that the transaction is not executable anymore.
+func (h *TxHistory) Demoted(hash common.Hash) {
+	h.record(TxEvent{Hash: hash, Kind: TxEventDemoted}, true)
+}
+
+// Replaced records that the transaction was superseded by another one.
+func (h *TxHistory) Replaced(hash common.Hash, by common.Hash) {
+	h.record(TxEvent{Hash: hash, Kind: TxEventReplaced, ReplacedBy: by}, true)
+}
+
+// Evicted records that the transaction was dropped for the given reason.
+func (h *TxHistory) Evicted(hash common.Hash, reason string) {
+	h.record(TxEvent{Hash: hash, Kind: TxEventEvicted, Reason: reason}, true)
+}
+
+// Included records the inclusion of the tracked transactions of the block.
+func (h *TxHistory) Included(block *types.Block) {
+	for _, tx := range block.Transactions() {
+		h.record(TxEvent{Hash: tx.Hash(), Kind: TxEventIncluded, Block: block.NumberU64()}, false)
+	}
+}
+
+// Get returns the recorded lifecycle events of the transaction, oldest first.
+func (h *TxHistory) Get(hash common.Hash) []TxEvent {
+	if h == nil {
+		return nil
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	events, _ := h.txs.Peek(hash)
+	return slices.Clone(events)
+}
+
+// Subscribe registers a subscription for the lifecycle events of all the
+// transactions. Events are dropped if the subscribers fall behind.
+func (h *TxHistory) Subscribe(ch chan<- TxEvent) event.Subscription {
+	return h.feed.Subscribe(ch)
+}
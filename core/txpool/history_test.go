@@ -0,0 +1,227 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// historyKinds returns the kinds of the recorded events of the transaction.
+func historyKinds(h *TxHistory, hash common.Hash) []TxEventKind {
+	var kinds []TxEventKind
+	for _, ev := range h.Get(hash) {
+		kinds = append(kinds, ev.Kind)
+	}
+	return kinds
+}
+
+func TestTxHistory(t *testing.T) {
+	h := NewTxHistory()
+	defer h.Close()
+
+	var (
+		tx       = types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		replaced = common.HexToHash("0x01")
+		unknown  = types.NewTransaction(1, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		block    = types.NewBlock(&types.Header{Number: big.NewInt(7)}, &types.Body{Transactions: types.Transactions{tx, unknown}}, nil, trie.NewStackTrie(nil))
+	)
+	h.Received(tx.Hash(), "local")
+	h.Validated(tx.Hash())
+	h.Promoted(tx.Hash())
+	h.Included(block)
+	h.Evicted(tx.Hash(), "nonce too low") // implied by the inclusion, ignored
+
+	h.Received(replaced, "remote")
+	h.Replaced(replaced, tx.Hash())
+
+	want := []TxEventKind{TxEventReceived, TxEventValidated, TxEventPromoted, TxEventIncluded}
+	if have := historyKinds(h, tx.Hash()); !slices.Equal(have, want) {
+		t.Fatalf("history mismatch: have %v, want %v", have, want)
+	}
+	if events := h.Get(tx.Hash()); events[0].Source != "local" || events[3].Block != 7 {
+		t.Fatalf("event details mismatch: source %q, block %d", events[0].Source, events[3].Block)
+	}
+	if events := h.Get(replaced); len(events) != 2 || events[1].ReplacedBy != tx.Hash() {
+		t.Fatalf("replacement is not recorded: %v", events)
+	}
+	// Inclusion of transactions never seen by the pool is not recorded
+	if events := h.Get(unknown.Hash()); len(events) != 0 {
+		t.Fatalf("untracked transaction recorded: %v", events)
+	}
+}
+
+func TestTxHistoryLimits(t *testing.T) {
+	h := NewTxHistory()
+	defer h.Close()
+
+	hash := common.HexToHash("0x01")
+	h.Received(hash, "remote")
+	for i := 0; i < historyEvents; i++ {
+		h.Rejected(hash, errors.New("rejected"))
+	}
+	events := h.Get(hash)
+	if len(events) != historyEvents {
+		t.Fatalf("event count mismatch: have %d, want %d", len(events), historyEvents)
+	}
+	if events[0].Kind != TxEventRejected {
+		t.Fatalf("oldest event is not dropped: %v", events[0].Kind)
+	}
+	for i := 0; i < historyTxs; i++ {
+		h.Received(common.BigToHash(big.NewInt(int64(i+2))), "remote")
+	}
+	if events := h.Get(hash); len(events) != 0 {
+		t.Fatalf("least recently updated transaction is not forgotten")
+	}
+}
+
+func TestTxHistorySubscription(t *testing.T) {
+	h := NewTxHistory()
+	defer h.Close()
+
+	events := make(chan TxEvent, 1)
+	sub := h.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	hash := common.HexToHash("0x01")
+	h.Evicted(hash, "queued lifetime exceeded")
+
+	select {
+	case ev := <-events:
+		if ev.Hash != hash || ev.Kind != TxEventEvicted || ev.Reason != "queued lifetime exceeded" {
+			t.Fatalf("event mismatch: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lifecycle event not delivered")
+	}
+}
+
+func TestTxHistoryHidden(t *testing.T) {
+	h := NewTxHistory()
+	defer h.Close()
+
+	events := make(chan TxEvent, 1)
+	sub := h.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	var (
+		tx    = types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		block = types.NewBlock(&types.Header{Number: big.NewInt(1)}, &types.Body{Transactions: types.Transactions{tx}}, nil, trie.NewStackTrie(nil))
+	)
+	h.Hide(tx.Hash())
+	h.Received(tx.Hash(), "local")
+	h.Validated(tx.Hash())
+	h.Included(block)
+
+	if events := h.Get(tx.Hash()); len(events) != 0 {
+		t.Fatalf("hidden transaction recorded: %v", events)
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("hidden transaction event delivered: %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNilTxHistory(t *testing.T) {
+	var h *TxHistory
+	h.Received(common.Hash{}, "local")
+	h.Close()
+	if events := h.Get(common.Hash{}); events != nil {
+		t.Fatalf("nil history recorded events: %v", events)
+	}
+}
+
+// historyTestChain is a block lookup backing the recording of inclusions.
+type historyTestChain struct {
+	BlockChain
+	blocks  map[common.Hash]*types.Block
+	lookups int
+}
+
+func (c *historyTestChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	c.lookups++
+	if block := c.blocks[hash]; block != nil && block.NumberU64() == number {
+		return block
+	}
+	return nil
+}
+
+// Tests that the inclusions are recorded for all the blocks between two heads,
+// including the ones of the new branch after a reorg.
+func TestRecordIncluded(t *testing.T) {
+	var (
+		chain = &historyTestChain{blocks: make(map[common.Hash]*types.Block)}
+		pool  = &TxPool{history: NewTxHistory()}
+	)
+	defer pool.history.Close()
+
+	// Create a chain of blocks with a tracked transaction each, forking at the
+	// given parent.
+	nonce := uint64(0)
+	extend := func(parent *types.Block, n int, extra byte) []*types.Block {
+		var blocks []*types.Block
+		for i := 0; i < n; i++ {
+			tx := types.NewTransaction(nonce, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+			nonce++
+			pool.history.Received(tx.Hash(), "local")
+
+			header := &types.Header{ParentHash: parent.Hash(), Number: new(big.Int).Add(parent.Number(), common.Big1), Extra: []byte{extra}}
+			block := types.NewBlock(header, &types.Body{Transactions: types.Transactions{tx}}, nil, trie.NewStackTrie(nil))
+			chain.blocks[block.Hash()] = block
+			blocks = append(blocks, block)
+			parent = block
+		}
+		return blocks
+	}
+	included := func(block *types.Block) bool {
+		kinds := historyKinds(pool.history, block.Transactions()[0].Hash())
+		return slices.Contains(kinds, TxEventIncluded)
+	}
+	genesis := types.NewBlockWithHeader(&types.Header{Number: common.Big0})
+	chain.blocks[genesis.Hash()] = genesis
+
+	// Jumping several blocks ahead records all of them
+	canon := extend(genesis, 3, 0)
+	pool.recordIncluded(chain, genesis.Header(), canon[2])
+	for i, block := range canon {
+		if !included(block) {
+			t.Errorf("block %d inclusion not recorded", i+1)
+		}
+	}
+	// Reorging to a shorter sidechain records the blocks of the new branch
+	side := extend(canon[0], 1, 1)
+	pool.recordIncluded(chain, canon[2].Header(), side[0])
+	if !included(side[0]) {
+		t.Error("sidechain block inclusion not recorded")
+	}
+	// Reorging from a deep chain to a shorter one stops walking the old chain
+	// after the step limit
+	deep := extend(side[0], 4*maxIncludedBlocks, 2)
+	chain.lookups = 0
+	pool.recordIncluded(chain, deep[len(deep)-1].Header(), canon[1])
+	if chain.lookups > maxIncludedBlocks {
+		t.Errorf("too many block lookups: have %d, want at most %d", chain.lookups, maxIncludedBlocks)
+	}
+}
@@ -41,6 +41,13 @@ type devNull struct{}
 func (*devNull) Write(p []byte) (n int, err error) { return len(p), nil }
 func (*devNull) Close() error                      { return nil }
 
+// privateEntry is the journal entry of a private transaction. It's encoded as
+// a two element list, which is distinguishable from the transactions.
+type privateEntry struct {
+	Tx     *types.Transaction
+	Expiry uint64
+}
+
 // journal is a rotating log of transactions with the aim of storing locally
 // created transactions to allow non-executed ones to survive node restarts.
 type journal struct {
@@ -57,7 +64,7 @@ func newTxJournal(path string) *journal {
 
 // load parses a transaction journal dump from disk, loading its contents into
 // the specified pool.
-func (journal *journal) load(add func([]*types.Transaction) []error) error {
+func (journal *journal) load(add func([]*types.Transaction) []error, addPrivate func(*types.Transaction, uint64) error) error {
 	// Open the journal for loading any past transactions
 	input, err := os.Open(journal.path)
 	if errors.Is(err, fs.ErrNotExist) {
@@ -93,9 +100,9 @@ func (journal *journal) load(add func([]*types.Transaction) []error) error {
 		batch   types.Transactions
 	)
 	for {
-		// Parse the next transaction and terminate on error
-		tx := new(types.Transaction)
-		if err = stream.Decode(tx); err != nil {
+		// Parse the next entry and terminate on error
+		var entry rlp.RawValue
+		if err = stream.Decode(&entry); err != nil {
 			if err != io.EOF {
 				failure = err
 			}
@@ -104,9 +111,35 @@ func (journal *journal) load(add func([]*types.Transaction) []error) error {
 			}
 			break
 		}
-		// New transaction parsed, queue up for later, import if threshold is reached
 		total++
 
+		// Private transactions are added one by one after the preceding ones
+		if isPrivateEntry(entry) {
+			var private privateEntry
+			if err = rlp.DecodeBytes(entry, &private); err != nil {
+				failure = err
+				break
+			}
+			if batch.Len() > 0 {
+				loadBatch(batch)
+				batch = batch[:0]
+			}
+			if err := addPrivate(private.Tx, private.Expiry); err != nil {
+				log.Debug("Failed to add journaled private transaction", "err", err)
+				dropped++
+			}
+			continue
+		}
+		tx := new(types.Transaction)
+		if err = rlp.DecodeBytes(entry, tx); err != nil {
+			failure = err
+			if batch.Len() > 0 {
+				loadBatch(batch)
+			}
+			break
+		}
+		// New transaction parsed, queue up for later, import if threshold is reached
+
 		if batch = append(batch, tx); batch.Len() > 1024 {
 			loadBatch(batch)
 			batch = batch[:0]
@@ -117,6 +150,17 @@ func (journal *journal) load(add func([]*types.Transaction) []error) error {
 	return failure
 }
 
+// isPrivateEntry reports whether the journal entry is a private transaction,
+// which is a two element list unlike the legacy transactions.
+func isPrivateEntry(entry rlp.RawValue) bool {
+	kind, content, _, err := rlp.Split(entry)
+	if err != nil || kind != rlp.List {
+		return false
+	}
+	n, err := rlp.CountValues(content)
+	return err == nil && n == 2
+}
+
 // insert adds the specified transaction to the local disk journal.
 func (journal *journal) insert(tx *types.Transaction) error {
 	if journal.writer == nil {
@@ -128,9 +172,17 @@ func (journal *journal) insert(tx *types.Transaction) error {
 	return nil
 }
 
+// insertPrivate adds the specified private transaction to the local disk journal.
+func (journal *journal) insertPrivate(tx *types.Transaction, expiry uint64) error {
+	if journal.writer == nil {
+		return errNoActiveJournal
+	}
+	return rlp.Encode(journal.writer, &privateEntry{Tx: tx, Expiry: expiry})
+}
+
 // rotate regenerates the transaction journal based on the current contents of
-// the transaction pool.
-func (journal *journal) rotate(all map[common.Address]types.Transactions) error {
+// the transaction pool, along with the expiries of the private transactions.
+func (journal *journal) rotate(all map[common.Address]types.Transactions, private map[common.Hash]uint64) error {
 	// Close the current journal (if any is open)
 	if journal.writer != nil {
 		if err := journal.writer.Close(); err != nil {
@@ -146,7 +198,12 @@ func (journal *journal) rotate(all map[common.Address]types.Transactions) error
 	journaled := 0
 	for _, txs := range all {
 		for _, tx := range txs {
-			if err = rlp.Encode(replacement, tx); err != nil {
+			if expiry, ok := private[tx.Hash()]; ok {
+				err = rlp.Encode(replacement, &privateEntry{Tx: tx, Expiry: expiry})
+			} else {
+				err = rlp.Encode(replacement, tx)
+			}
+			if err != nil {
 				replacement.Close()
 				return err
 			}
@@ -19,6 +19,7 @@ package legacypool
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"sort"
@@ -59,6 +60,15 @@ var (
 	// ErrTxPoolOverflow is returned if the transaction pool is full and can't accept
 	// another remote transaction.
 	ErrTxPoolOverflow = errors.New("txpool is full")
+
+	// ErrInflightTxLimitReached is returned when the maximum number of in-flight
+	// transactions is reached for specific accounts.
+	ErrInflightTxLimitReached = errors.New("in-flight transaction limit reached for delegated accounts")
+
+	// ErrAuthorityReserved is returned if a transaction has an authorization
+	// signed by an address which already has in-flight transactions known to the
+	// pool.
+	ErrAuthorityReserved = errors.New("authority already reserved")
 )
 
 var (
@@ -86,6 +96,7 @@ var (
 	invalidTxMeter     = metrics.NewRegisteredMeter("txpool/invalid", nil)
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 	overflowedTxMeter  = metrics.NewRegisteredMeter("txpool/overflowed", nil)
+	policyTxMeter      = metrics.NewRegisteredMeter("txpool/policy", nil) // Rejected by the admission policy
 
 	// throttleTxMeter counts how many transactions are rejected due to too-many-changes between
 	// txpool reorgs.
@@ -136,6 +147,8 @@ type Config struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	PrivateLifetime uint64 // Number of blocks a private transaction is kept for
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -152,6 +165,8 @@ var DefaultConfig = Config{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	PrivateLifetime: 100,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -190,6 +205,10 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultConfig.Lifetime)
 		conf.Lifetime = DefaultConfig.Lifetime
 	}
+	if conf.PrivateLifetime < 1 {
+		log.Warn("Sanitizing invalid txpool private lifetime", "provided", conf.PrivateLifetime, "updated", DefaultConfig.PrivateLifetime)
+		conf.PrivateLifetime = DefaultConfig.PrivateLifetime
+	}
 	return conf
 }
 
@@ -205,6 +224,8 @@ type LegacyPool struct {
 	chainconfig *params.ChainConfig
 	chain       BlockChain
 	gasTip      atomic.Pointer[uint256.Int]
+	policy      atomic.Pointer[txpool.Admission]
+	history     *txpool.TxHistory
 	txFeed      event.Feed
 	signer      types.Signer
 	mu          sync.RWMutex
@@ -222,6 +243,7 @@ type LegacyPool struct {
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
 	all     *lookup                      // All transactions to allow lookups
 	priced  *pricedList                  // All transactions sorted by price
+	private map[common.Hash]uint64       // Expiry block numbers of the private transactions
 
 	reqResetCh      chan *txpoolResetRequest
 	reqPromoteCh    chan *accountSet
@@ -254,6 +276,7 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		queue:           make(map[common.Address]*list),
 		beats:           make(map[common.Address]time.Time),
 		all:             newLookup(),
+		private:         make(map[common.Hash]uint64),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
 		queueTxEventCh:  make(chan *types.Transaction),
@@ -317,10 +340,10 @@ func (pool *LegacyPool) Init(gasTip uint64, head *types.Header, reserve txpool.A
 
 	// If local transactions and journaling is enabled, load from disk
 	if pool.journal != nil {
-		if err := pool.journal.load(pool.addLocals); err != nil {
+		if err := pool.journal.load(pool.addLocals, pool.addPrivate); err != nil {
 			log.Warn("Failed to load transaction journal", "err", err)
 		}
-		if err := pool.journal.rotate(pool.local()); err != nil {
+		if err := pool.journal.rotate(pool.local(), pool.privateExpiries()); err != nil {
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
@@ -380,6 +403,7 @@ func (pool *LegacyPool) loop() {
 					list := pool.queue[addr].Flatten()
 					for _, tx := range list {
 						pool.removeTx(tx.Hash(), true, true)
+						pool.history.Evicted(tx.Hash(), "queued lifetime exceeded")
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
@@ -390,7 +414,7 @@ func (pool *LegacyPool) loop() {
 		case <-journal.C:
 			if pool.journal != nil {
 				pool.mu.Lock()
-				if err := pool.journal.rotate(pool.local()); err != nil {
+				if err := pool.journal.rotate(pool.local(), pool.privateExpiries()); err != nil {
 					log.Warn("Failed to rotate local tx journal", "err", err)
 				}
 				pool.mu.Unlock()
@@ -446,12 +470,25 @@ func (pool *LegacyPool) SetGasTip(tip *big.Int) {
 		drop := pool.all.RemotesBelowTip(tip)
 		for _, tx := range drop {
 			pool.removeTx(tx.Hash(), false, true)
+			pool.history.Evicted(tx.Hash(), "below minimum tip")
 		}
 		pool.priced.Removed(len(drop))
 	}
 	log.Info("Legacy pool tip threshold updated", "tip", newTip)
 }
 
+// SetHistory sets the lifecycle history the pool records its transactions in.
+func (pool *LegacyPool) SetHistory(history *txpool.TxHistory) {
+	pool.history = history
+}
+
+// SetPolicy replaces the admission policy of the new transactions, nil disables
+// it. The transactions already in the pool are not affected.
+func (pool *LegacyPool) SetPolicy(admission *txpool.Admission) {
+	pool.policy.Store(admission)
+	log.Info("Legacy pool admission policy updated", "enabled", admission != nil)
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (pool *LegacyPool) Nonce(addr common.Address) uint64 {
@@ -486,38 +523,59 @@ func (pool *LegacyPool) stats() (int, int) {
 
 // Content retrieves the data content of the transaction pool, returning all the
 // pending as well as queued transactions, grouped by account and sorted by nonce.
+// The private transactions are left out.
 func (pool *LegacyPool) Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
 	pending := make(map[common.Address][]*types.Transaction, len(pool.pending))
 	for addr, list := range pool.pending {
-		pending[addr] = list.Flatten()
+		if txs := pool.public(list.Flatten()); len(txs) > 0 {
+			pending[addr] = txs
+		}
 	}
 	queued := make(map[common.Address][]*types.Transaction, len(pool.queue))
 	for addr, list := range pool.queue {
-		queued[addr] = list.Flatten()
+		if txs := pool.public(list.Flatten()); len(txs) > 0 {
+			queued[addr] = txs
+		}
 	}
 	return pending, queued
 }
 
 // ContentFrom retrieves the data content of the transaction pool, returning the
-// pending as well as queued transactions of this address, grouped by nonce.
+// pending as well as queued transactions of this address, grouped by nonce. The
+// private transactions are left out.
 func (pool *LegacyPool) ContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
 	pool.mu.RLock()
 	defer pool.mu.RUnlock()
 
 	var pending []*types.Transaction
 	if list, ok := pool.pending[addr]; ok {
-		pending = list.Flatten()
+		pending = pool.public(list.Flatten())
 	}
 	var queued []*types.Transaction
 	if list, ok := pool.queue[addr]; ok {
-		queued = list.Flatten()
+		queued = pool.public(list.Flatten())
 	}
 	return pending, queued
 }
 
+// public returns the transactions of the list which are not private, without
+// modifying the list. The caller must hold pool.mu.
+func (pool *LegacyPool) public(txs []*types.Transaction) []*types.Transaction {
+	if len(pool.private) == 0 {
+		return txs
+	}
+	filtered := make([]*types.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if _, ok := pool.private[tx.Hash()]; !ok {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered
+}
+
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce.
 //
@@ -547,6 +605,17 @@ func (pool *LegacyPool) Pending(filter txpool.PendingFilter) map[common.Address]
 	for addr, list := range pool.pending {
 		txs := list.Flatten()
 
+		// Cap the lists at the first private transaction unless requested, to
+		// avoid nonce gaps
+		if !filter.IncludePrivate && len(pool.private) > 0 {
+			for i, tx := range txs {
+				if _, ok := pool.private[tx.Hash()]; ok {
+					txs = txs[:i]
+					break
+				}
+			}
+		}
+
 		// If the miner requests tip enforcement, cap the lists now
 		if minTipBig != nil && !pool.locals.contains(addr) {
 			for i, tx := range txs {
@@ -610,7 +679,8 @@ func (pool *LegacyPool) validateTxBasics(tx *types.Transaction, local bool) erro
 		Accept: 0 |
 			1<<types.LegacyTxType |
 			1<<types.AccessListTxType |
-			1<<types.DynamicFeeTxType,
+			1<<types.DynamicFeeTxType |
+			1<<types.SetCodeTxType,
 		MaxSize: txMaxSize,
 		MinTip:  pool.gasTip.Load().ToBig(),
 	}
@@ -658,6 +728,50 @@ func (pool *LegacyPool) validateTx(tx *types.Transaction, local bool) error {
 	if err := txpool.ValidateTransactionWithState(tx, pool.signer, opts); err != nil {
 		return err
 	}
+	return pool.validateAuth(tx)
+}
+
+// validateAuth verifies that the transaction complies with code authorization
+// restrictions brought by SetCode transaction type.
+func (pool *LegacyPool) validateAuth(tx *types.Transaction) error {
+	from, _ := types.Sender(pool.signer, tx) // validated
+
+	// Allow at most one in-flight tx for delegated accounts or those with a
+	// pending authorization.
+	_, delegated := types.ParseDelegation(pool.currentState.GetCode(from))
+	if delegated || pool.all.hasAuth(from) {
+		var (
+			count  int
+			exists bool
+		)
+		if pending := pool.pending[from]; pending != nil {
+			count += pending.Len()
+			exists = pending.Contains(tx.Nonce())
+		}
+		if queue := pool.queue[from]; queue != nil {
+			count += queue.Len()
+			exists = exists || queue.Contains(tx.Nonce())
+		}
+		// Replacing the existing in-flight transaction of delegated accounts
+		// is still supported.
+		if count >= 1 && !exists {
+			return ErrInflightTxLimitReached
+		}
+	}
+	// Authorities with more than one in-flight transaction are rejected, as
+	// the delegation may invalidate the queued ones by draining the balance.
+	for _, auth := range tx.SetCodeAuthorities() {
+		var count int
+		if pending := pool.pending[auth]; pending != nil {
+			count += pending.Len()
+		}
+		if queue := pool.queue[auth]; queue != nil {
+			count += queue.Len()
+		}
+		if count > 1 {
+			return ErrAuthorityReserved
+		}
+	}
 	return nil
 }
 
@@ -768,6 +882,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 
 			sender, _ := types.Sender(pool.signer, tx)
 			dropped := pool.removeTx(tx.Hash(), false, sender != from) // Don't unreserve the sender of the tx being added if last from the acc
+			pool.history.Evicted(tx.Hash(), "underpriced in full pool")
 
 			pool.changesSinceReorg += dropped
 		}
@@ -786,11 +901,14 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
+			pool.history.Replaced(old.Hash(), hash)
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
 		pool.journalTx(from, tx)
 		pool.queueTxEvent(tx)
+		pool.history.Validated(hash)
+		pool.history.Promoted(hash)
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
 		// Successful promotion, bump the heartbeat
@@ -802,6 +920,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 	if err != nil {
 		return false, err
 	}
+	pool.history.Validated(hash)
 	// Mark local addresses and journal local transactions
 	if local && !pool.locals.contains(from) {
 		log.Info("Setting new local account", "address", from)
@@ -861,6 +980,7 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
+		pool.history.Replaced(old.Hash(), hash)
 	} else {
 		// Nothing was replaced, bump the queued counter
 		queuedGauge.Inc(1)
@@ -888,6 +1008,17 @@ func (pool *LegacyPool) journalTx(from common.Address, tx *types.Transaction) {
 	if pool.journal == nil || !pool.locals.contains(from) {
 		return
 	}
+	if expiry, ok := pool.private[tx.Hash()]; ok {
+		// The preconditions are not journaled, don't resurrect the transaction
+		// without them
+		if tx.Conditional() != nil {
+			return
+		}
+		if err := pool.journal.insertPrivate(tx, expiry); err != nil {
+			log.Warn("Failed to journal private transaction", "err", err)
+		}
+		return
+	}
 	if err := pool.journal.insert(tx); err != nil {
 		log.Warn("Failed to journal local transaction", "err", err)
 	}
@@ -910,6 +1041,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
+		pool.history.Evicted(hash, "outbid by pending transaction")
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -917,6 +1049,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
+		pool.history.Replaced(old.Hash(), hash)
 	} else {
 		// Nothing was replaced, bump the pending counter
 		pendingGauge.Inc(1)
@@ -944,6 +1077,101 @@ func (pool *LegacyPool) addLocal(tx *types.Transaction) error {
 	return pool.addLocals([]*types.Transaction{tx})[0]
 }
 
+// AddPrivate enqueues a local transaction into the pool, which is only used for
+// building blocks locally and never announced to the network. The transaction
+// is dropped if it's not included within the configured number of blocks.
+func (pool *LegacyPool) AddPrivate(tx *types.Transaction) error {
+	return pool.addPrivate(tx, pool.currentHead.Load().Number.Uint64()+pool.config.PrivateLifetime)
+}
+
+// addPrivate enqueues a private transaction which expires after the given block.
+func (pool *LegacyPool) addPrivate(tx *types.Transaction, expiry uint64) error {
+	hash := tx.Hash()
+
+	// Mark the transaction private before adding it, to suppress the events
+	pool.mu.Lock()
+	if _, ok := pool.private[hash]; ok || pool.all.Get(hash) != nil {
+		pool.mu.Unlock()
+		return txpool.ErrAlreadyKnown
+	}
+	if expiry <= pool.currentHead.Load().Number.Uint64() {
+		pool.mu.Unlock()
+		return txpool.ErrPrivateTxExpired
+	}
+	if cond := tx.Conditional(); cond != nil {
+		if err := pool.checkConditional(cond); err != nil {
+			pool.mu.Unlock()
+			return err
+		}
+	}
+	pool.private[hash] = expiry
+	pool.mu.Unlock()
+
+	if err := pool.addLocal(tx); err != nil {
+		pool.mu.Lock()
+		delete(pool.private, hash)
+		pool.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// privateExpiries returns a copy of the expiry block numbers of the private
+// transactions.
+func (pool *LegacyPool) privateExpiries() map[common.Hash]uint64 {
+	return maps.Clone(pool.private)
+}
+
+// expirePrivate drops the private transactions which have not been included
+// before their expiry, and forgets the ones which left the pool.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) expirePrivate(number uint64) {
+	for hash, expiry := range pool.private {
+		if pool.all.Get(hash) == nil {
+			delete(pool.private, hash)
+			continue
+		}
+		if number >= expiry {
+			log.Debug("Dropping expired private transaction", "hash", hash, "expiry", expiry)
+			pool.removeTx(hash, true, true)
+			delete(pool.private, hash)
+			pool.history.Evicted(hash, "private lifetime exceeded")
+		}
+	}
+}
+
+// checkConditional verifies the preconditions of a transaction can still be met
+// on top of the current head.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) checkConditional(cond *types.TransactionConditional) error {
+	head := pool.currentHead.Load()
+	if cond.Expired(head.Number.Uint64(), head.Time) {
+		return fmt.Errorf("%w: expired at block %d", types.ErrConditionalFailed, head.Number)
+	}
+	return cond.CheckState(pool.currentState)
+}
+
+// recheckConditionals drops the private transactions whose preconditions can't
+// be met anymore on top of the new head.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) recheckConditionals() {
+	for hash := range pool.private {
+		tx := pool.all.Get(hash)
+		if tx == nil || tx.Conditional() == nil {
+			continue
+		}
+		if err := pool.checkConditional(tx.Conditional()); err != nil {
+			log.Debug("Dropping conditional transaction", "hash", hash, "err", err)
+			pool.removeTx(hash, true, true)
+			delete(pool.private, hash)
+			pool.history.Evicted(hash, err.Error())
+		}
+	}
+}
+
 // addRemotes enqueues a batch of transactions into the pool if they are valid. If the
 // senders are not among the locally tracked ones, full pricing constraints will apply.
 //
@@ -980,8 +1208,9 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error
 
 	// Filter out known ones without obtaining the pool lock or recovering signatures
 	var (
-		errs = make([]error, len(txs))
-		news = make([]*types.Transaction, 0, len(txs))
+		errs      = make([]error, len(txs))
+		news      = make([]*types.Transaction, 0, len(txs))
+		admission = pool.policy.Load()
 	)
 	for i, tx := range txs {
 		// If the transaction is known, pre-set the error slot
@@ -999,6 +1228,17 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error
 			invalidTxMeter.Mark(1)
 			continue
 		}
+		// Enforce the admission policy on top of the validity rules, the sender
+		// is already cached by the basic validation
+		if admission != nil {
+			from, _ := types.Sender(pool.signer, tx)
+			if err := admission.Check(tx, from); err != nil {
+				errs[i] = err
+				log.Trace("Discarding transaction rejected by policy", "hash", tx.Hash(), "from", from, "err", err)
+				policyTxMeter.Mark(1)
+				continue
+			}
+		}
 		// Accumulate all unknown transactions for deeper processing
 		news = append(news, tx)
 	}
@@ -1012,12 +1252,19 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error
 	pool.mu.Unlock()
 
 	var nilSlot = 0
-	for _, err := range newErrs {
+	for i, err := range newErrs {
 		for errs[nilSlot] != nil {
 			nilSlot++
 		}
 		errs[nilSlot] = err
 		nilSlot++
+
+		// Only the transactions accepted by the pool count against the rate
+		// limit of their sender
+		if admission != nil && err == nil {
+			from, _ := types.Sender(pool.signer, news[i])
+			admission.Admit(from)
+		}
 	}
 	// Reorg the pool internals if needed and return
 	done := pool.requestPromoteExecutables(dirtyAddrs)
@@ -1044,7 +1291,7 @@ func (pool *LegacyPool) addTxsLocked(txs []*types.Transaction, local bool) ([]er
 }
 
 // Status returns the status (unknown/pending/queued) of a batch of transactions
-// identified by their hashes.
+// identified by their hashes. The private transactions are reported unknown.
 func (pool *LegacyPool) Status(hash common.Hash) txpool.TxStatus {
 	tx := pool.get(hash)
 	if tx == nil {
@@ -1055,6 +1302,10 @@ func (pool *LegacyPool) Status(hash common.Hash) txpool.TxStatus {
 	pool.mu.RLock()
 	defer pool.mu.RUnlock()
 
+	// The private transactions are not revealed to the RPC users
+	if _, ok := pool.private[hash]; ok {
+		return txpool.TxStatusUnknown
+	}
 	if txList := pool.pending[from]; txList != nil && txList.txs.items[tx.Nonce()] != nil {
 		return txpool.TxStatusPending
 	} else if txList := pool.queue[from]; txList != nil && txList.txs.items[tx.Nonce()] != nil {
@@ -1064,11 +1315,19 @@ func (pool *LegacyPool) Status(hash common.Hash) txpool.TxStatus {
 }
 
 // Get returns a transaction if it is contained in the pool and nil otherwise.
+// The private transactions are not returned, as they must not be served to the
+// network or the RPC users.
 func (pool *LegacyPool) Get(hash common.Hash) *types.Transaction {
 	tx := pool.get(hash)
 	if tx == nil {
 		return nil
 	}
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	if _, ok := pool.private[hash]; ok {
+		return nil
+	}
 	return tx
 }
 
@@ -1133,6 +1392,7 @@ func (pool *LegacyPool) removeTx(hash common.Hash, outofbound bool, unreserve bo
 			for _, tx := range invalids {
 				// Internal shuffle shouldn't touch the lookup set.
 				pool.enqueueTx(tx.Hash(), tx, false, false)
+				pool.history.Demoted(tx.Hash())
 			}
 			// Update the account nonce if needed
 			pool.pendingNonces.setIfLower(addr, tx.Nonce())
@@ -1298,6 +1558,8 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 	if reset != nil {
 		pool.demoteUnexecutables()
 		if reset.newHead != nil {
+			pool.expirePrivate(reset.newHead.Number.Uint64())
+			pool.recheckConditionals()
 			if pool.chainconfig.IsLondon(new(big.Int).Add(reset.newHead.Number, big.NewInt(1))) {
 				pendingBaseFee := eip1559.CalcBaseFee(pool.chainconfig, reset.newHead)
 				pool.priced.SetBaseFee(pendingBaseFee)
@@ -1331,10 +1593,19 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 	}
 	if len(events) > 0 {
 		var txs []*types.Transaction
+		pool.mu.RLock()
 		for _, set := range events {
-			txs = append(txs, set.Flatten()...)
+			for _, tx := range set.Flatten() {
+				// Private transactions are never announced
+				if _, ok := pool.private[tx.Hash()]; !ok {
+					txs = append(txs, tx)
+				}
+			}
+		}
+		pool.mu.RUnlock()
+		if len(txs) > 0 {
+			pool.txFeed.Send(core.NewTxsEvent{Txs: txs})
 		}
-		pool.txFeed.Send(core.NewTxsEvent{Txs: txs})
 	}
 }
 
@@ -1456,6 +1727,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		for _, tx := range forwards {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.history.Evicted(hash, "nonce too low")
 		}
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
@@ -1463,6 +1735,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.history.Evicted(hash, "insufficient funds or gas limit exceeded")
 		}
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
@@ -1473,6 +1746,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 			hash := tx.Hash()
 			if pool.promoteTx(addr, hash, tx) {
 				promoted = append(promoted, tx)
+				pool.history.Promoted(hash)
 			}
 		}
 		log.Trace("Promoted queued transactions", "count", len(promoted))
@@ -1485,6 +1759,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 			for _, tx := range caps {
 				hash := tx.Hash()
 				pool.all.Remove(hash)
+				pool.history.Evicted(hash, "account queue limit exceeded")
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
 			queuedRateLimitMeter.Mark(int64(len(caps)))
@@ -1550,6 +1825,7 @@ func (pool *LegacyPool) truncatePending() {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
 						pool.all.Remove(hash)
+						pool.history.Evicted(hash, "pending limit exceeded")
 
 						// Update the account nonce to the dropped transaction
 						pool.pendingNonces.setIfLower(offenders[i], tx.Nonce())
@@ -1577,6 +1853,7 @@ func (pool *LegacyPool) truncatePending() {
 					// Drop the transaction from the global pools too
 					hash := tx.Hash()
 					pool.all.Remove(hash)
+					pool.history.Evicted(hash, "pending limit exceeded")
 
 					// Update the account nonce to the dropped transaction
 					pool.pendingNonces.setIfLower(addr, tx.Nonce())
@@ -1624,6 +1901,7 @@ func (pool *LegacyPool) truncateQueue() {
 		if size := uint64(list.Len()); size <= drop {
 			for _, tx := range list.Flatten() {
 				pool.removeTx(tx.Hash(), true, true)
+				pool.history.Evicted(tx.Hash(), "queue limit exceeded")
 			}
 			drop -= size
 			queuedRateLimitMeter.Mark(int64(size))
@@ -1633,6 +1911,7 @@ func (pool *LegacyPool) truncateQueue() {
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 			pool.removeTx(txs[i].Hash(), true, true)
+			pool.history.Evicted(txs[i].Hash(), "queue limit exceeded")
 			drop--
 			queuedRateLimitMeter.Mark(1)
 		}
@@ -1657,6 +1936,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 		for _, tx := range olds {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.history.Evicted(hash, "nonce too low")
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
@@ -1665,6 +1945,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 			pool.all.Remove(hash)
+			pool.history.Evicted(hash, "insufficient funds or gas limit exceeded")
 		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
 
@@ -1674,6 +1955,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 
 			// Internal shuffle shouldn't touch the lookup set.
 			pool.enqueueTx(hash, tx, false, false)
+			pool.history.Demoted(hash)
 		}
 		pendingGauge.Dec(int64(len(olds) + len(drops) + len(invalids)))
 		if pool.locals.contains(addr) {
@@ -1688,6 +1970,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 
 				// Internal shuffle shouldn't touch the lookup set.
 				pool.enqueueTx(hash, tx, false, false)
+				pool.history.Demoted(hash)
 			}
 			pendingGauge.Dec(int64(len(gapped)))
 		}
@@ -1794,6 +2077,7 @@ type lookup struct {
 	lock    sync.RWMutex
 	locals  map[common.Hash]*types.Transaction
 	remotes map[common.Hash]*types.Transaction
+	auths   map[common.Address][]common.Hash // All accounts with a pooled authorization
 }
 
 // newLookup returns a new lookup structure.
@@ -1801,6 +2085,7 @@ func newLookup() *lookup {
 	return &lookup{
 		locals:  make(map[common.Hash]*types.Transaction),
 		remotes: make(map[common.Hash]*types.Transaction),
+		auths:   make(map[common.Address][]common.Hash),
 	}
 }
 
@@ -1899,6 +2184,9 @@ func (t *lookup) Add(tx *types.Transaction, local bool) {
 	} else {
 		t.remotes[tx.Hash()] = tx
 	}
+	for _, addr := range tx.SetCodeAuthorities() {
+		t.auths[addr] = append(t.auths[addr], tx.Hash())
+	}
 }
 
 // Remove removes a transaction from the lookup.
@@ -1919,6 +2207,30 @@ func (t *lookup) Remove(hash common.Hash) {
 
 	delete(t.locals, hash)
 	delete(t.remotes, hash)
+
+	for _, addr := range tx.SetCodeAuthorities() {
+		hashes := t.auths[addr]
+		for i, h := range hashes {
+			if h == hash {
+				hashes = append(hashes[:i], hashes[i+1:]...)
+				break
+			}
+		}
+		if len(hashes) == 0 {
+			delete(t.auths, addr)
+		} else {
+			t.auths[addr] = hashes
+		}
+	}
+}
+
+// hasAuth reports whether the given address is the authority of any pooled
+// set code transaction.
+func (t *lookup) hasAuth(addr common.Address) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return len(t.auths[addr]) != 0
 }
 
 // RemoteToLocals migrates the transactions belongs to the given locals to locals
@@ -24,6 +24,8 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -2670,3 +2672,298 @@ func BenchmarkMultiAccountBatchInsert(b *testing.B) {
 		pool.addRemotesSync([]*types.Transaction{tx})
 	}
 }
+
+// Tests that private transactions are available for block building, but are
+// neither announced nor returned for the peer announcements, and that they
+// survive restarts until they expire.
+func TestPrivateTransactions(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.Journal = filepath.Join(t.TempDir(), "transactions.rlp")
+	config.PrivateLifetime = 10
+
+	pool := New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+
+	events := make(chan core.NewTxsEvent, 10)
+	sub := pool.SubscribeTransactions(events, false)
+	defer sub.Unsubscribe()
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000000))
+
+	private := pricedTransaction(0, 100000, big.NewInt(1), key)
+	if err := pool.AddPrivate(private); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if err := pool.AddPrivate(private); !errors.Is(err, txpool.ErrAlreadyKnown) {
+		t.Fatalf("duplicate private transaction: have %v, want %v", err, txpool.ErrAlreadyKnown)
+	}
+	public := pricedTransaction(1, 100000, big.NewInt(1), key)
+	if err := pool.addLocal(public); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	select {
+	case ev := <-events:
+		if len(ev.Txs) != 1 || ev.Txs[0].Hash() != public.Hash() {
+			t.Fatalf("unexpected transaction event: %d txs", len(ev.Txs))
+		}
+	default:
+		t.Fatal("missing transaction event")
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected transaction event: %d txs", len(ev.Txs))
+	default:
+	}
+	if pending := pool.Pending(txpool.PendingFilter{}); len(pending[addr]) != 0 {
+		t.Fatalf("private transaction is returned: %d txs", len(pending[addr]))
+	}
+	if pending := pool.Pending(txpool.PendingFilter{IncludePrivate: true}); len(pending[addr]) != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want 2", len(pending[addr]))
+	}
+	// The private transaction is hidden from the content and lookups
+	if pool.Get(private.Hash()) != nil {
+		t.Fatal("private transaction is returned by lookup")
+	}
+	if pool.Get(public.Hash()) == nil {
+		t.Fatal("public transaction is not returned by lookup")
+	}
+	if status := pool.Status(private.Hash()); status != txpool.TxStatusUnknown {
+		t.Fatalf("private transaction status is revealed: %v", status)
+	}
+	if status := pool.Status(public.Hash()); status != txpool.TxStatusPending {
+		t.Fatalf("public transaction status mismatch: have %v, want %v", status, txpool.TxStatusPending)
+	}
+	if pending, _ := pool.Content(); len(pending[addr]) != 1 || pending[addr][0].Hash() != public.Hash() {
+		t.Fatalf("private transaction is returned in content: %d txs", len(pending[addr]))
+	}
+	if pending, _ := pool.ContentFrom(addr); len(pending) != 1 || pending[0].Hash() != public.Hash() {
+		t.Fatalf("private transaction is returned in account content: %d txs", len(pending))
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Restart the pool and ensure the transaction is still private
+	pool.Close()
+	pool = New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want 2", pending)
+	}
+	if expiry, ok := pool.private[private.Hash()]; !ok || expiry != 10 {
+		t.Fatalf("private transaction mismatch: expiry %d, private %v", expiry, ok)
+	}
+	if pending := pool.Pending(txpool.PendingFilter{}); len(pending[addr]) != 0 {
+		t.Fatalf("private transaction is returned after restart: %d txs", len(pending[addr]))
+	}
+	// Move past the expiry and ensure the transaction is dropped
+	head := &types.Header{
+		Number:   big.NewInt(10),
+		GasLimit: 1000000,
+		BaseFee:  big.NewInt(params.InitialBaseFee),
+	}
+	<-pool.requestReset(nil, head)
+	if pool.Has(private.Hash()) {
+		t.Fatal("expired private transaction is not dropped")
+	}
+	if len(pool.private) != 0 {
+		t.Fatalf("private transactions are not forgotten: %d", len(pool.private))
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the preconditions of conditional transactions are checked when
+// they are added, and that they are dropped once the preconditions fail.
+func TestConditionalTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	other, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(other.PublicKey), big.NewInt(1000000000))
+
+	var (
+		contract = common.Address{0xc0}
+		slot     = common.Hash{0x01}
+		max      = uint64(3)
+		expired  = uint64(0)
+	)
+	conditional := func(tx *types.Transaction, cond *types.TransactionConditional) *types.Transaction {
+		tx.SetConditional(cond)
+		return tx
+	}
+	// Transactions whose preconditions don't hold are rejected
+	tx := conditional(transaction(0, 100000, key), &types.TransactionConditional{BlockNumberMax: &expired})
+	if err := pool.AddPrivate(tx); !errors.Is(err, types.ErrConditionalFailed) {
+		t.Fatalf("expired conditional: have %v, want %v", err, types.ErrConditionalFailed)
+	}
+	tx = conditional(transaction(0, 100000, key), &types.TransactionConditional{
+		KnownAccounts: map[common.Address]types.KnownAccount{
+			contract: {StorageSlots: map[common.Hash]common.Hash{slot: {0x01}}},
+		},
+	})
+	if err := pool.AddPrivate(tx); !errors.Is(err, types.ErrConditionalFailed) {
+		t.Fatalf("mismatching slot: have %v, want %v", err, types.ErrConditionalFailed)
+	}
+	// Transactions whose preconditions hold are accepted
+	bySlot := conditional(transaction(0, 100000, key), &types.TransactionConditional{
+		KnownAccounts: map[common.Address]types.KnownAccount{
+			contract: {StorageSlots: map[common.Hash]common.Hash{slot: {}}},
+		},
+	})
+	if err := pool.AddPrivate(bySlot); err != nil {
+		t.Fatalf("failed to add conditional transaction: %v", err)
+	}
+	root := types.EmptyRootHash
+	byRoot := conditional(transaction(0, 100000, other), &types.TransactionConditional{
+		KnownAccounts: map[common.Address]types.KnownAccount{
+			contract: {StorageRoot: &root},
+		},
+		BlockNumberMax: &max,
+	})
+	if err := pool.AddPrivate(byRoot); err != nil {
+		t.Fatalf("failed to add conditional transaction: %v", err)
+	}
+	// Change the expected slot and ensure only the affected transaction is dropped
+	pool.mu.Lock()
+	pool.currentState.SetState(contract, slot, common.Hash{0x02})
+	pool.mu.Unlock()
+
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(1), GasLimit: 1000000, BaseFee: big.NewInt(params.InitialBaseFee)})
+	if pool.Has(bySlot.Hash()) {
+		t.Fatal("conditional transaction with mismatching slot is not dropped")
+	}
+	if !pool.Has(byRoot.Hash()) {
+		t.Fatal("conditional transaction with a committed storage root is dropped")
+	}
+	// Move to the last block allowed and ensure the transaction is dropped
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(3), GasLimit: 1000000, BaseFee: big.NewInt(params.InitialBaseFee)})
+	if pool.Has(byRoot.Hash()) {
+		t.Fatal("expired conditional transaction is not dropped")
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the admission policy rejects the new transactions, but leaves the
+// ones already in the pool alone.
+func TestAdmissionPolicy(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	admission, err := txpool.NewAdmission(&txpool.Policy{DenySenders: []common.Address{from}})
+	if err != nil {
+		t.Fatalf("failed to create admission: %v", err)
+	}
+	pool.SetPolicy(admission)
+
+	if err := pool.addRemoteSync(transaction(1, 100000, key)); err != txpool.ErrSenderDenied {
+		t.Fatalf("remote transaction: have %v, want %v", err, txpool.ErrSenderDenied)
+	}
+	if err := pool.addLocal(transaction(1, 100000, key)); err != txpool.ErrSenderDenied {
+		t.Fatalf("local transaction: have %v, want %v", err, txpool.ErrSenderDenied)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatch: have %d, want 1", pending)
+	}
+	pool.SetPolicy(nil)
+	if err := pool.addRemoteSync(transaction(1, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction without policy: %v", err)
+	}
+}
+
+// Tests that only the transactions accepted by the pool count against the rate
+// limit of the admission policy.
+func TestAdmissionRateLimit(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+	testSetNonce(pool, from, 1)
+
+	admission, err := txpool.NewAdmission(&txpool.Policy{SenderRateLimit: 1})
+	if err != nil {
+		t.Fatalf("failed to create admission: %v", err)
+	}
+	pool.SetPolicy(admission)
+
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); !errors.Is(err, core.ErrNonceTooLow) {
+		t.Fatalf("stale transaction: have %v, want %v", err, core.ErrNonceTooLow)
+	}
+	if err := pool.addRemoteSync(transaction(1, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(transaction(2, 100000, key)); err != txpool.ErrSenderRateLimited {
+		t.Fatalf("rate limited transaction: have %v, want %v", err, txpool.ErrSenderRateLimited)
+	}
+}
+
+// Tests that the lifecycle of the transactions is recorded in the history.
+func TestTxHistory(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	history := txpool.NewTxHistory()
+	defer history.Close()
+	pool.SetHistory(history)
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	var (
+		gapped      = pricedTransaction(1, 100000, big.NewInt(1), key)
+		first       = pricedTransaction(0, 100000, big.NewInt(1), key)
+		replacement = pricedTransaction(0, 100000, big.NewInt(2), key)
+	)
+	for _, tx := range []*types.Transaction{gapped, first, replacement} {
+		if err := pool.addRemoteSync(tx); err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	tests := []struct {
+		tx   *types.Transaction
+		want []txpool.TxEventKind
+	}{
+		{gapped, []txpool.TxEventKind{txpool.TxEventValidated, txpool.TxEventPromoted}},
+		{first, []txpool.TxEventKind{txpool.TxEventValidated, txpool.TxEventPromoted, txpool.TxEventReplaced}},
+		{replacement, []txpool.TxEventKind{txpool.TxEventValidated, txpool.TxEventPromoted}},
+	}
+	for i, test := range tests {
+		var have []txpool.TxEventKind
+		for _, ev := range history.Get(test.tx.Hash()) {
+			have = append(have, ev.Kind)
+		}
+		if !slices.Equal(have, test.want) {
+			t.Errorf("test %d: history mismatch: have %v, want %v", i, have, test.want)
+		}
+	}
+	if events := history.Get(first.Hash()); events[len(events)-1].ReplacedBy != replacement.Hash() {
+		t.Fatalf("replacement mismatch: have %x, want %x", events[len(events)-1].ReplacedBy, replacement.Hash())
+	}
+}
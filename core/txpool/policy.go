@@ -0,0 +1,249 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// policyRateWindow is the period the per-sender rate limit of the admission
+// policy applies to.
+const policyRateWindow = time.Minute
+
+// PolicyError is a transaction rejection by the admission policy, carrying the
+// JSON-RPC error code of the rejection reason.
+type PolicyError struct {
+	Code    int
+	Message string
+}
+
+func (e *PolicyError) Error() string  { return e.Message }
+func (e *PolicyError) ErrorCode() int { return e.Code }
+
+var (
+	// ErrSenderNotAllowed is returned if the sender is not on the allow list of
+	// the admission policy.
+	ErrSenderNotAllowed = &PolicyError{Code: -38030, Message: "sender not allowed by txpool policy"}
+
+	// ErrSenderDenied is returned if the sender is on the deny list of the
+	// admission policy.
+	ErrSenderDenied = &PolicyError{Code: -38031, Message: "sender denied by txpool policy"}
+
+	// ErrRecipientNotAllowed is returned if the recipient is not on the allow
+	// list of the admission policy, including contract creations.
+	ErrRecipientNotAllowed = &PolicyError{Code: -38032, Message: "recipient not allowed by txpool policy"}
+
+	// ErrRecipientDenied is returned if the recipient is on the deny list of the
+	// admission policy.
+	ErrRecipientDenied = &PolicyError{Code: -38033, Message: "recipient denied by txpool policy"}
+
+	// ErrSenderRateLimited is returned if the sender exceeds the number of
+	// transactions admitted per minute.
+	ErrSenderRateLimited = &PolicyError{Code: -38034, Message: "sender rate limit exceeded"}
+
+	// ErrPolicyCalldataTooLarge is returned if the calldata of the transaction
+	// exceeds the size allowed by the admission policy.
+	ErrPolicyCalldataTooLarge = &PolicyError{Code: -38035, Message: "calldata exceeds txpool policy limit"}
+
+	// ErrTargetTipTooLow is returned if the tip of the transaction is below the
+	// minimum set by the admission policy for its recipient.
+	ErrTargetTipTooLow = &PolicyError{Code: -38036, Message: "tip below txpool policy minimum for recipient"}
+)
+
+// TargetTip is the minimum tip of the transactions sent to a contract.
+type TargetTip struct {
+	Target common.Address `json:"target"`
+	Tip    *big.Int       `json:"tip"`
+}
+
+// Policy is the declarative admission policy of the transaction pool, applied
+// to the new transactions on top of the validity rules. The deny lists take
+// precedence over the allow lists, and an empty allow list admits everyone.
+type Policy struct {
+	AllowSenders    []common.Address `json:"allowSenders,omitempty"`
+	DenySenders     []common.Address `json:"denySenders,omitempty"`
+	AllowRecipients []common.Address `json:"allowRecipients,omitempty"`
+	DenyRecipients  []common.Address `json:"denyRecipients,omitempty"`
+
+	SenderRateLimit uint64      `json:"senderRateLimit,omitempty"` // Maximum number of transactions admitted per sender per minute, 0 if unlimited
+	MaxCalldata     uint64      `json:"maxCalldata,omitempty"`     // Maximum calldata size in bytes, 0 if unlimited
+	MinTips         []TargetTip `json:"minTips,omitempty"`         // Minimum tips of the transactions sent to specific contracts
+}
+
+// Validate checks the policy is well-formed.
+func (p *Policy) Validate() error {
+	for _, target := range p.MinTips {
+		if target.Tip == nil || target.Tip.Sign() < 0 {
+			return fmt.Errorf("invalid minimum tip for %s: %v", target.Target, target.Tip)
+		}
+	}
+	return nil
+}
+
+// rateWindow is the number of transactions admitted from a sender within the
+// current rate limit period.
+type rateWindow struct {
+	start mclock.AbsTime
+	count uint64
+}
+
+// Admission enforces an admission policy, tracking the rate of the admitted
+// transactions per sender.
+type Admission struct {
+	policy Policy
+
+	allowSenders    map[common.Address]struct{}
+	denySenders     map[common.Address]struct{}
+	allowRecipients map[common.Address]struct{}
+	denyRecipients  map[common.Address]struct{}
+	minTips         map[common.Address]*big.Int
+
+	clock  mclock.Clock
+	rates  map[common.Address]*rateWindow
+	pruned mclock.AbsTime // Last time the stale rate windows were dropped
+	lock   sync.Mutex
+}
+
+// NewAdmission creates the enforcer of the given policy.
+func NewAdmission(policy *Policy) (*Admission, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	a := &Admission{
+		policy:          *policy,
+		allowSenders:    addressSet(policy.AllowSenders),
+		denySenders:     addressSet(policy.DenySenders),
+		allowRecipients: addressSet(policy.AllowRecipients),
+		denyRecipients:  addressSet(policy.DenyRecipients),
+		minTips:         make(map[common.Address]*big.Int),
+		clock:           mclock.System{},
+		rates:           make(map[common.Address]*rateWindow),
+	}
+	for _, target := range policy.MinTips {
+		a.minTips[target.Target] = new(big.Int).Set(target.Tip)
+	}
+	return a, nil
+}
+
+// addressSet converts the address list into a set.
+func addressSet(addrs []common.Address) map[common.Address]struct{} {
+	set := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+	return set
+}
+
+// Policy returns a copy of the enforced policy.
+func (a *Admission) Policy() *Policy {
+	policy := &Policy{
+		AllowSenders:    slices.Clone(a.policy.AllowSenders),
+		DenySenders:     slices.Clone(a.policy.DenySenders),
+		AllowRecipients: slices.Clone(a.policy.AllowRecipients),
+		DenyRecipients:  slices.Clone(a.policy.DenyRecipients),
+		SenderRateLimit: a.policy.SenderRateLimit,
+		MaxCalldata:     a.policy.MaxCalldata,
+	}
+	for _, target := range a.policy.MinTips {
+		policy.MinTips = append(policy.MinTips, TargetTip{Target: target.Target, Tip: new(big.Int).Set(target.Tip)})
+	}
+	return policy
+}
+
+// Check decides whether the transaction of the given sender is admitted. The
+// transaction is not counted against the rate limit of the sender, that is up
+// to Admit once the pool accepted it.
+func (a *Admission) Check(tx *types.Transaction, from common.Address) error {
+	if _, ok := a.denySenders[from]; ok {
+		return ErrSenderDenied
+	}
+	if len(a.allowSenders) > 0 {
+		if _, ok := a.allowSenders[from]; !ok {
+			return ErrSenderNotAllowed
+		}
+	}
+	// Contract creations have no recipient, they are only rejected by a
+	// recipient allow list.
+	if to := tx.To(); to != nil {
+		if _, ok := a.denyRecipients[*to]; ok {
+			return ErrRecipientDenied
+		}
+		if len(a.allowRecipients) > 0 {
+			if _, ok := a.allowRecipients[*to]; !ok {
+				return ErrRecipientNotAllowed
+			}
+		}
+		if tip, ok := a.minTips[*to]; ok && tx.GasTipCapIntCmp(tip) < 0 {
+			return ErrTargetTipTooLow
+		}
+	} else if len(a.allowRecipients) > 0 {
+		return ErrRecipientNotAllowed
+	}
+	if a.policy.MaxCalldata != 0 && uint64(len(tx.Data())) > a.policy.MaxCalldata {
+		return ErrPolicyCalldataTooLarge
+	}
+	if a.policy.SenderRateLimit == 0 {
+		return nil
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.window(from).count >= a.policy.SenderRateLimit {
+		return ErrSenderRateLimited
+	}
+	return nil
+}
+
+// Admit counts a transaction accepted by the pool against the rate limit of
+// the sender.
+func (a *Admission) Admit(from common.Address) {
+	if a.policy.SenderRateLimit == 0 {
+		return
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.window(from).count++
+}
+
+// window returns the current rate limit period of the sender, dropping the
+// stale ones every now and then. The lock must be held.
+func (a *Admission) window(from common.Address) *rateWindow {
+	now := a.clock.Now()
+	if time.Duration(now-a.pruned) >= policyRateWindow {
+		for addr, window := range a.rates {
+			if time.Duration(now-window.start) >= policyRateWindow {
+				delete(a.rates, addr)
+			}
+		}
+		a.pruned = now
+	}
+	window := a.rates[from]
+	if window == nil || time.Duration(now-window.start) >= policyRateWindow {
+		window = &rateWindow{start: now}
+		a.rates[from] = window
+	}
+	return window
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func policyTx(to *common.Address, tip int64, data []byte) *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{
+		To:        to,
+		Gas:       100000,
+		GasTipCap: big.NewInt(tip),
+		GasFeeCap: big.NewInt(tip),
+		Data:      data,
+	})
+}
+
+func TestAdmissionCheck(t *testing.T) {
+	var (
+		alice    = common.HexToAddress("0xa1")
+		bob      = common.HexToAddress("0xb0b")
+		mallory  = common.HexToAddress("0xbad")
+		contract = common.HexToAddress("0xc0de")
+		sink     = common.HexToAddress("0xdead")
+	)
+	tests := []struct {
+		name   string
+		policy Policy
+		from   common.Address
+		tx     *types.Transaction
+		err    error
+	}{
+		{"empty policy", Policy{}, alice, policyTx(&contract, 1, nil), nil},
+		{"denied sender", Policy{DenySenders: []common.Address{mallory}}, mallory, policyTx(&contract, 1, nil), ErrSenderDenied},
+		{"deny over allow", Policy{AllowSenders: []common.Address{mallory}, DenySenders: []common.Address{mallory}}, mallory, policyTx(&contract, 1, nil), ErrSenderDenied},
+		{"allowed sender", Policy{AllowSenders: []common.Address{alice}}, alice, policyTx(&contract, 1, nil), nil},
+		{"unlisted sender", Policy{AllowSenders: []common.Address{alice}}, bob, policyTx(&contract, 1, nil), ErrSenderNotAllowed},
+		{"denied recipient", Policy{DenyRecipients: []common.Address{sink}}, alice, policyTx(&sink, 1, nil), ErrRecipientDenied},
+		{"allowed recipient", Policy{AllowRecipients: []common.Address{contract}}, alice, policyTx(&contract, 1, nil), nil},
+		{"unlisted recipient", Policy{AllowRecipients: []common.Address{contract}}, alice, policyTx(&sink, 1, nil), ErrRecipientNotAllowed},
+		{"creation with recipient allow list", Policy{AllowRecipients: []common.Address{contract}}, alice, policyTx(nil, 1, nil), ErrRecipientNotAllowed},
+		{"creation with recipient deny list", Policy{DenyRecipients: []common.Address{sink}}, alice, policyTx(nil, 1, nil), nil},
+		{"calldata within limit", Policy{MaxCalldata: 4}, alice, policyTx(&contract, 1, make([]byte, 4)), nil},
+		{"calldata over limit", Policy{MaxCalldata: 4}, alice, policyTx(&contract, 1, make([]byte, 5)), ErrPolicyCalldataTooLarge},
+		{"target tip met", Policy{MinTips: []TargetTip{{contract, big.NewInt(10)}}}, alice, policyTx(&contract, 10, nil), nil},
+		{"target tip too low", Policy{MinTips: []TargetTip{{contract, big.NewInt(10)}}}, alice, policyTx(&contract, 9, nil), ErrTargetTipTooLow},
+		{"other target tip", Policy{MinTips: []TargetTip{{contract, big.NewInt(10)}}}, alice, policyTx(&sink, 1, nil), nil},
+	}
+	for _, test := range tests {
+		admission, err := NewAdmission(&test.policy)
+		if err != nil {
+			t.Fatalf("%s: failed to create admission: %v", test.name, err)
+		}
+		if err := admission.Check(test.tx, test.from); err != test.err {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestAdmissionRateLimit(t *testing.T) {
+	admission, err := NewAdmission(&Policy{SenderRateLimit: 2})
+	if err != nil {
+		t.Fatalf("failed to create admission: %v", err)
+	}
+	clock := new(mclock.Simulated)
+	admission.clock = clock
+
+	var (
+		alice = common.HexToAddress("0xa1")
+		bob   = common.HexToAddress("0xb0b")
+		tx    = policyTx(&common.Address{}, 1, nil)
+	)
+	for i := 0; i < 2; i++ {
+		if err := admission.Check(tx, alice); err != nil {
+			t.Fatalf("transaction %d rejected: %v", i, err)
+		}
+		admission.Admit(alice)
+	}
+	if err := admission.Check(tx, alice); err != ErrSenderRateLimited {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrSenderRateLimited)
+	}
+	if err := admission.Check(tx, bob); err != nil {
+		t.Fatalf("other sender rejected: %v", err)
+	}
+	clock.Run(time.Minute)
+	if err := admission.Check(tx, alice); err != nil {
+		t.Fatalf("transaction rejected in the next window: %v", err)
+	}
+	if len(admission.rates) != 1 {
+		t.Fatalf("stale rate windows are not pruned: have %d, want 1", len(admission.rates))
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	if _, err := NewAdmission(&Policy{MinTips: []TargetTip{{Target: common.Address{}}}}); err == nil {
+		t.Fatal("missing minimum tip is accepted")
+	}
+	if _, err := NewAdmission(&Policy{MinTips: []TargetTip{{Target: common.Address{}, Tip: big.NewInt(-1)}}}); err == nil {
+		t.Fatal("negative minimum tip is accepted")
+	}
+}
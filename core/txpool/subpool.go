@@ -83,6 +83,8 @@ type PendingFilter struct {
 
 	OnlyPlainTxs bool // Return only plain EVM transactions (peer-join announces, block space filling)
 	OnlyBlobTxs  bool // Return only blob transactions (block blob-space filling)
+
+	IncludePrivate bool // Return the private transactions too (local block building)
 }
 
 // SubPool represents a specialized transaction pool that lives on its own (e.g.
@@ -163,3 +165,27 @@ type SubPool interface {
 	// identified by their hashes.
 	Status(hash common.Hash) TxStatus
 }
+
+// PrivateSubPool is implemented by the subpools supporting private transactions,
+// which are only used for building blocks locally and never announced to the
+// network.
+type PrivateSubPool interface {
+	// AddPrivate enqueues a private local transaction into the pool if it's
+	// valid. The transaction expires if it's not included in a few blocks.
+	AddPrivate(tx *types.Transaction) error
+}
+
+// PolicySubPool is implemented by the subpools enforcing an admission policy on
+// the new transactions.
+type PolicySubPool interface {
+	// SetPolicy replaces the admission policy of the pool, nil disables it.
+	SetPolicy(admission *Admission)
+}
+
+// HistorySubPool is implemented by the subpools recording the lifecycle of their
+// transactions.
+type HistorySubPool interface {
+	// SetHistory sets the lifecycle history of the pool, it's called before
+	// the pool is initialized.
+	SetHistory(history *TxHistory)
+}
@@ -49,12 +49,21 @@ var (
 	reservationsGaugeName = "txpool/reservations"
 )
 
+// maxIncludedBlocks is the maximum number of blocks walked back along the old and
+// new chains to record the included transactions on a head change, e.g. after a
+// sync or a deep reorg.
+const maxIncludedBlocks = 64
+
 // BlockChain defines the minimal set of methods needed to back a tx pool with
 // a chain. Exists to allow mocking the live chain out of tests.
 type BlockChain interface {
 	// CurrentBlock returns the current head of the chain.
 	CurrentBlock() *types.Header
 
+	// GetBlock retrieves a specific block, used to record the inclusions of
+	// the transactions in the lifecycle history.
+	GetBlock(hash common.Hash, number uint64) *types.Block
+
 	// SubscribeChainHeadEvent subscribes to new blocks being added to the chain.
 	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
 }
@@ -70,6 +79,11 @@ type TxPool struct {
 	reservations map[common.Address]SubPool // Map with the account to pool reservations
 	reserveLock  sync.Mutex                 // Lock protecting the account reservations
 
+	policy     *Admission   // Admission policy of the new transactions, nil if disabled
+	policyLock sync.RWMutex // Lock protecting the admission policy
+
+	history *TxHistory // Lifecycle events of the recently seen transactions
+
 	subs event.SubscriptionScope // Subscription scope to unsubscribe all on shutdown
 	quit chan chan error         // Quit channel to tear down the head updater
 	term chan struct{}           // Termination channel to detect a closed pool
@@ -88,15 +102,20 @@ func New(gasTip uint64, chain BlockChain, subpools []SubPool) (*TxPool, error) {
 	pool := &TxPool{
 		subpools:     subpools,
 		reservations: make(map[common.Address]SubPool),
+		history:      NewTxHistory(),
 		quit:         make(chan chan error),
 		term:         make(chan struct{}),
 		sync:         make(chan chan error),
 	}
 	for i, subpool := range subpools {
+		if subpool, ok := subpool.(HistorySubPool); ok {
+			subpool.SetHistory(pool.history)
+		}
 		if err := subpool.Init(gasTip, head, pool.reserver(i, subpool)); err != nil {
 			for j := i - 1; j >= 0; j-- {
 				subpools[j].Close()
 			}
+			pool.history.Close()
 			return nil, err
 		}
 	}
@@ -168,6 +187,7 @@ func (p *TxPool) Close() error {
 	}
 	// Unsubscribe anyone still listening for tx events
 	p.subs.Close()
+	p.history.Close()
 
 	if len(errs) > 0 {
 		return fmt.Errorf("subpool close errors: %v", errs)
@@ -189,10 +209,12 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 	)
 	defer newHeadSub.Unsubscribe()
 
-	// Track the previous and current head to feed to an idle reset
+	// Track the previous and current head to feed to an idle reset, and the
+	// last head whose transactions were recorded as included
 	var (
-		oldHead = head
-		newHead = oldHead
+		oldHead  = head
+		newHead  = oldHead
+		included = head
 	)
 	// Consume chain head events and start resets when none is running
 	var (
@@ -244,6 +266,8 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 		case event := <-newHeadCh:
 			// Chain moved forward, store the head for later consumption
 			newHead = event.Block.Header()
+			p.recordIncluded(chain, included, event.Block)
+			included = newHead
 
 		case head := <-resetDone:
 			// Previous reset finished, update the old head and allow a new reset
@@ -276,6 +300,48 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 	errc <- nil
 }
 
+// recordIncluded records the inclusions of the transactions of all the blocks
+// leading from the last recorded head to the new one, walking back along both
+// chains until their common ancestor, up to maxIncludedBlocks steps.
+func (p *TxPool) recordIncluded(chain BlockChain, last *types.Header, block *types.Block) {
+	if p.history == nil {
+		return
+	}
+	parent := func(header *types.Header) *types.Block {
+		if header.Number.Sign() == 0 {
+			return nil
+		}
+		return chain.GetBlock(header.ParentHash, header.Number.Uint64()-1)
+	}
+	parentHeader := func(header *types.Header) *types.Header {
+		if block := parent(header); block != nil {
+			return block.Header()
+		}
+		return nil
+	}
+	var blocks []*types.Block
+	for steps := 0; block != nil && last != nil && block.Hash() != last.Hash() && steps < maxIncludedBlocks; steps++ {
+		switch number := block.NumberU64(); {
+		case number > last.Number.Uint64():
+			// The new chain is ahead, record its block
+			blocks = append(blocks, block)
+			block = parent(block.Header())
+
+		case number < last.Number.Uint64():
+			// The old chain is ahead after a reorg, step it back
+			last = parentHeader(last)
+
+		default:
+			// Same height on different branches, step both back
+			blocks = append(blocks, block)
+			block, last = parent(block.Header()), parentHeader(last)
+		}
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		p.history.Included(blocks[i])
+	}
+}
+
 // SetGasTip updates the minimum gas tip required by the transaction pool for a
 // new transaction, and drops all transactions below this threshold.
 func (p *TxPool) SetGasTip(tip *big.Int) {
@@ -318,10 +384,20 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 	txsets := make([][]*types.Transaction, len(p.subpools))
 	splits := make([]int, len(txs))
 
+	source := "remote"
+	if local {
+		source = "local"
+	}
 	for i, tx := range txs {
 		// Mark this transaction belonging to no-subpool
 		splits[i] = -1
 
+		// Start the lifecycle history of the transaction, unless it's a
+		// duplicate announcement of a tracked one
+		if !p.Has(tx.Hash()) {
+			p.history.Received(tx.Hash(), source)
+		}
+
 		// Try to find a subpool that accepts the transaction
 		for j, subpool := range p.subpools {
 			if subpool.Filter(tx) {
@@ -348,9 +424,83 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 		errs[i] = errsets[split][0]
 		errsets[split] = errsets[split][1:]
 	}
+	for i, err := range errs {
+		if err != nil && !errors.Is(err, ErrAlreadyKnown) {
+			p.history.Rejected(txs[i].Hash(), err)
+		}
+	}
 	return errs
 }
 
+// AddPrivate enqueues a local transaction which is only used for building blocks
+// locally and never announced to the network. Its lifecycle is not recorded in
+// the history, as it must not be revealed to the RPC users.
+func (p *TxPool) AddPrivate(tx *types.Transaction) error {
+	if !p.Has(tx.Hash()) {
+		p.history.Hide(tx.Hash())
+	}
+	return p.addPrivate(tx)
+}
+
+// addPrivate hands the private transaction to the subpool accepting its type.
+func (p *TxPool) addPrivate(tx *types.Transaction) error {
+	for _, subpool := range p.subpools {
+		if subpool.Filter(tx) {
+			if private, ok := subpool.(PrivateSubPool); ok {
+				return private.AddPrivate(tx)
+			}
+			return ErrPrivateTxUnsupported
+		}
+	}
+	return core.ErrTxTypeNotSupported
+}
+
+// History returns the recorded lifecycle events of the transaction, oldest
+// first, or nil if it's not seen recently.
+func (p *TxPool) History(hash common.Hash) []TxEvent {
+	return p.history.Get(hash)
+}
+
+// SubscribeHistory registers a subscription for the lifecycle events of all the
+// transactions. Events are dropped if the subscriber falls behind.
+func (p *TxPool) SubscribeHistory(ch chan<- TxEvent) event.Subscription {
+	return p.subs.Track(p.history.Subscribe(ch))
+}
+
+// SetPolicy replaces the admission policy of the new transactions in all the
+// subpools enforcing one, nil disables it. The rate limits start over.
+func (p *TxPool) SetPolicy(policy *Policy) error {
+	var admission *Admission
+	if policy != nil {
+		var err error
+		if admission, err = NewAdmission(policy); err != nil {
+			return err
+		}
+	}
+	p.policyLock.Lock()
+	defer p.policyLock.Unlock()
+
+	for _, subpool := range p.subpools {
+		if subpool, ok := subpool.(PolicySubPool); ok {
+			subpool.SetPolicy(admission)
+		}
+	}
+	p.policy = admission
+	return nil
+}
+
+// Policy returns the admission policy of the new transactions, or nil if none
+// is enforced.
+func (p *TxPool) Policy() *Policy {
+	p.policyLock.RLock()
+	defer p.policyLock.RUnlock()
+
+	if p.policy == nil {
+		return nil
+	}
+	return p.policy.Policy()
+}
+
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce.
 //
@@ -18,6 +18,10 @@ var (
 	RelayerContract        = common.HexToAddress("0xBC10000000000000000000000000000000000006")
 )
 
+// CLRootsContract stores the recent CL block roots in a ring buffer, the code
+// is the same as the EIP-4788 contract and it's deployed at the CL root fork.
+var CLRootsContract = common.HexToAddress("0xBc1000000000000000000000000000000000Beac")
+
 var (
 	// EmptyRequestsHash is the known hash of the empty requests set.
 	EmptyRequestsHash = common.HexToHash("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
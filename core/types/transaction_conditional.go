@@ -0,0 +1,194 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrConditionalFailed is returned if the preconditions of a transaction are
+// not met.
+var ErrConditionalFailed = errors.New("transaction conditional failed")
+
+// KnownAccount is the expected storage of an account, either its storage root
+// or the values of some of its storage slots.
+type KnownAccount struct {
+	StorageRoot  *common.Hash
+	StorageSlots map[common.Hash]common.Hash
+}
+
+// MarshalJSON encodes the expected storage root as a hash and the expected
+// slots as an object.
+func (ka KnownAccount) MarshalJSON() ([]byte, error) {
+	if ka.StorageRoot != nil {
+		return json.Marshal(ka.StorageRoot)
+	}
+	return json.Marshal(ka.StorageSlots)
+}
+
+// UnmarshalJSON decodes either an expected storage root or expected slots.
+func (ka *KnownAccount) UnmarshalJSON(input []byte) error {
+	var root common.Hash
+	if err := json.Unmarshal(input, &root); err == nil {
+		ka.StorageRoot, ka.StorageSlots = &root, nil
+		return nil
+	}
+	var slots map[common.Hash]common.Hash
+	if err := json.Unmarshal(input, &slots); err != nil {
+		return errors.New("known account must be a storage root or a map of storage slots")
+	}
+	ka.StorageRoot, ka.StorageSlots = nil, slots
+	return nil
+}
+
+// TransactionConditional is the set of preconditions on the block and the state
+// a transaction may only be included under.
+type TransactionConditional struct {
+	KnownAccounts  map[common.Address]KnownAccount `json:"knownAccounts"`
+	BlockNumberMin *uint64                         `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *uint64                         `json:"blockNumberMax,omitempty"`
+	TimestampMin   *uint64                         `json:"timestampMin,omitempty"`
+	TimestampMax   *uint64                         `json:"timestampMax,omitempty"`
+}
+
+// conditionalJSON is the hex encoding of the block and timestamp bounds.
+type conditionalJSON struct {
+	KnownAccounts  map[common.Address]KnownAccount `json:"knownAccounts"`
+	BlockNumberMin *hexutil.Uint64                 `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Uint64                 `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64                 `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64                 `json:"timestampMax,omitempty"`
+}
+
+// MarshalJSON encodes the conditional with hex quantities.
+func (c TransactionConditional) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&conditionalJSON{
+		KnownAccounts:  c.KnownAccounts,
+		BlockNumberMin: (*hexutil.Uint64)(c.BlockNumberMin),
+		BlockNumberMax: (*hexutil.Uint64)(c.BlockNumberMax),
+		TimestampMin:   (*hexutil.Uint64)(c.TimestampMin),
+		TimestampMax:   (*hexutil.Uint64)(c.TimestampMax),
+	})
+}
+
+// UnmarshalJSON decodes the conditional from hex quantities.
+func (c *TransactionConditional) UnmarshalJSON(input []byte) error {
+	var dec conditionalJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	c.KnownAccounts = dec.KnownAccounts
+	c.BlockNumberMin = (*uint64)(dec.BlockNumberMin)
+	c.BlockNumberMax = (*uint64)(dec.BlockNumberMax)
+	c.TimestampMin = (*uint64)(dec.TimestampMin)
+	c.TimestampMax = (*uint64)(dec.TimestampMax)
+	return nil
+}
+
+// Cost returns the number of state lookups needed to check the conditional.
+func (c *TransactionConditional) Cost() int {
+	var cost int
+	for _, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			cost++
+		}
+		cost += len(account.StorageSlots)
+	}
+	return cost
+}
+
+// Validate checks the bounds of the conditional are consistent.
+func (c *TransactionConditional) Validate() error {
+	if c.BlockNumberMin != nil && c.BlockNumberMax != nil && *c.BlockNumberMin > *c.BlockNumberMax {
+		return fmt.Errorf("block number range [%d, %d] is empty", *c.BlockNumberMin, *c.BlockNumberMax)
+	}
+	if c.TimestampMin != nil && c.TimestampMax != nil && *c.TimestampMin > *c.TimestampMax {
+		return fmt.Errorf("timestamp range [%d, %d] is empty", *c.TimestampMin, *c.TimestampMax)
+	}
+	return nil
+}
+
+// CheckBlock verifies the block with the given number and timestamp satisfies
+// the bounds of the conditional.
+func (c *TransactionConditional) CheckBlock(number uint64, time uint64) error {
+	if c.BlockNumberMin != nil && number < *c.BlockNumberMin {
+		return fmt.Errorf("%w: block number %d before %d", ErrConditionalFailed, number, *c.BlockNumberMin)
+	}
+	if c.BlockNumberMax != nil && number > *c.BlockNumberMax {
+		return fmt.Errorf("%w: block number %d after %d", ErrConditionalFailed, number, *c.BlockNumberMax)
+	}
+	if c.TimestampMin != nil && time < *c.TimestampMin {
+		return fmt.Errorf("%w: timestamp %d before %d", ErrConditionalFailed, time, *c.TimestampMin)
+	}
+	if c.TimestampMax != nil && time > *c.TimestampMax {
+		return fmt.Errorf("%w: timestamp %d after %d", ErrConditionalFailed, time, *c.TimestampMax)
+	}
+	return nil
+}
+
+// Expired reports whether no block after the given one can satisfy the bounds
+// of the conditional anymore.
+func (c *TransactionConditional) Expired(number uint64, time uint64) bool {
+	return (c.BlockNumberMax != nil && number >= *c.BlockNumberMax) || (c.TimestampMax != nil && time >= *c.TimestampMax)
+}
+
+// ConditionalState is the state access needed to check the known accounts of a
+// conditional.
+type ConditionalState interface {
+	GetStorageRoot(addr common.Address) common.Hash
+	GetState(addr common.Address, slot common.Hash) common.Hash
+}
+
+// CheckState verifies the known accounts of the conditional match the state.
+// The storage roots need to be up to date with the pending storage changes.
+func (c *TransactionConditional) CheckState(state ConditionalState) error {
+	for addr, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			root := state.GetStorageRoot(addr)
+			if root == (common.Hash{}) {
+				root = EmptyRootHash
+			}
+			if root != *account.StorageRoot {
+				return fmt.Errorf("%w: storage root of %s is %s, expected %s", ErrConditionalFailed, addr, root, account.StorageRoot)
+			}
+			continue
+		}
+		for slot, value := range account.StorageSlots {
+			if have := state.GetState(addr, slot); have != value {
+				return fmt.Errorf("%w: storage slot %s of %s is %s, expected %s", ErrConditionalFailed, slot, addr, have, value)
+			}
+		}
+	}
+	return nil
+}
+
+// Conditional returns the preconditions of the transaction, or nil if it can be
+// included unconditionally. The conditional is local metadata, it's not part of
+// the encoding of the transaction.
+func (tx *Transaction) Conditional() *TransactionConditional {
+	return tx.conditional.Load()
+}
+
+// SetConditional attaches preconditions to the transaction.
+func (tx *Transaction) SetConditional(cond *TransactionConditional) {
+	tx.conditional.Store(cond)
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTransactionConditionalJSON(t *testing.T) {
+	input := `{
+		"knownAccounts": {
+			"0x000000000000000000000000000000000000000a": "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+			"0x000000000000000000000000000000000000000b": {
+				"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000002"
+			}
+		},
+		"blockNumberMin": "0x10",
+		"timestampMax": "0x64"
+	}`
+	var cond TransactionConditional
+	if err := json.Unmarshal([]byte(input), &cond); err != nil {
+		t.Fatalf("failed to decode conditional: %v", err)
+	}
+	root := EmptyRootHash
+	minimum, maximum := uint64(16), uint64(100)
+	want := TransactionConditional{
+		KnownAccounts: map[common.Address]KnownAccount{
+			common.HexToAddress("0x0a"): {StorageRoot: &root},
+			common.HexToAddress("0x0b"): {StorageSlots: map[common.Hash]common.Hash{common.HexToHash("0x01"): common.HexToHash("0x02")}},
+		},
+		BlockNumberMin: &minimum,
+		TimestampMax:   &maximum,
+	}
+	if !reflect.DeepEqual(cond, want) {
+		t.Fatalf("conditional mismatch: have %+v, want %+v", cond, want)
+	}
+	if cost := cond.Cost(); cost != 2 {
+		t.Fatalf("cost mismatch: have %d, want 2", cost)
+	}
+	enc, err := json.Marshal(cond)
+	if err != nil {
+		t.Fatalf("failed to encode conditional: %v", err)
+	}
+	var dec TransactionConditional
+	if err := json.Unmarshal(enc, &dec); err != nil {
+		t.Fatalf("failed to decode encoded conditional: %v", err)
+	}
+	if !reflect.DeepEqual(dec, want) {
+		t.Fatalf("round trip mismatch: have %+v, want %+v", dec, want)
+	}
+	if err := json.Unmarshal([]byte(`{"knownAccounts": {"0x000000000000000000000000000000000000000a": 1}}`), &dec); err == nil {
+		t.Fatal("invalid known account accepted")
+	}
+}
+
+func TestTransactionConditionalCheckBlock(t *testing.T) {
+	minimum, maximum := uint64(10), uint64(20)
+	cond := &TransactionConditional{BlockNumberMin: &minimum, BlockNumberMax: &maximum, TimestampMax: &maximum}
+
+	tests := []struct {
+		number, time uint64
+		fail         bool
+	}{
+		{9, 0, true},
+		{10, 0, false},
+		{20, 20, false},
+		{21, 0, true},
+		{15, 21, true},
+	}
+	for i, test := range tests {
+		err := cond.CheckBlock(test.number, test.time)
+		if fail := errors.Is(err, ErrConditionalFailed); fail != test.fail {
+			t.Errorf("test %d: failure mismatch: have %v, want %v", i, err, test.fail)
+		}
+	}
+	if !cond.Expired(20, 0) || cond.Expired(19, 19) {
+		t.Error("expiry mismatch")
+	}
+	if err := (&TransactionConditional{BlockNumberMin: &maximum, BlockNumberMax: &minimum}).Validate(); err == nil {
+		t.Error("empty block range accepted")
+	}
+}
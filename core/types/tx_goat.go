@@ -23,6 +23,23 @@ func (tx *Transaction) IsGoatTx() bool {
 	return tx.inner.txType() == GoatTxType
 }
 
+// GoatTx returns the decoded goat tx payload, it returns nil if it's not a goat tx
+func (tx *Transaction) GoatTx() goattypes.Tx {
+	if !tx.IsGoatTx() {
+		return nil
+	}
+	return tx.inner.(*GoatTx).inner
+}
+
+// GoatModule returns the module and action of the goat tx, it returns zero values if it's not a goat tx
+func (tx *Transaction) GoatModule() (goattypes.Module, goattypes.Action) {
+	if !tx.IsGoatTx() {
+		return 0, 0
+	}
+	inner := tx.inner.(*GoatTx)
+	return inner.Module, inner.Action
+}
+
 func (tx *Transaction) Deposit() *goattypes.Mint {
 	if !tx.IsGoatTx() {
 		return nil
@@ -0,0 +1,139 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// TestParseDelegation tests a few possible delegation designator values and
+// ensures they are parsed correctly.
+func TestParseDelegation(t *testing.T) {
+	addr := common.Address{0x42}
+	for _, tt := range []struct {
+		val  []byte
+		want *common.Address
+	}{
+		{ // simple correct delegation
+			val:  append(DelegationPrefix, addr.Bytes()...),
+			want: &addr,
+		},
+		{ // wrong address size
+			val: append(DelegationPrefix, addr.Bytes()[0:19]...),
+		},
+		{ // short address
+			val: append(DelegationPrefix, 0x42),
+		},
+		{ // long address
+			val: append(append(DelegationPrefix, addr.Bytes()...), 0x42),
+		},
+		{ // wrong prefix size
+			val: append([]byte{0xef, 0x01}, addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x01, 0x01}, addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x00, 0x00}, addr.Bytes()...),
+		},
+		{ // no prefix
+			val: addr.Bytes(),
+		},
+		{ // no address
+			val: DelegationPrefix,
+		},
+	} {
+		got, ok := ParseDelegation(tt.val)
+		if ok && tt.want == nil {
+			t.Fatalf("expected fail, got %s", got.Hex())
+		}
+		if !ok && tt.want != nil {
+			t.Fatalf("failed to parse, want %s", tt.want.Hex())
+		}
+		if tt.want != nil && got != *tt.want {
+			t.Fatalf("wrong address, have %s, want %s", got.Hex(), tt.want.Hex())
+		}
+	}
+}
+
+// Tests that set code transactions and their authorizations survive the
+// encoding roundtrips and recover the correct signers.
+func TestSetCodeTxRoundtrip(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		chainID = big.NewInt(1)
+		signer  = NewPragueSigner(chainID)
+	)
+	auth, err := SignSetCode(key, SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(chainID),
+		Address: common.Address{0x42},
+		Nonce:   1,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign authorization: %v", err)
+	}
+	if authority, err := auth.Authority(); err != nil || authority != addr {
+		t.Fatalf("wrong authority: have %v (%v), want %v", authority, err, addr)
+	}
+	tx := MustSignNewTx(key, signer, &SetCodeTx{
+		ChainID:   uint256.MustFromBig(chainID),
+		Nonce:     1,
+		To:        common.Address{0x01},
+		Gas:       100000,
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(10),
+		Value:     uint256.NewInt(0),
+		AuthList:  []SetCodeAuthorization{auth},
+	})
+	// RLP roundtrip
+	blob, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode tx: %v", err)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(blob); err != nil {
+		t.Fatalf("failed to decode tx: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Fatalf("hash mismatch after rlp roundtrip: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	// JSON roundtrip
+	if blob, err = json.Marshal(tx); err != nil {
+		t.Fatalf("failed to marshal tx: %v", err)
+	}
+	dec = Transaction{}
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatalf("failed to unmarshal tx: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Fatalf("hash mismatch after json roundtrip: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	from, err := Sender(signer, &dec)
+	if err != nil || from != addr {
+		t.Fatalf("wrong sender: have %v (%v), want %v", from, err, addr)
+	}
+	if auths := dec.SetCodeAuthorities(); len(auths) != 1 || auths[0] != addr {
+		t.Fatalf("wrong authorities: have %v, want [%v]", auths, addr)
+	}
+}
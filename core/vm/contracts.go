@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto/blake2b"
 	"github.com/ethereum/go-ethereum/crypto/bn256"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/crypto/secp256r1"
 	"github.com/ethereum/go-ethereum/params"
 	"golang.org/x/crypto/ripemd160"
 )
@@ -177,8 +178,12 @@ func activePrecompiledContracts(rules params.Rules) PrecompiledContracts {
 	switch {
 	case rules.IsVerkle:
 		return PrecompiledContractsVerkle
+	case rules.IsPrague && rules.IsGoatP256Verify:
+		return PrecompiledContractsPragueP256
 	case rules.IsPrague:
 		return PrecompiledContractsPrague
+	case rules.IsCancun && rules.IsGoatP256Verify:
+		return PrecompiledContractsCancunP256
 	case rules.IsCancun:
 		return PrecompiledContractsCancun
 	case rules.IsBerlin:
@@ -200,8 +205,12 @@ func ActivePrecompiledContracts(rules params.Rules) PrecompiledContracts {
 // ActivePrecompiles returns the precompile addresses enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
 	switch {
+	case rules.IsPrague && rules.IsGoatP256Verify:
+		return PrecompiledAddressesPragueP256
 	case rules.IsPrague:
 		return PrecompiledAddressesPrague
+	case rules.IsCancun && rules.IsGoatP256Verify:
+		return PrecompiledAddressesCancunP256
 	case rules.IsCancun:
 		return PrecompiledAddressesCancun
 	case rules.IsBerlin:
@@ -1251,3 +1260,34 @@ func kZGToVersionedHash(kzg kzg4844.Commitment) common.Hash {
 
 	return h
 }
+
+// p256Verify implements the RIP-7212 secp256r1 signature verification as a
+// native contract.
+type p256Verify struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *p256Verify) RequiredGas(input []byte) uint64 {
+	return params.P256VerifyGas
+}
+
+// Run verifies the signature with the 160 bytes input, consisting of the
+// message hash, the signature values r and s, and the public key coordinates
+// x and y, 32 bytes each. One is returned in 32 bytes if the signature is
+// valid, empty output otherwise.
+func (c *p256Verify) Run(input []byte) ([]byte, error) {
+	const p256VerifyInputLength = 160
+	if len(input) != p256VerifyInputLength {
+		return nil, nil
+	}
+	var (
+		hash = input[0:32]
+		r    = new(big.Int).SetBytes(input[32:64])
+		s    = new(big.Int).SetBytes(input[64:96])
+		x    = new(big.Int).SetBytes(input[96:128])
+		y    = new(big.Int).SetBytes(input[128:160])
+	)
+	if secp256r1.Verify(hash, r, s, x, y) {
+		return true32Byte, nil
+	}
+	return nil, nil
+}
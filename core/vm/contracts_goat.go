@@ -0,0 +1,65 @@
+package vm
+
+import (
+	"maps"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// the RIP-7212 address of the secp256r1 signature verifier
+var p256VerifyAddress = common.BytesToAddress([]byte{0x01, 0x00})
+
+// PrecompiledContractsCancunP256 and PrecompiledContractsPragueP256 contain the
+// pre-compiled contracts of the goat chains which have scheduled the secp256r1
+// signature verifier.
+var (
+	PrecompiledContractsCancunP256 = withP256Verify(PrecompiledContractsCancun)
+	PrecompiledContractsPragueP256 = withP256Verify(PrecompiledContractsPrague)
+)
+
+// PrecompiledAddressesCancunP256 contains the addresses of the pre-compiled
+// contracts of PrecompiledContractsCancunP256, in ascending order.
+var PrecompiledAddressesCancunP256 = []common.Address{
+	common.BytesToAddress([]byte{0x01}),
+	common.BytesToAddress([]byte{0x02}),
+	common.BytesToAddress([]byte{0x03}),
+	common.BytesToAddress([]byte{0x04}),
+	common.BytesToAddress([]byte{0x05}),
+	common.BytesToAddress([]byte{0x06}),
+	common.BytesToAddress([]byte{0x07}),
+	common.BytesToAddress([]byte{0x08}),
+	common.BytesToAddress([]byte{0x09}),
+	common.BytesToAddress([]byte{0x0a}),
+	p256VerifyAddress,
+}
+
+// PrecompiledAddressesPragueP256 contains the addresses of the pre-compiled
+// contracts of PrecompiledContractsPragueP256, in ascending order.
+var PrecompiledAddressesPragueP256 = []common.Address{
+	common.BytesToAddress([]byte{0x01}),
+	common.BytesToAddress([]byte{0x02}),
+	common.BytesToAddress([]byte{0x03}),
+	common.BytesToAddress([]byte{0x04}),
+	common.BytesToAddress([]byte{0x05}),
+	common.BytesToAddress([]byte{0x06}),
+	common.BytesToAddress([]byte{0x07}),
+	common.BytesToAddress([]byte{0x08}),
+	common.BytesToAddress([]byte{0x09}),
+	common.BytesToAddress([]byte{0x0a}),
+	common.BytesToAddress([]byte{0x0b}),
+	common.BytesToAddress([]byte{0x0c}),
+	common.BytesToAddress([]byte{0x0d}),
+	common.BytesToAddress([]byte{0x0e}),
+	common.BytesToAddress([]byte{0x0f}),
+	common.BytesToAddress([]byte{0x10}),
+	common.BytesToAddress([]byte{0x11}),
+	common.BytesToAddress([]byte{0x12}),
+	common.BytesToAddress([]byte{0x13}),
+	p256VerifyAddress,
+}
+
+func withP256Verify(contracts PrecompiledContracts) PrecompiledContracts {
+	cpy := maps.Clone(contracts)
+	cpy[p256VerifyAddress] = &p256Verify{}
+	return cpy
+}
@@ -0,0 +1,68 @@
+package vm
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestGoatP256VerifyAddresses(t *testing.T) {
+	for _, tt := range []struct {
+		addrs     []common.Address
+		contracts PrecompiledContracts
+	}{
+		{PrecompiledAddressesCancunP256, PrecompiledContractsCancunP256},
+		{PrecompiledAddressesPragueP256, PrecompiledContractsPragueP256},
+	} {
+		if len(tt.addrs) != len(tt.contracts) {
+			t.Fatalf("address count mismatch: have %d, want %d", len(tt.addrs), len(tt.contracts))
+		}
+		for _, addr := range tt.addrs {
+			if _, ok := tt.contracts[addr]; !ok {
+				t.Fatalf("address %x has no pre-compiled contract", addr)
+			}
+		}
+		if !slices.IsSortedFunc(tt.addrs, common.Address.Cmp) {
+			t.Fatalf("addresses are not in ascending order: %x", tt.addrs)
+		}
+	}
+}
+
+func TestGoatP256VerifyActivation(t *testing.T) {
+	fork := uint64(100)
+	config := *params.AllGoatDebugChainConfig
+	config.Goat = &params.GoatConfig{P256VerifyTime: &fork}
+
+	for _, tt := range []struct {
+		time   uint64
+		prague bool
+		active bool
+	}{
+		{time: 99, active: false},
+		{time: 100, active: true},
+		{time: 99, prague: true, active: false},
+		{time: 100, prague: true, active: true},
+	} {
+		cfg := config
+		if tt.prague {
+			cfg.PragueTime = new(uint64)
+		}
+		rules := cfg.Rules(big.NewInt(1), true, tt.time)
+		if rules.IsGoatP256Verify != tt.active {
+			t.Fatalf("time %d prague %v: rule mismatch, have %v, want %v", tt.time, tt.prague, rules.IsGoatP256Verify, tt.active)
+		}
+		_, ok := activePrecompiledContracts(rules)[p256VerifyAddress]
+		if ok != tt.active {
+			t.Fatalf("time %d prague %v: precompile mismatch, have %v, want %v", tt.time, tt.prague, ok, tt.active)
+		}
+		if have := slices.Contains(ActivePrecompiles(rules), p256VerifyAddress); have != tt.active {
+			t.Fatalf("time %d prague %v: address mismatch, have %v, want %v", tt.time, tt.prague, have, tt.active)
+		}
+		if want := len(activePrecompiledContracts(rules)); len(ActivePrecompiles(rules)) != want {
+			t.Fatalf("time %d prague %v: address count mismatch, have %d, want %d", tt.time, tt.prague, len(ActivePrecompiles(rules)), want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package secp256r1
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("passkey"))
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := key.PublicKey.X, key.PublicKey.Y
+	if !Verify(hash[:], r, s, x, y) {
+		t.Fatal("valid signature rejected")
+	}
+	// Tampered hash
+	other := sha256.Sum256([]byte("other"))
+	if Verify(other[:], r, s, x, y) {
+		t.Fatal("signature accepted for different hash")
+	}
+	// Out of range signature values
+	if Verify(hash[:], new(big.Int), s, x, y) {
+		t.Fatal("zero r accepted")
+	}
+	if Verify(hash[:], r, elliptic.P256().Params().N, x, y) {
+		t.Fatal("s = n accepted")
+	}
+	// Public key not on the curve
+	if Verify(hash[:], r, s, x, new(big.Int).Add(y, big.NewInt(1))) {
+		t.Fatal("invalid public key accepted")
+	}
+	if Verify(hash[:], r, s, nil, nil) {
+		t.Fatal("missing public key accepted")
+	}
+}
+
+func FuzzVerify(f *testing.F) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add([]byte("passkey"))
+	f.Fuzz(func(t *testing.T, msg []byte) {
+		hash := sha256.Sum256(msg)
+		r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(hash[:], r, s, key.PublicKey.X, key.PublicKey.Y) {
+			t.Fatalf("valid signature rejected for %x", msg)
+		}
+		// Flip the lowest bit of s, the signature must be rejected
+		s.Xor(s, big.NewInt(1))
+		if Verify(hash[:], r, s, key.PublicKey.X, key.PublicKey.Y) {
+			t.Fatalf("tampered signature accepted for %x", msg)
+		}
+	})
+}
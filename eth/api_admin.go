@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -89,6 +90,26 @@ func hasAllBlocks(chain *core.BlockChain, bs []*types.Block) bool {
 	return true
 }
 
+// SetTxPoolPolicy replaces the admission policy of the new transactions, null
+// disables it. The per-sender rate limits start over.
+//
+// The policy is served as admin_setTxPoolPolicy rather than txpool_setPolicy:
+// the txpool namespace is commonly exposed on public endpoints, the very ones
+// the policy protects, while admin is only reachable over IPC unless the
+// operator opts in.
+func (api *AdminAPI) SetTxPoolPolicy(policy *txpool.Policy) (bool, error) {
+	if err := api.eth.TxPool().SetPolicy(policy); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// TxPoolPolicy returns the admission policy of the new transactions, or null if
+// none is enforced.
+func (api *AdminAPI) TxPoolPolicy() *txpool.Policy {
+	return api.eth.TxPool().Policy()
+}
+
 // ImportChain imports a blockchain from a local file.
 func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	// Make sure the can access the file to import
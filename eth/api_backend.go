@@ -28,7 +28,6 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
@@ -38,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -143,11 +143,27 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 		}
 		return b.eth.blockchain.GetBlock(header.Hash(), header.Number.Uint64()), nil
 	}
-	return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
+	block := b.eth.blockchain.GetBlockByNumber(uint64(number))
+	if block == nil && b.historyPruned(uint64(number)) {
+		return nil, ethapi.NewPrunedHistoryError()
+	}
+	return block, nil
 }
 
 func (b *EthAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
-	return b.eth.blockchain.GetBlockByHash(hash), nil
+	block := b.eth.blockchain.GetBlockByHash(hash)
+	if block == nil {
+		if header := b.eth.blockchain.GetHeaderByHash(hash); header != nil && b.historyPruned(header.Number.Uint64()) {
+			return nil, ethapi.NewPrunedHistoryError()
+		}
+	}
+	return block, nil
+}
+
+// historyPruned reports whether the body and receipts of the given block have
+// been pruned from the local history.
+func (b *EthAPIBackend) historyPruned(number uint64) bool {
+	return number > 0 && number < b.eth.blockchain.HistoryTail()
 }
 
 // GetBody returns body of a block. It does not resolve special block numbers.
@@ -158,6 +174,9 @@ func (b *EthAPIBackend) GetBody(ctx context.Context, hash common.Hash, number rp
 	if body := b.eth.blockchain.GetBody(hash); body != nil {
 		return body, nil
 	}
+	if b.historyPruned(uint64(number)) {
+		return nil, ethapi.NewPrunedHistoryError()
+	}
 	return nil, errors.New("block body not found")
 }
 
@@ -175,6 +194,9 @@ func (b *EthAPIBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash r
 		}
 		block := b.eth.blockchain.GetBlock(hash, header.Number.Uint64())
 		if block == nil {
+			if b.historyPruned(header.Number.Uint64()) {
+				return nil, ethapi.NewPrunedHistoryError()
+			}
 			return nil, errors.New("header found, but block body is missing")
 		}
 		return block, nil
@@ -203,7 +225,7 @@ func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.B
 	if header == nil {
 		return nil, nil, errors.New("header not found")
 	}
-	stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+	stateDb, err := b.stateAt(header.Root)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -225,7 +247,7 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number.Uint64()) != hash {
 			return nil, nil, errors.New("hash is not currently canonical")
 		}
-		stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+		stateDb, err := b.stateAt(header.Root)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -234,12 +256,36 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 	return nil, nil, errors.New("invalid arguments; neither block nor hash specified")
 }
 
+// stateAt returns the state with the given root. If the state is not available
+// anymore, it falls back to the historical state served by the indexed state
+// histories if enabled.
+func (b *EthAPIBackend) stateAt(root common.Hash) (*state.StateDB, error) {
+	stateDb, err := b.eth.BlockChain().StateAt(root)
+	if err == nil || !b.eth.config.StateHistoryIndex {
+		return stateDb, err
+	}
+	if historic, herr := b.eth.BlockChain().HistoricState(root); herr == nil {
+		return historic, nil
+	}
+	return nil, err
+}
+
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
-	return b.eth.blockchain.GetReceiptsByHash(hash), nil
+	receipts := b.eth.blockchain.GetReceiptsByHash(hash)
+	if receipts == nil {
+		if header := b.eth.blockchain.GetHeaderByHash(hash); header != nil && b.historyPruned(header.Number.Uint64()) {
+			return nil, ethapi.NewPrunedHistoryError()
+		}
+	}
+	return receipts, nil
 }
 
 func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash, number uint64) ([][]*types.Log, error) {
-	return rawdb.ReadLogs(b.eth.chainDb, hash, number), nil
+	logs := rawdb.ReadLogs(b.eth.chainDb, hash, number)
+	if logs == nil && b.historyPruned(number) {
+		return nil, ethapi.NewPrunedHistoryError()
+	}
+	return logs, nil
 }
 
 func (b *EthAPIBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int {
@@ -287,6 +333,10 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.Add([]*types.Transaction{signedTx}, true, false)[0]
 }
 
+func (b *EthAPIBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction) error {
+	return b.eth.txPool.AddPrivate(signedTx)
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending := b.eth.txPool.Pending(txpool.PendingFilter{})
 	var txs types.Transactions
@@ -304,6 +354,14 @@ func (b *EthAPIBackend) GetPoolTransaction(hash common.Hash) *types.Transaction
 	return b.eth.txPool.Get(hash)
 }
 
+func (b *EthAPIBackend) TxPoolHistory(hash common.Hash) (txpool.TxStatus, []txpool.TxEvent) {
+	return b.eth.txPool.Status(hash), b.eth.txPool.History(hash)
+}
+
+func (b *EthAPIBackend) SubscribeTxPoolHistory(ch chan<- txpool.TxEvent) event.Subscription {
+	return b.eth.txPool.SubscribeHistory(ch)
+}
+
 // GetTransaction retrieves the lookup along with the transaction itself associate
 // with the given transaction hash.
 //
@@ -405,15 +463,11 @@ func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 	return b.eth.config.RPCTxFeeCap
 }
 
-func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
-	sections, _, _ := b.eth.bloomIndexer.Sections()
-	return params.BloomBitsBlocks, sections
-}
-
-func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
-	for i := 0; i < bloomFilterThreads; i++ {
-		go session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, b.eth.bloomRequests)
-	}
+// LogIndexStatus returns the section size of the log index, the number of the
+// processed sections and the first section whose index is retained.
+func (b *EthAPIBackend) LogIndexStatus() (uint64, uint64, uint64) {
+	sections, _, _ := b.eth.logIndexer.Sections()
+	return params.LogIndexBlocks, sections, rawdb.ReadLogIndexTail(b.eth.chainDb)
 }
 
 func (b *EthAPIBackend) Engine() consensus.Engine {
@@ -0,0 +1,103 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
+)
+
+// BundleAPI provides the submission and the simulation of transaction bundles,
+// which are included atomically by the miner.
+type BundleAPI struct {
+	e *Ethereum
+}
+
+// NewBundleAPI creates a new BundleAPI instance.
+func NewBundleAPI(e *Ethereum) *BundleAPI {
+	return &BundleAPI{e}
+}
+
+// BundleArgs are the arguments of the bundle submission and simulation.
+type BundleArgs struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	MinBlock          hexutil.Uint64  `json:"minBlock"`
+	MaxBlock          hexutil.Uint64  `json:"maxBlock"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"`
+}
+
+// bundle decodes the transactions of the bundle.
+func (args *BundleArgs) bundle() (*miner.Bundle, error) {
+	bundle := &miner.Bundle{
+		Txs:          make(types.Transactions, len(args.Txs)),
+		MinBlock:     uint64(args.MinBlock),
+		MaxBlock:     uint64(args.MaxBlock),
+		RevertingTxs: args.RevertingTxHashes,
+	}
+	for i, input := range args.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(input); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+		bundle.Txs[i] = tx
+	}
+	return bundle, nil
+}
+
+// CallBundleResult is the outcome of a bundle simulation.
+type CallBundleResult struct {
+	BundleHash common.Hash      `json:"bundleHash"`
+	GasUsed    hexutil.Uint64   `json:"gasUsed"`
+	Profit     *hexutil.Big     `json:"profit"`
+	Receipts   []*types.Receipt `json:"receipts"`
+}
+
+// SendBundle submits an ordered list of signed transactions, which are included
+// atomically ahead of the regular transactions within the given block range,
+// whose maxBlock must be set within a few blocks of the head. The bundle is
+// dropped if any of the transactions reverts, except the ones listed in
+// revertingTxHashes.
+func (api *BundleAPI) SendBundle(args BundleArgs) (common.Hash, error) {
+	bundle, err := args.bundle()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return api.e.Miner().SendBundle(bundle)
+}
+
+// CallBundle simulates an ordered list of signed transactions on top of the
+// latest block and returns their receipts and the profit of the fee recipient.
+func (api *BundleAPI) CallBundle(args BundleArgs) (*CallBundleResult, error) {
+	bundle, err := args.bundle()
+	if err != nil {
+		return nil, err
+	}
+	result, err := api.e.Miner().CallBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+	return &CallBundleResult{
+		BundleHash: result.Hash,
+		GasUsed:    hexutil.Uint64(result.GasUsed),
+		Profit:     (*hexutil.Big)(result.Profit),
+		Receipts:   result.Receipts,
+	}, nil
+}
@@ -0,0 +1,170 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types/goattypes"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxGoatQueryRange is the max block range of a reward or gas revenue query,
+// every block in the range requires a database read.
+const maxGoatQueryRange = 10000
+
+// GoatAPI provides the goat specific chain data queries.
+type GoatAPI struct {
+	eth *Ethereum
+}
+
+// NewGoatAPI creates a new GoatAPI instance.
+func NewGoatAPI(eth *Ethereum) *GoatAPI {
+	return &GoatAPI{eth: eth}
+}
+
+// GoatReward is a validator reward paid by a DistributeRewardTx.
+type GoatReward struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	TxIndex     hexutil.Uint64 `json:"transactionIndex"`
+	Id          hexutil.Uint64 `json:"id"`
+	Recipient   common.Address `json:"recipient"`
+	Goat        *hexutil.Big   `json:"goat"`
+	GasReward   *hexutil.Big   `json:"gasReward"`
+}
+
+// GoatRewards is the reward history of a validator with the aggregate totals.
+type GoatRewards struct {
+	Rewards        []*GoatReward `json:"rewards"`
+	TotalGoat      *hexutil.Big  `json:"totalGoat"`
+	TotalGasReward *hexutil.Big  `json:"totalGasReward"`
+}
+
+// GoatGasRevenue is the gas revenue handed to the locking module by a block.
+type GoatGasRevenue struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Amount      *hexutil.Big   `json:"amount"`
+}
+
+// GoatGasRevenues is the gas revenue list of a block range with the aggregate total.
+type GoatGasRevenues struct {
+	Revenues []*GoatGasRevenue `json:"revenues"`
+	Total    *hexutil.Big      `json:"total"`
+}
+
+// GetRewards returns the rewards paid to the validator reward recipient in the
+// canonical block range [fromBlock, toBlock].
+func (api *GoatAPI) GetRewards(validator common.Address, fromBlock, toBlock rpc.BlockNumber) (*GoatRewards, error) {
+	from, to, err := api.blockRange(fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		totalGoat = new(big.Int)
+		totalGas  = new(big.Int)
+		entries   = api.eth.blockchain.GetGoatRewards(validator, from, to)
+		rewards   = make([]*GoatReward, 0, len(entries))
+	)
+	for _, entry := range entries {
+		rewards = append(rewards, &GoatReward{
+			BlockNumber: hexutil.Uint64(entry.BlockNumber),
+			BlockHash:   entry.BlockHash,
+			TxHash:      entry.TxHash,
+			TxIndex:     hexutil.Uint64(entry.TxIndex),
+			Id:          hexutil.Uint64(entry.Id),
+			Recipient:   entry.Recipient,
+			Goat:        (*hexutil.Big)(entry.Goat),
+			GasReward:   (*hexutil.Big)(entry.GasReward),
+		})
+		totalGoat.Add(totalGoat, entry.Goat)
+		totalGas.Add(totalGas, entry.GasReward)
+	}
+	return &GoatRewards{
+		Rewards:        rewards,
+		TotalGoat:      (*hexutil.Big)(totalGoat),
+		TotalGasReward: (*hexutil.Big)(totalGas),
+	}, nil
+}
+
+// GetGasRevenue returns the gas revenue reported by the GasRequest of every
+// canonical block in the range [fromBlock, toBlock].
+func (api *GoatAPI) GetGasRevenue(fromBlock, toBlock rpc.BlockNumber) (*GoatGasRevenues, error) {
+	from, to, err := api.blockRange(fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		total    = new(big.Int)
+		revenues = make([]*GoatGasRevenue, 0, to-from+1)
+	)
+	for number := from; number <= to; number++ {
+		header := api.eth.blockchain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		requests := api.eth.blockchain.GetGoatRequests(header.Hash(), number)
+		if len(requests) == 0 {
+			return nil, fmt.Errorf("goat requests of block #%d not found", number)
+		}
+		// the gas request is always the first one
+		gas := new(goattypes.GasRequest)
+		if err := gas.Decode(requests[0]); err != nil {
+			return nil, fmt.Errorf("invalid gas request of block #%d: %w", number, err)
+		}
+		revenues = append(revenues, &GoatGasRevenue{
+			BlockNumber: hexutil.Uint64(number),
+			BlockHash:   header.Hash(),
+			Amount:      (*hexutil.Big)(gas.Amount),
+		})
+		total.Add(total, gas.Amount)
+	}
+	return &GoatGasRevenues{Revenues: revenues, Total: (*hexutil.Big)(total)}, nil
+}
+
+// blockRange resolves the given block numbers into a block range.
+func (api *GoatAPI) blockRange(fromBlock, toBlock rpc.BlockNumber) (uint64, uint64, error) {
+	from, err := api.resolveNumber(fromBlock)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := api.resolveNumber(toBlock)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > to {
+		return 0, 0, errors.New("invalid block range")
+	}
+	if to-from >= maxGoatQueryRange {
+		return 0, 0, fmt.Errorf("block range too large (max %d)", maxGoatQueryRange)
+	}
+	return from, to, nil
+}
+
+func (api *GoatAPI) resolveNumber(number rpc.BlockNumber) (uint64, error) {
+	switch number {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		return api.eth.blockchain.CurrentBlock().Number.Uint64(), nil
+	case rpc.FinalizedBlockNumber:
+		if header := api.eth.blockchain.CurrentFinalBlock(); header != nil {
+			return header.Number.Uint64(), nil
+		}
+		return 0, errors.New("finalized block not found")
+	case rpc.SafeBlockNumber:
+		if header := api.eth.blockchain.CurrentSafeBlock(); header != nil {
+			return header.Number.Uint64(), nil
+		}
+		return 0, errors.New("safe block not found")
+	case rpc.EarliestBlockNumber:
+		return 0, nil
+	default:
+		if number < 0 {
+			return 0, fmt.Errorf("invalid block number %d", number)
+		}
+		return uint64(number), nil
+	}
+}
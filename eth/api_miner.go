@@ -51,6 +51,15 @@ func (api *MinerAPI) SetGasPrice(gasPrice hexutil.Big) bool {
 	return true
 }
 
+// SetOrderingPolicy sets the ordering policy of the pending transactions in the
+// built blocks.
+func (api *MinerAPI) SetOrderingPolicy(name string) (bool, error) {
+	if err := api.e.Miner().SetOrderingPolicy(name); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // SetGasLimit sets the gaslimit to target towards during mining.
 func (api *MinerAPI) SetGasLimit(gasLimit hexutil.Uint64) bool {
 	api.e.Miner().SetGasCeil(uint64(gasLimit))
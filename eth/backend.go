@@ -29,7 +29,6 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/pruner"
 	"github.com/ethereum/go-ethereum/core/txpool"
@@ -56,6 +55,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 // Config contains the configuration options of the ETH protocol.
@@ -79,9 +79,7 @@ type Ethereum struct {
 	engine         consensus.Engine
 	accountManager *accounts.Manager
 
-	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
-	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
-	closeBloomHandler chan struct{}
+	logIndexer *core.ChainIndexer // Log indexer operating during block imports
 
 	APIBackend *EthAPIBackend
 
@@ -96,6 +94,10 @@ type Ethereum struct {
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price and etherbase)
 
 	shutdownTracker *shutdowncheck.ShutdownTracker // Tracks if and when the node has shutdown ungracefully
+
+	responseCache    *rpc.ResponseCache // Cache of immutable RPC responses, nil if disabled
+	responseCacheSub event.Subscription // Chain head subscription invalidating the response cache
+	wg               sync.WaitGroup
 }
 
 // New creates a new Ethereum object (including the initialisation of the common Ethereum object),
@@ -125,6 +127,21 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Serve the pruned ancient history from the Era1 archives if configured.
+	if config.HistoryEra != "" {
+		if chainDb, err = rawdb.NewEraHistoryDatabase(chainDb, config.HistoryEra, func() types.TrieHasher { return trie.NewStackTrie(nil) }); err != nil {
+			return nil, err
+		}
+	}
+	// Drop the legacy bloombits index, superseded by the log index.
+	if deleted, err := rawdb.DeleteBloomBitsDb(chainDb); err != nil {
+		log.Warn("Failed to delete legacy bloombits index", "err", err)
+	} else if deleted > 0 {
+		log.Info("Deleted legacy bloombits index", "entries", deleted)
+	}
+	if config.HistoryExpiry > 0 && config.HistoryEra == "" && config.TransactionHistory == 0 {
+		log.Warn("Transactions in the pruned history can't be indexed", "boundary", config.HistoryExpiry)
+	}
 	scheme, err := rawdb.ParseStateScheme(config.StateScheme, chainDb)
 	if err != nil {
 		return nil, err
@@ -149,19 +166,18 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		networkID = chainConfig.ChainID.Uint64()
 	}
 	eth := &Ethereum{
-		config:            config,
-		chainDb:           chainDb,
-		eventMux:          stack.EventMux(),
-		accountManager:    stack.AccountManager(),
-		engine:            engine,
-		closeBloomHandler: make(chan struct{}),
-		networkID:         networkID,
-		gasPrice:          config.Miner.GasPrice,
-		bloomRequests:     make(chan chan *bloombits.Retrieval),
-		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
-		p2pServer:         stack.Server(),
-		discmix:           enode.NewFairMix(0),
-		shutdownTracker:   shutdowncheck.NewShutdownTracker(chainDb),
+		config:          config,
+		chainDb:         chainDb,
+		eventMux:        stack.EventMux(),
+		accountManager:  stack.AccountManager(),
+		engine:          engine,
+		networkID:       networkID,
+		gasPrice:        config.Miner.GasPrice,
+		logIndexer:      core.NewLogIndexer(chainDb, params.LogIndexBlocks, params.LogIndexConfirms, config.LogHistory),
+		p2pServer:       stack.Server(),
+		discmix:         enode.NewFairMix(0),
+		shutdownTracker: shutdowncheck.NewShutdownTracker(chainDb),
+		responseCache:   stack.ResponseCache(),
 	}
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
@@ -183,6 +199,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	var (
 		vmConfig = vm.Config{
 			EnablePreimageRecording: config.EnablePreimageRecording,
+			ParallelExecution:       config.ParallelExecution,
 		}
 		cacheConfig = &core.CacheConfig{
 			TrieCleanLimit:      config.TrieCleanCache,
@@ -193,7 +210,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
 			StateHistory:        config.StateHistory,
+			StateHistoryIndex:   config.StateHistoryIndex,
 			StateScheme:         scheme,
+			HistoryExpiry:       config.HistoryExpiry,
 		}
 	)
 	if config.VMTrace != "" {
@@ -219,7 +238,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
-	eth.bloomIndexer.Start(eth.blockchain)
+	eth.logIndexer.Start(eth.blockchain)
 
 	if config.BlobPool.Datadir != "" {
 		config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
@@ -235,6 +254,11 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	if config.TxPolicy != nil {
+		if err := eth.txPool.SetPolicy(config.TxPolicy); err != nil {
+			return nil, fmt.Errorf("invalid txpool policy: %w", err)
+		}
+	}
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
 	if eth.handler, err = newHandler(&handlerConfig{
@@ -253,6 +277,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 
 	eth.miner = miner.New(eth, config.Miner, eth.engine)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
+	if err := eth.miner.SetOrderingPolicy(config.Miner.OrderingPolicy); err != nil {
+		return nil, err
+	}
 
 	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
 	if eth.APIBackend.allowUnprotectedTxs {
@@ -299,11 +326,24 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append the goat specific APIs
+	if s.blockchain.Config().Goat != nil {
+		apis = append(apis, rpc.API{
+			Namespace:   "goat",
+			Service:     NewGoatAPI(s),
+			Description: "GOAT Network validator rewards and gas revenue",
+		})
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
-			Namespace: "miner",
-			Service:   NewMinerAPI(s),
+			Namespace:   "miner",
+			Service:     NewMinerAPI(s),
+			Description: "Block building configuration",
+		}, {
+			Namespace: "eth",
+			Service:   NewBundleAPI(s),
 		}, {
 			Namespace: "eth",
 			Service:   downloader.NewDownloaderAPI(s.handler.downloader, s.blockchain, s.eventMux),
@@ -314,8 +354,9 @@ func (s *Ethereum) APIs() []rpc.API {
 			Namespace: "debug",
 			Service:   NewDebugAPI(s),
 		}, {
-			Namespace: "net",
-			Service:   s.netRPCService,
+			Namespace:   "net",
+			Service:     s.netRPCService,
+			Description: "Network identity and peer count",
 		},
 	}...)
 }
@@ -337,7 +378,7 @@ func (s *Ethereum) Downloader() *downloader.Downloader { return s.handler.downlo
 func (s *Ethereum) Synced() bool                       { return s.handler.synced.Load() }
 func (s *Ethereum) SetSynced()                         { s.handler.enableSyncedFeatures() }
 func (s *Ethereum) ArchiveMode() bool                  { return s.config.NoPruning }
-func (s *Ethereum) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
+func (s *Ethereum) LogIndexer() *core.ChainIndexer     { return s.logIndexer }
 
 // Protocols returns all the currently configured
 // network protocols to start.
@@ -354,17 +395,37 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 func (s *Ethereum) Start() error {
 	s.setupDiscovery()
 
-	// Start the bloom bits servicing goroutines
-	s.startBloomHandlers(params.BloomBitsBlocks)
-
 	// Regularly update shutdown marker
 	s.shutdownTracker.Start()
 
 	// Start the networking layer
 	s.handler.Start(s.p2pServer.MaxPeers)
+
+	// Drop the cached RPC responses of reorged blocks
+	if s.responseCache != nil {
+		heads := make(chan core.ChainHeadEvent, 16)
+		s.responseCacheSub = s.blockchain.SubscribeChainHeadEvent(heads)
+		s.wg.Add(1)
+		go s.verifyResponseCache(heads)
+	}
 	return nil
 }
 
+// verifyResponseCache purges the RPC response cache whenever a reorg replaces
+// any of the blocks its responses are derived from.
+func (s *Ethereum) verifyResponseCache(heads chan core.ChainHeadEvent) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-heads:
+			s.responseCache.Verify(s.blockchain.GetCanonicalHash)
+		case <-s.responseCacheSub.Err():
+			return
+		}
+	}
+}
+
 func (s *Ethereum) setupDiscovery() error {
 	eth.StartENRUpdater(s.blockchain, s.p2pServer.LocalNode())
 
@@ -403,10 +464,13 @@ func (s *Ethereum) Stop() error {
 	// Stop all the peer-related stuff first.
 	s.discmix.Close()
 	s.handler.Stop()
+	if s.responseCacheSub != nil {
+		s.responseCacheSub.Unsubscribe()
+		s.wg.Wait()
+	}
 
 	// Then stop everything else.
-	s.bloomIndexer.Close()
-	close(s.closeBloomHandler)
+	s.logIndexer.Close()
 	s.txPool.Close()
 	s.blockchain.Stop()
 	s.engine.Close()
@@ -51,6 +51,7 @@ func Register(stack *node.Node, backend *eth.Ethereum) error {
 			Namespace:     "engine",
 			Service:       NewConsensusAPI(backend),
 			Authenticated: true,
+			Description:   "Engine API between the consensus and the execution layer",
 		},
 	})
 	return nil
@@ -89,6 +90,7 @@ var caps = []string{
 	"engine_forkchoiceUpdatedWithWitnessV2",
 	"engine_forkchoiceUpdatedWithWitnessV3",
 	"engine_exchangeTransitionConfigurationV1",
+	"engine_exchangeGoatConfigV1",
 	"engine_getPayloadV1",
 	"engine_getPayloadV2",
 	"engine_getPayloadV3",
@@ -151,6 +153,10 @@ type ConsensusAPI struct {
 	lastNewPayloadUpdate time.Time
 	lastNewPayloadLock   sync.Mutex
 
+	lastGoatConfigUpdate time.Time
+	goatConfigErr        error // Error of the last goat config handshake, payload building is refused if set
+	goatConfigLock       sync.Mutex
+
 	forkchoiceLock sync.Mutex // Lock for the forkChoiceUpdated method
 	newPayloadLock sync.Mutex // Lock for the NewPayload method
 }
@@ -436,6 +442,9 @@ func (api *ConsensusAPI) forkchoiceUpdated(update engine.ForkchoiceStateV1, payl
 	// will replace it arbitrarily many times in between.
 	if payloadAttributes != nil {
 		// goat
+		if err := api.checkGoatConfig(); err != nil {
+			return valid(nil), engine.GenericServerError.With(err)
+		}
 		if d := len(payloadAttributes.GoatTxs); d > params.GoatTxLimitPerBlock {
 			return engine.STATUS_INVALID, fmt.Errorf("goat tx size too large(size %d)", d)
 		}
@@ -548,6 +557,10 @@ func (api *ConsensusAPI) GetPayloadV4(payloadID engine.PayloadID) (*engine.Execu
 
 func (api *ConsensusAPI) getPayload(payloadID engine.PayloadID, full bool) (*engine.ExecutionPayloadEnvelope, error) {
 	log.Trace("Engine API request received", "method", "GetPayload", "id", payloadID)
+	// goat
+	if err := api.checkGoatConfig(); err != nil {
+		return nil, engine.GenericServerError.With(err)
+	}
 	data := api.localBlocks.get(payloadID, full)
 	if data == nil {
 		return nil, engine.UnknownPayload
@@ -1137,12 +1150,14 @@ func (api *ConsensusAPI) heartbeat() {
 		return
 	}
 
-	var offlineLogged time.Time
+	var offlineLogged, goatLogged time.Time
 
 	for {
 		// Sleep a bit and retrieve the last known consensus updates
 		time.Sleep(5 * time.Second)
 
+		api.logGoatConfig(&goatLogged)
+
 		api.lastTransitionLock.Lock()
 		lastTransitionUpdate := api.lastTransitionUpdate
 		api.lastTransitionLock.Unlock()
@@ -2,10 +2,84 @@ package catalyst
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 )
 
 func (api *ConsensusAPI) GetChainConfig(_ context.Context) (*params.ChainConfig, error) {
 	return api.eth.BlockChain().Config(), nil
 }
+
+// ExchangeGoatConfigV1 checks the goat config of the consensus client against
+// the local one. Payload building is refused until the configs match again if
+// there is a mismatch.
+func (api *ConsensusAPI) ExchangeGoatConfigV1(config engine.GoatConfigV1) (*engine.GoatConfigV1, error) {
+	log.Trace("Engine API request received", "method", "ExchangeGoatConfig", "genesis", config.GenesisHash, "digest", config.Digest)
+	chain := api.eth.BlockChain()
+	if chain.Config().Goat == nil {
+		return nil, errors.New("not a goat chain")
+	}
+	if config.ChainID == nil {
+		return nil, errors.New("invalid chain id")
+	}
+	local := engine.NewGoatConfigV1(chain.Config().ChainID, chain.Genesis().Hash(), chain.GoatConfigDigest())
+
+	var err error
+	switch {
+	case local.ChainID.ToInt().Cmp(config.ChainID.ToInt()) != 0:
+		err = fmt.Errorf("chain id mismatch: execution %v consensus %v", local.ChainID, config.ChainID)
+	case local.GenesisHash != config.GenesisHash:
+		err = fmt.Errorf("genesis mismatch: execution %x consensus %x", local.GenesisHash, config.GenesisHash)
+	case local.Digest != config.Digest:
+		err = fmt.Errorf("goat config digest mismatch: execution %x consensus %x", local.Digest, config.Digest)
+	}
+	api.goatConfigLock.Lock()
+	api.lastGoatConfigUpdate = time.Now()
+	api.goatConfigErr = err
+	api.goatConfigLock.Unlock()
+
+	if err != nil {
+		log.Error("Goat config mismatch between execution and consensus clients, payload building is disabled", "err", err)
+		return nil, err
+	}
+	return local, nil
+}
+
+// checkGoatConfig returns the error of the last goat config handshake. Payloads
+// are still built if the consensus client never did the handshake, as older
+// clients don't support it.
+func (api *ConsensusAPI) checkGoatConfig() error {
+	if api.eth.BlockChain().Config().Goat == nil {
+		return nil
+	}
+	api.goatConfigLock.Lock()
+	defer api.goatConfigLock.Unlock()
+
+	return api.goatConfigErr
+}
+
+// logGoatConfig warns the user if the last goat config handshake failed or
+// the consensus client never did it.
+func (api *ConsensusAPI) logGoatConfig(logged *time.Time) {
+	if api.eth.BlockChain().Config().Goat == nil || time.Since(*logged) <= beaconUpdateWarnFrequency {
+		return
+	}
+	api.goatConfigLock.Lock()
+	lastUpdate, err := api.lastGoatConfigUpdate, api.goatConfigErr
+	api.goatConfigLock.Unlock()
+
+	switch {
+	case err != nil:
+		log.Error("Goat config mismatch between execution and consensus clients, payload building is disabled", "err", err)
+	case lastUpdate.IsZero():
+		log.Warn("Consensus client never exchanged the goat config, building payloads without checking it")
+	default:
+		return
+	}
+	*logged = time.Now()
+}
@@ -0,0 +1,82 @@
+package catalyst
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestExchangeGoatConfig(t *testing.T) {
+	config := *params.AllGoatDebugChainConfig
+	genesis := &core.Genesis{
+		Config:  &config,
+		Alloc:   types.GenesisAlloc{testAddr: {Balance: testBalance}},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	n, ethservice := startEthService(t, genesis, nil)
+	defer n.Close()
+
+	var (
+		api   = newConsensusAPIWithoutHeartbeat(ethservice)
+		chain = ethservice.BlockChain()
+		local = engine.NewGoatConfigV1(config.ChainID, chain.Genesis().Hash(), chain.GoatConfigDigest())
+	)
+	buildPayload := func() error {
+		parent := chain.CurrentBlock()
+		fcState := engine.ForkchoiceStateV1{HeadBlockHash: parent.Hash()}
+		attrs := &engine.PayloadAttributes{
+			Timestamp:   parent.Time + 1,
+			Withdrawals: []*types.Withdrawal{},
+			BeaconRoot:  &common.Hash{0x01},
+		}
+		_, err := api.ForkchoiceUpdatedV3(fcState, attrs)
+		return err
+	}
+
+	// Consensus clients without the handshake can still build payloads
+	if err := buildPayload(); err != nil {
+		t.Fatalf("failed to build payload without goat config: %v", err)
+	}
+
+	if res, err := api.ExchangeGoatConfigV1(*local); err != nil {
+		t.Fatalf("failed to exchange goat config: %v", err)
+	} else if *res != *local {
+		t.Fatalf("goat config mismatch: have %v want %v", res, local)
+	}
+	if err := buildPayload(); err != nil {
+		t.Fatalf("failed to build payload: %v", err)
+	}
+
+	// A mismatched digest disables payload building
+	remote := *local
+	remote.Digest = common.Hash{0x01}
+	if _, err := api.ExchangeGoatConfigV1(remote); err == nil {
+		t.Fatal("expected goat config digest mismatch")
+	}
+	var engineErr *engine.EngineAPIError
+	if err := buildPayload(); !errors.As(err, &engineErr) || engineErr.ErrorCode() != engine.GenericServerError.ErrorCode() {
+		t.Fatalf("expected payload building to be refused, have %v", err)
+	}
+	if _, err := api.GetPayloadV3(engine.PayloadID{0x03}); !errors.As(err, &engineErr) || engineErr.ErrorCode() != engine.GenericServerError.ErrorCode() {
+		t.Fatalf("expected payload retrieval to be refused, have %v", err)
+	}
+	remote = *local
+	remote.GenesisHash = common.Hash{0x01}
+	if _, err := api.ExchangeGoatConfigV1(remote); err == nil {
+		t.Fatal("expected genesis mismatch")
+	}
+
+	// The matched config enables payload building again
+	if _, err := api.ExchangeGoatConfigV1(*local); err != nil {
+		t.Fatalf("failed to exchange goat config: %v", err)
+	}
+	if err := buildPayload(); err != nil {
+		t.Fatalf("failed to build payload: %v", err)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -94,7 +95,14 @@ type Config struct {
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 
 	TransactionHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
+	LogHistory         uint64 `toml:",omitempty"` // The maximum number of blocks from head whose log indices are reserved.
 	StateHistory       uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
+	StateHistoryIndex  bool   `toml:",omitempty"` // Whether to index the state histories for serving historical state (path scheme only).
+
+	// History expiry options, the ancient block bodies and receipts before the
+	// boundary are pruned and optionally served from a directory of Era1 archives.
+	HistoryExpiry uint64 `toml:",omitempty"` // Block number before which ancient bodies and receipts are pruned (0 = keep all).
+	HistoryEra    string `toml:",omitempty"` // Directory of the Era1 archives serving the pruned history.
 
 	// State scheme represents the scheme used to store ethereum states and trie
 	// nodes on top. It can be 'hash', 'path', or none which means use the scheme
@@ -121,12 +129,21 @@ type Config struct {
 	// This is the number of blocks for which logs will be cached in the filter system.
 	FilterLogCacheSize int
 
+	// LogRangeLimit is the maximum number of blocks searched by a log query,
+	// longer ranges have to be paged through. 0, the default, means unlimited.
+	LogRangeLimit uint64
+
+	// LogQueryLimit is the maximum number of logs returned by a log query,
+	// more results have to be paged through. 0, the default, means unlimited.
+	LogQueryLimit int
+
 	// Mining options
 	Miner miner.Config
 
 	// Transaction pool options
 	TxPool   legacypool.Config
 	BlobPool blobpool.Config
+	TxPolicy *txpool.Policy `toml:",omitempty"` // Admission policy of the new transactions
 
 	// Gas Price Oracle options
 	GPO gasprice.Config
@@ -138,6 +155,9 @@ type Config struct {
 	VMTrace           string
 	VMTraceJsonConfig string
 
+	// Enables optimistic parallel execution of the transactions in blocks
+	ParallelExecution bool
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"sync"
 	"time"
@@ -39,6 +40,12 @@ var (
 	errInvalidBlockRange      = errors.New("invalid block range params")
 	errPendingLogsUnsupported = errors.New("pending logs are not supported")
 	errExceedMaxTopics        = errors.New("exceed max topics")
+	errExceedMaxBlockRange    = errors.New("exceed max block range, use eth_getLogsPage to page")
+	errExceedLogQueryLimit    = errors.New("exceed max results, use eth_getLogsPage to page")
+	errInvalidLogCursor       = errors.New("invalid log cursor")
+	errPagedLogQuery          = errors.New("limit and cursor are only supported by eth_getLogsPage")
+	errInvalidEventID         = errors.New("invalid last event ID")
+	errResumeTooOld           = errors.New("resume point too old")
 )
 
 // The maximum number of topic criteria allowed, vm.LOG4 - vm.LOG0
@@ -47,6 +54,10 @@ const maxTopics = 4
 // The maximum number of allowed topics within a topic criteria
 const maxSubTopics = 1000
 
+// The maximum number of blocks replayed to a subscription resumed after its last
+// event ID
+const maxResumeBlocks = 1024
+
 // filter is a helper struct that holds meta information over the filter type
 // and associated subscription in the event system.
 type filter struct {
@@ -224,12 +235,25 @@ func (api *FilterAPI) NewBlockFilter() rpc.ID {
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
+// The notifications are tagged with the block number as event ID. A subscription
+// resumed after an event ID first replays the canonical headers following it.
 func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
-
+	var resume *uint64
+	if id, ok := rpc.LastEventID(ctx); ok {
+		number, err := hexutil.DecodeUint64(id)
+		if err != nil {
+			return nil, errInvalidEventID
+		}
+		if err := api.checkResume(number); err != nil {
+			return nil, err
+		}
+		number++
+		resume = &number
+	}
 	rpcSub := notifier.CreateSubscription()
 
 	go func() {
@@ -237,10 +261,47 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 		headersSub := api.events.SubscribeNewHeads(headers)
 		defer headersSub.Unsubscribe()
 
+		notify := func(h *types.Header) {
+			notifier.NotifyWithEventID(rpcSub.ID, hexutil.EncodeUint64(h.Number.Uint64()), h)
+		}
+		// Replay the missed headers, holding back the new ones meanwhile
+		var (
+			replayed = make(map[common.Hash]bool)
+			pending  []*types.Header
+			done     chan struct{}
+		)
+		if resume != nil {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				for number := *resume; ctx.Err() == nil; number++ {
+					h, _ := api.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+					if h == nil {
+						return
+					}
+					replayed[h.Hash()] = true
+					notify(h)
+				}
+			}()
+		}
 		for {
 			select {
 			case h := <-headers:
-				notifier.Notify(rpcSub.ID, h)
+				if done != nil {
+					pending = append(pending, h)
+				} else {
+					notify(h)
+				}
+			case <-done:
+				for _, h := range pending {
+					if !replayed[h.Hash()] {
+						notify(h)
+					}
+				}
+				done, pending = nil, nil
 			case <-rpcSub.Err():
 				return
 			}
@@ -251,11 +312,24 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
+// The notifications of new logs are tagged with the log cursor following them as event
+// ID. A subscription resumed after an event ID first replays the matching canonical
+// logs from the cursor on.
 func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
+	var resume *LogCursor
+	if id, ok := rpc.LastEventID(ctx); ok {
+		resume = new(LogCursor)
+		if err := resume.UnmarshalText([]byte(id)); err != nil {
+			return nil, errInvalidEventID
+		}
+		if err := api.checkResume(resume.BlockNumber); err != nil {
+			return nil, err
+		}
+	}
 
 	var (
 		rpcSub      = notifier.CreateSubscription()
@@ -269,13 +343,53 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 
 	go func() {
 		defer logsSub.Unsubscribe()
+
+		notify := func(log *types.Log) {
+			if log.Removed {
+				notifier.Notify(rpcSub.ID, log)
+				return
+			}
+			next, _ := logCursorAt(log).next().MarshalText()
+			notifier.NotifyWithEventID(rpcSub.ID, string(next), log)
+		}
+		// Replay the missed logs, holding back the new ones meanwhile
+		var (
+			replayed = make(map[common.Hash]bool)
+			pending  [][]*types.Log
+			done     chan struct{}
+		)
+		if resume != nil {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				api.replayLogs(ctx, crit, resume, func(log *types.Log) {
+					replayed[log.BlockHash] = true
+					notify(log)
+				})
+			}()
+		}
 		for {
 			select {
 			case logs := <-matchedLogs:
+				if done != nil {
+					pending = append(pending, logs)
+					continue
+				}
 				for _, log := range logs {
-					log := log
-					notifier.Notify(rpcSub.ID, &log)
+					notify(log)
+				}
+			case <-done:
+				for _, logs := range pending {
+					for _, log := range logs {
+						if log.Removed || !replayed[log.BlockHash] {
+							notify(log)
+						}
+					}
 				}
+				done, pending = nil, nil
 			case <-rpcSub.Err(): // client send an unsubscribe request
 				return
 			}
@@ -285,6 +399,31 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 	return rpcSub, nil
 }
 
+// checkResume checks a subscription can be resumed after the given block.
+func (api *FilterAPI) checkResume(number uint64) error {
+	if head := api.sys.backend.CurrentHeader().Number.Uint64(); head > number && head-number > maxResumeBlocks {
+		return errResumeTooOld
+	}
+	return nil
+}
+
+// replayLogs sends the matching canonical logs from the cursor up to the current
+// head, paging through them within the configured query limits.
+func (api *FilterAPI) replayLogs(ctx context.Context, crit FilterCriteria, cursor *LogCursor, send func(*types.Log)) {
+	head := api.sys.backend.CurrentHeader().Number.Int64()
+	for cursor != nil && int64(cursor.BlockNumber) <= head {
+		filter := api.sys.NewRangeFilter(int64(cursor.BlockNumber), head, crit.Addresses, crit.Topics)
+		logs, next, err := filter.Page(ctx, 0, cursor)
+		if err != nil {
+			return
+		}
+		for _, log := range logs {
+			send(log)
+		}
+		cursor = next
+	}
+}
+
 // FilterCriteria represents a request to create a new filter.
 // Same as ethereum.FilterQuery but with UnmarshalJSON() method.
 type FilterCriteria ethereum.FilterQuery
@@ -332,30 +471,21 @@ func (api *FilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 	return logsSub.ID, nil
 }
 
+// LogPage is a page of the logs matching a paged log query, along with the
+// cursor to resume the query from. The cursor is omitted on the last page.
+type LogPage struct {
+	Logs   []*types.Log `json:"logs"`
+	Cursor *LogCursor   `json:"cursor,omitempty"`
+}
+
 // GetLogs returns logs matching the given argument that are stored within the state.
 func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
-	if len(crit.Topics) > maxTopics {
-		return nil, errExceedMaxTopics
+	if crit.Limit != 0 || crit.Cursor != "" {
+		return nil, errPagedLogQuery
 	}
-	var filter *Filter
-	if crit.BlockHash != nil {
-		// Block filter requested, construct a single-shot filter
-		filter = api.sys.NewBlockFilter(*crit.BlockHash, crit.Addresses, crit.Topics)
-	} else {
-		// Convert the RPC block numbers into internal representations
-		begin := rpc.LatestBlockNumber.Int64()
-		if crit.FromBlock != nil {
-			begin = crit.FromBlock.Int64()
-		}
-		end := rpc.LatestBlockNumber.Int64()
-		if crit.ToBlock != nil {
-			end = crit.ToBlock.Int64()
-		}
-		if begin > 0 && end > 0 && begin > end {
-			return nil, errInvalidBlockRange
-		}
-		// Construct the range filter
-		filter = api.sys.NewRangeFilter(begin, end, crit.Addresses, crit.Topics)
+	filter, err := api.logFilter(crit)
+	if err != nil {
+		return nil, err
 	}
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
@@ -365,6 +495,52 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 	return returnLogs(logs), err
 }
 
+// GetLogsPage returns at most limit logs matching the given argument, resuming
+// from the cursor if given, along with the cursor of the next page.
+func (api *FilterAPI) GetLogsPage(ctx context.Context, crit FilterCriteria) (*LogPage, error) {
+	filter, err := api.logFilter(crit)
+	if err != nil {
+		return nil, err
+	}
+	var cursor *LogCursor
+	if crit.Cursor != "" {
+		cursor = new(LogCursor)
+		if err := cursor.UnmarshalText([]byte(crit.Cursor)); err != nil {
+			return nil, err
+		}
+	}
+	logs, next, err := filter.Page(ctx, int(min(crit.Limit, math.MaxInt32)), cursor)
+	if err != nil {
+		return nil, err
+	}
+	return &LogPage{Logs: returnLogs(logs), Cursor: next}, nil
+}
+
+// logFilter constructs the one-shot filter of a log query.
+func (api *FilterAPI) logFilter(crit FilterCriteria) (*Filter, error) {
+	if len(crit.Topics) > maxTopics {
+		return nil, errExceedMaxTopics
+	}
+	if crit.BlockHash != nil {
+		// Block filter requested, construct a single-shot filter
+		return api.sys.NewBlockFilter(*crit.BlockHash, crit.Addresses, crit.Topics), nil
+	}
+	// Convert the RPC block numbers into internal representations
+	begin := rpc.LatestBlockNumber.Int64()
+	if crit.FromBlock != nil {
+		begin = crit.FromBlock.Int64()
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if crit.ToBlock != nil {
+		end = crit.ToBlock.Int64()
+	}
+	if begin > 0 && end > 0 && begin > end {
+		return nil, errInvalidBlockRange
+	}
+	// Construct the range filter
+	return api.sys.NewRangeFilter(begin, end, crit.Addresses, crit.Topics), nil
+}
+
 // UninstallFilter removes the filter with the given filter id.
 func (api *FilterAPI) UninstallFilter(id rpc.ID) bool {
 	api.filtersMu.Lock()
@@ -493,6 +669,8 @@ func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 		ToBlock   *rpc.BlockNumber `json:"toBlock"`
 		Addresses interface{}      `json:"address"`
 		Topics    []interface{}    `json:"topics"`
+		Limit     hexutil.Uint64   `json:"limit"`
+		Cursor    string           `json:"cursor"`
 	}
 
 	var raw input
@@ -516,6 +694,13 @@ func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 		}
 	}
 
+	if raw.Cursor != "" {
+		if err := new(LogCursor).UnmarshalText([]byte(raw.Cursor)); err != nil {
+			return err
+		}
+	}
+	args.Limit, args.Cursor = uint64(raw.Limit), raw.Cursor
+
 	args.Addresses = []common.Address{}
 
 	if raw.Addresses != nil {
@@ -182,4 +182,25 @@ func TestUnmarshalJSONNewFilterArgs(t *testing.T) {
 	if len(test7.Topics[2]) != 0 {
 		t.Fatalf("expected 0 topics, got %d topics", len(test7.Topics[2]))
 	}
+
+	// test paging
+	var test8 FilterCriteria
+	vector = `{"fromBlock": "0x1", "limit": "0x10", "cursor": "0x000000000000002a0000000100000003"}`
+	if err := json.Unmarshal([]byte(vector), &test8); err != nil {
+		t.Fatal(err)
+	}
+	if test8.Limit != 16 {
+		t.Fatalf("expected limit 16, got %d", test8.Limit)
+	}
+	var cursor LogCursor
+	if err := cursor.UnmarshalText([]byte(test8.Cursor)); err != nil {
+		t.Fatal(err)
+	}
+	if cursor != (LogCursor{BlockNumber: 42, TxIndex: 1, LogIndex: 3}) {
+		t.Fatalf("invalid cursor, got %+v", cursor)
+	}
+	var test9 FilterCriteria
+	if err := json.Unmarshal([]byte(`{"cursor": "0x2a"}`), &test9); err != errInvalidLogCursor {
+		t.Fatalf("expected %v, got %v", errInvalidLogCursor, err)
+	}
 }
@@ -18,12 +18,14 @@ package filters
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"math/big"
 	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -38,36 +40,36 @@ type Filter struct {
 	block      *common.Hash // Block hash if filtering a single block
 	begin, end int64        // Range interval if filtering multiple blocks
 
-	matcher *bloombits.Matcher
+	clauses [][][]byte // Log index keys of the filter clauses, any key of every clause must match
 }
 
-// NewRangeFilter creates a new filter which uses a bloom filter on blocks to
+// NewRangeFilter creates a new filter which uses the log index on blocks to
 // figure out whether a particular block is interesting or not.
 func (sys *FilterSystem) NewRangeFilter(begin, end int64, addresses []common.Address, topics [][]common.Hash) *Filter {
-	// Flatten the address and topic filter clauses into a single bloombits filter
-	// system. Since the bloombits are not positional, nil topics are permitted,
-	// which get flattened into a nil byte slice.
-	var filters [][][]byte
+	// Flatten the address and topic filter clauses into the log index keys.
+	// The wildcard topics don't constrain the blocks, so they are skipped.
+	var clauses [][][]byte
 	if len(addresses) > 0 {
-		filter := make([][]byte, len(addresses))
+		clause := make([][]byte, len(addresses))
 		for i, address := range addresses {
-			filter[i] = address.Bytes()
+			clause[i] = rawdb.LogIndexAddressKey(address)
 		}
-		filters = append(filters, filter)
+		clauses = append(clauses, clause)
 	}
-	for _, topicList := range topics {
-		filter := make([][]byte, len(topicList))
+	for position, topicList := range topics {
+		if len(topicList) == 0 {
+			continue
+		}
+		clause := make([][]byte, len(topicList))
 		for i, topic := range topicList {
-			filter[i] = topic.Bytes()
+			clause[i] = rawdb.LogIndexTopicKey(position, topic)
 		}
-		filters = append(filters, filter)
+		clauses = append(clauses, clause)
 	}
-	size, _ := sys.backend.BloomStatus()
-
 	// Create a generic filter and convert it into a range filter
 	filter := newFilter(sys, addresses, topics)
 
-	filter.matcher = bloombits.NewMatcher(size, filters)
+	filter.clauses = clauses
 	filter.begin = begin
 	filter.end = end
 
@@ -93,24 +95,159 @@ func newFilter(sys *FilterSystem, addresses []common.Address, topics [][]common.
 	}
 }
 
+// LogCursor is the position of a log within the chain, used to resume a paged
+// log query. It's encoded as an opaque hex string on the RPC interface.
+type LogCursor struct {
+	BlockNumber uint64
+	TxIndex     uint32
+	LogIndex    uint32
+}
+
+// logCursorLength is the length of an encoded log cursor.
+const logCursorLength = 16
+
+// MarshalText implements encoding.TextMarshaler.
+func (c LogCursor) MarshalText() ([]byte, error) {
+	enc := make([]byte, logCursorLength)
+	binary.BigEndian.PutUint64(enc, c.BlockNumber)
+	binary.BigEndian.PutUint32(enc[8:], c.TxIndex)
+	binary.BigEndian.PutUint32(enc[12:], c.LogIndex)
+	return hexutil.Bytes(enc).MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *LogCursor) UnmarshalText(input []byte) error {
+	var enc hexutil.Bytes
+	if err := enc.UnmarshalText(input); err != nil || len(enc) != logCursorLength {
+		return errInvalidLogCursor
+	}
+	c.BlockNumber = binary.BigEndian.Uint64(enc)
+	c.TxIndex = binary.BigEndian.Uint32(enc[8:])
+	c.LogIndex = binary.BigEndian.Uint32(enc[12:])
+	return nil
+}
+
+// logCursorAt returns the cursor pointing to the given log.
+func logCursorAt(log *types.Log) *LogCursor {
+	return &LogCursor{BlockNumber: log.BlockNumber, TxIndex: uint32(log.TxIndex), LogIndex: uint32(log.Index)}
+}
+
+// next returns the cursor following the one of a log.
+func (c *LogCursor) next() *LogCursor {
+	return &LogCursor{BlockNumber: c.BlockNumber, TxIndex: c.TxIndex, LogIndex: c.LogIndex + 1}
+}
+
+// precedes reports whether the log is positioned before the cursor.
+func (c *LogCursor) precedes(log *types.Log) bool {
+	if log.BlockNumber != c.BlockNumber {
+		return log.BlockNumber < c.BlockNumber
+	}
+	if log.TxIndex != uint(c.TxIndex) {
+		return log.TxIndex < uint(c.TxIndex)
+	}
+	return log.Index < uint(c.LogIndex)
+}
+
 // Logs searches the blockchain for matching log entries, returning all from the
 // first block that contains matches, updating the start of the filter accordingly.
+// The configured range and result limits of the filter system are enforced.
 func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	// If we're doing singleton block filtering, execute and return
 	if f.block != nil {
-		header, err := f.sys.backend.HeaderByHash(ctx, *f.block)
+		logs, err := f.singleBlockLogs(ctx)
 		if err != nil {
 			return nil, err
 		}
-		if header == nil {
-			return nil, errors.New("unknown block")
+		if limit := f.sys.cfg.LogQueryLimit; limit != 0 && len(logs) > limit {
+			return nil, errExceedLogQueryLimit
 		}
-		return f.blockLogs(ctx, header)
+		return logs, nil
+	}
+	if err := f.resolveRange(ctx); err != nil {
+		return nil, err
 	}
+	if limit := f.sys.cfg.LogRangeLimit; limit != 0 && f.end >= f.begin && uint64(f.end-f.begin) >= limit {
+		return nil, errExceedMaxBlockRange
+	}
+	logs, next, err := f.collectLogs(ctx, f.sys.cfg.LogQueryLimit, nil)
+	if err != nil {
+		return logs, err
+	}
+	if next != nil {
+		return nil, errExceedLogQueryLimit
+	}
+	return logs, nil
+}
 
+// Page searches the blockchain for at most limit matching log entries (0 means
+// up to the configured result limit), resuming from the cursor if given. The
+// returned cursor points to the position to resume from, or is nil if all the
+// matching logs were returned. Range queries exceeding the configured range
+// limit are split across pages instead of being rejected.
+func (f *Filter) Page(ctx context.Context, limit int, cursor *LogCursor) ([]*types.Log, *LogCursor, error) {
+	if maxLimit := f.sys.cfg.LogQueryLimit; maxLimit != 0 && (limit == 0 || limit > maxLimit) {
+		limit = maxLimit
+	}
+	// If we're doing singleton block filtering, page through its logs
+	if f.block != nil {
+		logs, err := f.singleBlockLogs(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cursor != nil {
+			if len(logs) > 0 && logs[0].BlockNumber != cursor.BlockNumber {
+				return nil, nil, errInvalidLogCursor
+			}
+			for len(logs) > 0 && cursor.precedes(logs[0]) {
+				logs = logs[1:]
+			}
+		}
+		if limit != 0 && len(logs) > limit {
+			return logs[:limit], logCursorAt(logs[limit]), nil
+		}
+		return logs, nil, nil
+	}
+	if err := f.resolveRange(ctx); err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		if cursor.BlockNumber < uint64(f.begin) || cursor.BlockNumber > uint64(f.end) {
+			return nil, nil, errInvalidLogCursor
+		}
+		f.begin = int64(cursor.BlockNumber)
+	}
+	// Cut the range at the range limit, resuming after it on the next page
+	var truncated bool
+	if maxRange := f.sys.cfg.LogRangeLimit; maxRange != 0 && f.end >= f.begin && uint64(f.end-f.begin) >= maxRange {
+		f.end, truncated = f.begin+int64(maxRange)-1, true
+	}
+	logs, next, err := f.collectLogs(ctx, limit, cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	if next == nil && truncated {
+		next = &LogCursor{BlockNumber: uint64(f.end) + 1}
+	}
+	return logs, next, nil
+}
+
+// singleBlockLogs returns the matching logs of the block the filter is bound to.
+func (f *Filter) singleBlockLogs(ctx context.Context) ([]*types.Log, error) {
+	header, err := f.sys.backend.HeaderByHash(ctx, *f.block)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("unknown block")
+	}
+	return f.blockLogs(ctx, header)
+}
+
+// resolveRange resolves the special block numbers of the range filter.
+func (f *Filter) resolveRange(ctx context.Context) error {
 	// Disallow pending logs.
 	if f.begin == rpc.PendingBlockNumber.Int64() || f.end == rpc.PendingBlockNumber.Int64() {
-		return nil, errPendingLogsUnsupported
+		return errPendingLogsUnsupported
 	}
 
 	resolveSpecial := func(number int64) (int64, error) {
@@ -142,20 +279,39 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	var err error
 	// range query need to resolve the special begin/end block number
 	if f.begin, err = resolveSpecial(f.begin); err != nil {
-		return nil, err
+		return err
 	}
 	if f.end, err = resolveSpecial(f.end); err != nil {
-		return nil, err
+		return err
 	}
+	return nil
+}
+
+// collectLogs gathers at most limit (0 means no limit) matching logs of the
+// resolved range, skipping the ones before the cursor. If more logs match, the
+// search is aborted and the cursor of the first one left out is returned.
+func (f *Filter) collectLogs(ctx context.Context, limit int, cursor *LogCursor) ([]*types.Log, *LogCursor, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	logChan, errChan := f.rangeLogsAsync(ctx)
 	var logs []*types.Log
 	for {
 		select {
 		case log := <-logChan:
+			if log == nil || (cursor != nil && cursor.precedes(log)) {
+				continue
+			}
+			if limit != 0 && len(logs) == limit {
+				// Abort the search and wait for the retrieval to exit
+				cancel()
+				for range errChan {
+				}
+				return logs, logCursorAt(log), nil
+			}
 			logs = append(logs, log)
 		case err := <-errChan:
-			return logs, err
+			return logs, nil, err
 		}
 	}
 }
@@ -174,17 +330,20 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 			close(logChan)
 		}()
 
-		// Gather all indexed logs, and finish with non indexed ones
+		// Gather the logs before the index tail and the indexed logs, and
+		// finish with non indexed ones
 		var (
-			end            = uint64(f.end)
-			size, sections = f.sys.backend.BloomStatus()
-			err            error
+			end                  = uint64(f.end)
+			size, sections, tail = f.sys.backend.LogIndexStatus()
 		)
-		if indexed := sections * size; indexed > uint64(f.begin) {
-			if indexed > end {
-				indexed = end + 1
+		if unindexed := tail * size; unindexed > uint64(f.begin) {
+			if err := f.unindexedLogs(ctx, min(unindexed-1, end), logChan); err != nil {
+				errChan <- err
+				return
 			}
-			if err = f.indexedLogs(ctx, indexed-1, logChan); err != nil {
+		}
+		if indexed := sections * size; indexed > uint64(f.begin) {
+			if err := f.indexedLogs(ctx, min(indexed-1, end), size, logChan); err != nil {
 				errChan <- err
 				return
 			}
@@ -201,33 +360,48 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 	return logChan, errChan
 }
 
-// indexedLogs returns the logs matching the filter criteria based on the bloom
-// bits indexed available locally or via the network.
-func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
-	// Create a matcher session and request servicing from the backend
-	matches := make(chan uint64, 64)
-
-	session, err := f.matcher.Start(ctx, uint64(f.begin), end, matches)
-	if err != nil {
-		return err
+// logIndexBatch is the number of log index sections resolved at once, which
+// bounds the memory used by the keys matching most of the blocks.
+const logIndexBatch = 64
+
+// indexedLogs returns the logs matching the filter criteria based on the log
+// index available locally. The sections skipped by the indexer due to missing
+// receipts are filtered without the index.
+func (f *Filter) indexedLogs(ctx context.Context, end uint64, size uint64, logChan chan *types.Log) error {
+	// Every block is a candidate if the criteria are not constrained at all,
+	// the header blooms are cheaper to check than the index.
+	if len(f.clauses) == 0 {
+		return f.unindexedLogs(ctx, end, logChan)
 	}
-	defer session.Close()
-
-	f.sys.backend.ServiceFilter(ctx, session)
-
-	for {
-		select {
-		case number, ok := <-matches:
-			// Abort if all matches have been fulfilled
-			if !ok {
-				err := session.Error()
-				if err == nil {
-					f.begin = int64(end) + 1
-				}
+	db := f.sys.backend.ChainDb()
+	for f.begin <= int64(end) {
+		var (
+			from = uint64(f.begin) / size
+			to   = min(from+logIndexBatch, end/size+1)
+		)
+		if !rawdb.HasLogIndexSection(db, from) {
+			if err := f.unindexedLogs(ctx, min((from+1)*size-1, end), logChan); err != nil {
 				return err
 			}
-			f.begin = int64(number) + 1
-
+			continue
+		}
+		for section := from + 1; section < to; section++ {
+			if !rawdb.HasLogIndexSection(db, section) {
+				to = section
+				break
+			}
+		}
+		numbers, err := f.indexMatches(from, to)
+		if err != nil {
+			return err
+		}
+		for _, number := range numbers {
+			if number < uint64(f.begin) {
+				continue
+			}
+			if number > end {
+				break
+			}
 			// Retrieve the suggested block and pull any truly matching logs
 			header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
 			if header == nil || err != nil {
@@ -238,13 +412,85 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *type
 				return err
 			}
 			for _, log := range found {
-				logChan <- log
+				select {
+				case logChan <- log:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			f.begin = int64(number) + 1
+		}
+		f.begin = int64(min(to*size-1, end)) + 1
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexMatches returns the ascending numbers of the blocks within the log index
+// sections [from, to) which contain the logs matching every filter clause.
+func (f *Filter) indexMatches(from, to uint64) ([]uint64, error) {
+	var matches []uint64
+	for i, clause := range f.clauses {
+		var union []uint64
+		for _, key := range clause {
+			numbers, err := rawdb.ReadLogIndex(f.sys.backend.ChainDb(), key, from, to)
+			if err != nil {
+				return nil, err
 			}
+			union = mergeNumbers(union, numbers)
+		}
+		if i == 0 {
+			matches = union
+		} else {
+			matches = intersectNumbers(matches, union)
+		}
+		if len(matches) == 0 {
+			return nil, nil
+		}
+	}
+	return matches, nil
+}
+
+// mergeNumbers returns the union of two ascending number lists.
+func mergeNumbers(a, b []uint64) []uint64 {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	merged := make([]uint64, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case a[0] > b[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
 
-		case <-ctx.Done():
-			return ctx.Err()
+// intersectNumbers returns the intersection of two ascending number lists.
+func intersectNumbers(a, b []uint64) []uint64 {
+	var common []uint64
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			common, a, b = append(common, a[0]), a[1:], b[1:]
 		}
 	}
+	return common
 }
 
 // unindexedLogs returns the logs matching the filter criteria based on raw block
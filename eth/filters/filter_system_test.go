@@ -17,19 +17,23 @@
 package filters
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"math/big"
-	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -37,11 +41,13 @@ import (
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/triedb"
 )
 
 type testBackend struct {
 	db              ethdb.Database
 	sections        uint64
+	sectionSize     uint64
 	txFeed          event.Feed
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
@@ -137,35 +143,12 @@ func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subsc
 	return b.chainFeed.Subscribe(ch)
 }
 
-func (b *testBackend) BloomStatus() (uint64, uint64) {
-	return params.BloomBitsBlocks, b.sections
-}
-
-func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
-	requests := make(chan chan *bloombits.Retrieval)
-
-	go session.Multiplex(16, 0, requests)
-	go func() {
-		for {
-			// Wait for a service request or a shutdown
-			select {
-			case <-ctx.Done():
-				return
-
-			case request := <-requests:
-				task := <-request
-
-				task.Bitsets = make([][]byte, len(task.Sections))
-				for i, section := range task.Sections {
-					if rand.Int()%4 != 0 { // Handle occasional missing deliveries
-						head := rawdb.ReadCanonicalHash(b.db, (section+1)*params.BloomBitsBlocks-1)
-						task.Bitsets[i], _ = rawdb.ReadBloomBits(b.db, task.Bit, section, head)
-					}
-				}
-				request <- task
-			}
-		}
-	}()
+func (b *testBackend) LogIndexStatus() (uint64, uint64, uint64) {
+	size := b.sectionSize
+	if size == 0 {
+		size = params.LogIndexBlocks
+	}
+	return size, b.sections, rawdb.ReadLogIndexTail(b.db)
 }
 
 func (b *testBackend) setPending(block *types.Block, receipts types.Receipts) {
@@ -630,3 +613,95 @@ func TestPendingTxFilterDeadlock(t *testing.T) {
 		}
 	}
 }
+
+// TestNewHeadsResume tests that a header subscription resumed over server-sent
+// events replays the headers following the last event ID.
+func TestNewHeadsResume(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		api          = NewFilterAPI(sys)
+		genesis      = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		_, chain, _ = core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 11, func(i int, gen *core.BlockGen) {})
+	)
+	genesis.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+	for _, block := range chain[:10] {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	defer server.Stop() // ends the event streams
+
+	subscribe := func(lastEventID string) *bufio.Scanner {
+		body := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`)
+		req, _ := http.NewRequest(http.MethodPost, ts.URL, body)
+		req.Header.Set("content-type", "application/json")
+		req.Header.Set("accept", "text/event-stream")
+		req.Header.Set("last-event-id", lastEventID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return bufio.NewScanner(resp.Body)
+	}
+	// next reads the next event, returning its ID and data.
+	next := func(events *bufio.Scanner) (id string, msg map[string]json.RawMessage) {
+		for events.Scan() {
+			line := events.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &msg); err != nil {
+					t.Fatalf("invalid event data: %v", err)
+				}
+			case line == "" && msg != nil:
+				return id, msg
+			}
+		}
+		t.Fatalf("event stream ended: %v", events.Err())
+		return "", nil
+	}
+	// Invalid event IDs are rejected
+	if _, msg := next(subscribe("xyz")); msg["error"] == nil {
+		t.Fatalf("invalid event ID accepted: %v", msg)
+	}
+	// The headers after the last event ID are replayed, followed by the new ones
+	events := subscribe("0x7")
+	if _, msg := next(events); msg["result"] == nil {
+		t.Fatalf("subscription failed: %s", msg["error"])
+	}
+	want := func(number uint64) {
+		t.Helper()
+		id, msg := next(events)
+		var params struct {
+			Result *types.Header `json:"result"`
+		}
+		if err := json.Unmarshal(msg["params"], &params); err != nil {
+			t.Fatalf("invalid notification: %v", err)
+		}
+		if have := params.Result.Number.Uint64(); have != number {
+			t.Fatalf("header number mismatch: have %d, want %d", have, number)
+		}
+		if id != hexutil.EncodeUint64(number) {
+			t.Fatalf("event ID mismatch: have %s, want %s", id, hexutil.EncodeUint64(number))
+		}
+	}
+	for number := uint64(8); number <= 10; number++ {
+		want(number)
+	}
+	backend.chainFeed.Send(core.ChainEvent{Hash: chain[10].Hash(), Block: chain[10]})
+	want(11)
+}
@@ -19,6 +19,7 @@ package filters
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"strings"
 	"testing"
@@ -32,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/triedb"
@@ -387,3 +389,244 @@ func TestFilters(t *testing.T) {
 		}
 	})
 }
+
+// writeTestLogIndex writes the log index of the given sections of the chain.
+func writeTestLogIndex(db ethdb.Database, size, sections uint64) {
+	for section := uint64(0); section < sections; section++ {
+		entries := make(map[string][]uint64)
+		add := func(key []byte, number uint64) {
+			numbers := entries[string(key)]
+			if len(numbers) == 0 || numbers[len(numbers)-1] != number {
+				entries[string(key)] = append(numbers, number)
+			}
+		}
+		for number := section * size; number < (section+1)*size; number++ {
+			hash := rawdb.ReadCanonicalHash(db, number)
+			for _, txLogs := range rawdb.ReadLogs(db, hash, number) {
+				for _, log := range txLogs {
+					add(rawdb.LogIndexAddressKey(log.Address), number)
+					for i, topic := range log.Topics {
+						add(rawdb.LogIndexTopicKey(i, topic), number)
+					}
+				}
+			}
+		}
+		rawdb.WriteLogIndexSection(db, section, entries)
+	}
+}
+
+// writeTestLogChain writes a chain of the given length, in which the block i+1
+// contains a log emitted by addrs[i%len(addrs)] with the topics[i%len(topics)]
+// and byte(i) topics, except for every fourth block.
+func writeTestLogChain(db ethdb.Database, n int, addrs []common.Address, topics []common.Hash) []*types.Block {
+	gspec := &core.Genesis{
+		Config:  params.TestChainConfig,
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	_, chain, receipts := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), n, func(i int, gen *core.BlockGen) {
+		if i%4 == 3 {
+			return
+		}
+		receipt := types.NewReceipt(nil, false, 0)
+		receipt.Logs = []*types.Log{{Address: addrs[i%len(addrs)], Topics: []common.Hash{topics[i%len(topics)], {byte(i)}}}}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		gen.AddUncheckedReceipt(receipt)
+		gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+	})
+	gspec.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	return chain
+}
+
+// TestIndexedFilters tests that the range filters return the same logs whether
+// the blocks are matched by the log index, scanned before the index tail or
+// after the indexed sections.
+func TestIndexedFilters(t *testing.T) {
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		addrs        = []common.Address{{0x1}, {0x2}, {0x3}}
+		topics       = []common.Hash{{0xa}, {0xb}}
+	)
+	writeTestLogChain(db, 40, addrs, topics)
+
+	criteria := []struct {
+		begin, end int64
+		addresses  []common.Address
+		topics     [][]common.Hash
+	}{
+		{0, int64(rpc.LatestBlockNumber), []common.Address{addrs[0]}, nil},
+		{0, int64(rpc.LatestBlockNumber), []common.Address{addrs[0], addrs[2]}, [][]common.Hash{{topics[1]}}},
+		{3, 30, nil, [][]common.Hash{nil, {{5}, {12}, {26}, {33}}}},
+		{10, 35, []common.Address{addrs[1]}, [][]common.Hash{{topics[0]}}},
+		{0, int64(rpc.LatestBlockNumber), nil, [][]common.Hash{{{0xff}}}},
+		{0, int64(rpc.LatestBlockNumber), nil, nil},
+	}
+	filterLogs := func() [][]*types.Log {
+		results := make([][]*types.Log, len(criteria))
+		for i, c := range criteria {
+			logs, err := sys.NewRangeFilter(c.begin, c.end, c.addresses, c.topics).Logs(context.Background())
+			if err != nil {
+				t.Fatalf("filter %d: %v", i, err)
+			}
+			results[i] = logs
+		}
+		return results
+	}
+	want := filterLogs()
+
+	// Index the first four sections, unindex the first one and leave a gap at
+	// the third one as if its receipts were unavailable
+	backend.sectionSize, backend.sections = 8, 4
+	writeTestLogIndex(db, 8, 4)
+	batch := db.NewBatch()
+	rawdb.DeleteLogIndexSection(db, batch, 0)
+	rawdb.DeleteLogIndexSection(db, batch, 2)
+	rawdb.WriteLogIndexTail(batch, 1)
+	batch.Write()
+
+	for i, have := range filterLogs() {
+		if len(have) != len(want[i]) {
+			t.Fatalf("filter %d: log count mismatch, have %d, want %d", i, len(have), len(want[i]))
+		}
+		for j := range have {
+			if have[j].BlockNumber != want[i][j].BlockNumber || have[j].Index != want[i][j].Index {
+				t.Fatalf("filter %d: log %d mismatch, have block %d, want block %d", i, j, have[j].BlockNumber, want[i][j].BlockNumber)
+			}
+		}
+	}
+	if len(want[0]) == 0 || len(want[2]) != 3 || len(want[4]) != 0 {
+		t.Fatalf("unexpected filter results: %d %d %d", len(want[0]), len(want[2]), len(want[4]))
+	}
+}
+
+// TestFilterPaging tests that the paged log queries return all the matching
+// logs across the pages, and that the query limits are enforced.
+func TestFilterPaging(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		addrs  = []common.Address{{0x1}, {0x2}}
+		topics = []common.Hash{{0xa}}
+		chain  = writeTestLogChain(db, 40, addrs, topics)
+		latest = int64(rpc.LatestBlockNumber)
+	)
+	_, sys := newTestFilterSystem(t, db, Config{})
+	want, err := sys.NewRangeFilter(0, latest, []common.Address{addrs[0]}, nil).Logs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 20 {
+		t.Fatalf("unexpected log count: have %d, want %d", len(want), 20)
+	}
+	page := func(sys *FilterSystem, limit int) ([]*types.Log, int) {
+		var (
+			logs   []*types.Log
+			pages  int
+			cursor *LogCursor
+		)
+		for {
+			found, next, err := sys.NewRangeFilter(0, latest, []common.Address{addrs[0]}, nil).Page(context.Background(), limit, cursor)
+			if err != nil {
+				t.Fatalf("failed to retrieve page %d: %v", pages, err)
+			}
+			if limit != 0 && len(found) > limit {
+				t.Fatalf("page %d exceeds the limit: have %d, want at most %d", pages, len(found), limit)
+			}
+			logs, pages = append(logs, found...), pages+1
+			if next == nil {
+				return logs, pages
+			}
+			// Round-trip the cursor through its opaque encoding
+			enc, _ := next.MarshalText()
+			cursor = new(LogCursor)
+			if err := cursor.UnmarshalText(enc); err != nil {
+				t.Fatalf("failed to decode cursor %s: %v", enc, err)
+			}
+		}
+	}
+	check := func(name string, logs []*types.Log) {
+		t.Helper()
+		if len(logs) != len(want) {
+			t.Fatalf("%s: log count mismatch: have %d, want %d", name, len(logs), len(want))
+		}
+		for i := range logs {
+			if logs[i].BlockNumber != want[i].BlockNumber || logs[i].Index != want[i].Index {
+				t.Fatalf("%s: log %d mismatch: have block %d, want block %d", name, i, logs[i].BlockNumber, want[i].BlockNumber)
+			}
+		}
+	}
+	for _, limit := range []int{1, 2, 7, 20, 100} {
+		logs, pages := page(sys, limit)
+		check(fmt.Sprintf("limit %d", limit), logs)
+		if minPages := (len(want) + limit - 1) / limit; pages < minPages {
+			t.Fatalf("limit %d: too few pages: have %d, want at least %d", limit, pages, minPages)
+		}
+	}
+	// The range limit rejects the plain queries and splits the paged ones
+	_, capped := newTestFilterSystem(t, db, Config{LogRangeLimit: 8, LogQueryLimit: 4})
+	if _, err := capped.NewRangeFilter(0, latest, []common.Address{addrs[0]}, nil).Logs(context.Background()); err != errExceedMaxBlockRange {
+		t.Fatalf("unexpected error: have %v, want %v", err, errExceedMaxBlockRange)
+	}
+	if _, err := capped.NewRangeFilter(0, 7, nil, nil).Logs(context.Background()); err != errExceedLogQueryLimit {
+		t.Fatalf("unexpected error: have %v, want %v", err, errExceedLogQueryLimit)
+	}
+	if logs, err := capped.NewRangeFilter(0, 7, []common.Address{addrs[0]}, nil).Logs(context.Background()); err != nil || len(logs) != 4 {
+		t.Fatalf("unexpected result: %d logs, %v", len(logs), err)
+	}
+	logs, _ := page(capped, 0)
+	check("capped", logs)
+
+	// A cursor out of the range is rejected
+	cursor := &LogCursor{BlockNumber: chain[len(chain)-1].NumberU64() + 1}
+	if _, _, err := sys.NewRangeFilter(0, latest, nil, nil).Page(context.Background(), 1, cursor); err != errInvalidLogCursor {
+		t.Fatalf("unexpected error: have %v, want %v", err, errInvalidLogCursor)
+	}
+
+	// Paging is served by eth_getLogsPage only
+	var (
+		api  = NewFilterAPI(sys)
+		crit = FilterCriteria{FromBlock: big.NewInt(0), Addresses: []common.Address{addrs[0]}, Limit: 7}
+	)
+	if _, err := api.GetLogs(context.Background(), crit); err != errPagedLogQuery {
+		t.Fatalf("unexpected error: have %v, want %v", err, errPagedLogQuery)
+	}
+	logs = nil
+	for {
+		page, err := api.GetLogsPage(context.Background(), crit)
+		if err != nil {
+			t.Fatalf("failed to retrieve page: %v", err)
+		}
+		logs = append(logs, page.Logs...)
+		if page.Cursor == nil {
+			break
+		}
+		enc, _ := page.Cursor.MarshalText()
+		crit.Cursor = string(enc)
+	}
+	check("api", logs)
+}
+
+func TestLogCursorOrder(t *testing.T) {
+	cursor := &LogCursor{BlockNumber: 5, TxIndex: 2, LogIndex: 4}
+	tests := []struct {
+		log      types.Log
+		precedes bool
+	}{
+		{types.Log{BlockNumber: 4, TxIndex: 9, Index: 9}, true},
+		{types.Log{BlockNumber: 5, TxIndex: 1, Index: 9}, true},
+		{types.Log{BlockNumber: 5, TxIndex: 2, Index: 3}, true},
+		{types.Log{BlockNumber: 5, TxIndex: 2, Index: 4}, false},
+		{types.Log{BlockNumber: 5, TxIndex: 3, Index: 0}, false},
+		{types.Log{BlockNumber: 6, TxIndex: 0, Index: 0}, false},
+	}
+	for i, tt := range tests {
+		if have := cursor.precedes(&tt.log); have != tt.precedes {
+			t.Errorf("test %d: precedes mismatch: have %v, want %v", i, have, tt.precedes)
+		}
+	}
+}
@@ -903,6 +903,7 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 		TxIndex:     int(index),
 		TxHash:      hash,
 	}
+	ethapi.CacheFinalizedResponse(ctx, api.backend, block.Header())
 	return api.traceTx(ctx, tx, msg, txctx, vmctx, statedb, config)
 }
 
@@ -439,6 +439,21 @@ func (ec *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]typ
 	return result, err
 }
 
+// FilterLogsPage executes a paged filter query, returning at most q.Limit logs
+// and the cursor to resume the query from. The cursor is empty on the last page.
+func (ec *Client) FilterLogsPage(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, string, error) {
+	var result struct {
+		Logs   []types.Log `json:"logs"`
+		Cursor string      `json:"cursor"`
+	}
+	arg, err := toFilterArg(q)
+	if err != nil {
+		return nil, "", err
+	}
+	err = ec.c.CallContext(ctx, &result, "eth_getLogsPage", arg)
+	return result.Logs, result.Cursor, err
+}
+
 // SubscribeFilterLogs subscribes to the results of a streaming filter query.
 func (ec *Client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
 	arg, err := toFilterArg(q)
@@ -473,6 +488,12 @@ func toFilterArg(q ethereum.FilterQuery) (interface{}, error) {
 		}
 		arg["toBlock"] = toBlockNumArg(q.ToBlock)
 	}
+	if q.Limit != 0 {
+		arg["limit"] = hexutil.Uint64(q.Limit)
+	}
+	if q.Cursor != "" {
+		arg["cursor"] = q.Cursor
+	}
 	return arg, nil
 }
 
@@ -677,6 +698,9 @@ func toCallArg(msg ethereum.CallMsg) interface{} {
 	if msg.BlobHashes != nil {
 		arg["blobVersionedHashes"] = msg.BlobHashes
 	}
+	if msg.AuthorizationList != nil {
+		arg["authorizationList"] = msg.AuthorizationList
+	}
 	return arg
 }
 
@@ -0,0 +1,568 @@
+package graphql
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/types/goattypes"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// TransactionFilter restricts the transactions of a block by goat module and action.
+type TransactionFilter struct {
+	Goat   *bool
+	Module *Long
+	Action *Long
+}
+
+func (f *TransactionFilter) match(tx *types.Transaction) bool {
+	if f == nil {
+		return true
+	}
+	if f.Goat != nil && *f.Goat != tx.IsGoatTx() {
+		return false
+	}
+	if f.Module == nil && f.Action == nil {
+		return true
+	}
+	if !tx.IsGoatTx() {
+		return false
+	}
+	module, action := tx.GoatModule()
+	if f.Module != nil && Long(module) != *f.Module {
+		return false
+	}
+	if f.Action != nil && Long(action) != *f.Action {
+		return false
+	}
+	return true
+}
+
+func (t *Transaction) Goat(ctx context.Context) *GoatTransaction {
+	tx, _ := t.resolve(ctx)
+	if tx == nil || !tx.IsGoatTx() {
+		return nil
+	}
+	return &GoatTransaction{tx: tx}
+}
+
+func (b *Block) GoatTxRoot(ctx context.Context) (*common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if b.r.backend.ChainConfig().Goat == nil || len(header.Extra) != params.GoatHeaderExtraLengthV0 {
+		return nil, nil
+	}
+	root := common.BytesToHash(header.Extra[1:])
+	return &root, nil
+}
+
+func (b *Block) GoatTxCount(ctx context.Context) (*hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if b.r.backend.ChainConfig().Goat == nil || len(header.Extra) != params.GoatHeaderExtraLengthV0 {
+		return nil, nil
+	}
+	count := hexutil.Uint64(header.Extra[0])
+	return &count, nil
+}
+
+func (b *Block) GoatRequests(ctx context.Context) (*GoatRequests, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return nil, err
+	}
+	if b.r.backend.ChainConfig().Goat == nil {
+		return nil, nil
+	}
+	requests := rawdb.ReadGoatRequests(b.r.backend.ChainDb(), header.Hash(), header.Number.Uint64())
+	if requests == nil {
+		return nil, nil
+	}
+	bridge, relayer, locking, err := goattypes.DecodeRequests(requests)
+	if err != nil {
+		return nil, err
+	}
+	return &GoatRequests{bridge: bridge, relayer: relayer, locking: locking}, nil
+}
+
+// GoatTransaction represents the goat detail of a goat transaction.
+type GoatTransaction struct {
+	tx *types.Transaction
+}
+
+func (t *GoatTransaction) Module(ctx context.Context) hexutil.Uint64 {
+	module, _ := t.tx.GoatModule()
+	return hexutil.Uint64(module)
+}
+
+func (t *GoatTransaction) Action(ctx context.Context) hexutil.Uint64 {
+	_, action := t.tx.GoatModule()
+	return hexutil.Uint64(action)
+}
+
+func (t *GoatTransaction) Sender(ctx context.Context) common.Address {
+	return t.tx.GoatTx().Sender()
+}
+
+func (t *GoatTransaction) Contract(ctx context.Context) common.Address {
+	return t.tx.GoatTx().Contract()
+}
+
+func (t *GoatTransaction) Payload(ctx context.Context) *GoatTxPayload {
+	return &GoatTxPayload{tx: t.tx.GoatTx()}
+}
+
+func (t *GoatTransaction) Mint(ctx context.Context) *GoatMint {
+	if mint := t.tx.Deposit(); mint != nil {
+		return &GoatMint{mint: mint}
+	}
+	return nil
+}
+
+func (t *GoatTransaction) Claim(ctx context.Context) *GoatMint {
+	if claim := t.tx.Claim(); claim != nil {
+		return &GoatMint{mint: claim}
+	}
+	return nil
+}
+
+// GoatMint represents an amount minted or claimed by a goat transaction.
+type GoatMint struct {
+	mint *goattypes.Mint
+}
+
+func (m *GoatMint) Address(ctx context.Context) common.Address {
+	return m.mint.Address
+}
+
+func (m *GoatMint) Amount(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*m.mint.Amount)
+}
+
+// GoatTxPayload is the union resolver of the goat transaction payloads.
+type GoatTxPayload struct {
+	tx goattypes.Tx
+}
+
+func (p *GoatTxPayload) ToGoatDepositTx() (*GoatDepositTx, bool) {
+	tx, ok := p.tx.(*goattypes.DepositTx)
+	return &GoatDepositTx{tx}, ok
+}
+
+func (p *GoatTxPayload) ToGoatCancel2Tx() (*GoatCancel2Tx, bool) {
+	tx, ok := p.tx.(*goattypes.Cancel2Tx)
+	return &GoatCancel2Tx{tx}, ok
+}
+
+func (p *GoatTxPayload) ToGoatPaidTx() (*GoatPaidTx, bool) {
+	tx, ok := p.tx.(*goattypes.PaidTx)
+	return &GoatPaidTx{tx}, ok
+}
+
+func (p *GoatTxPayload) ToGoatNewBtcBlockTx() (*GoatNewBtcBlockTx, bool) {
+	tx, ok := p.tx.(*goattypes.NewBtcBlockTx)
+	return &GoatNewBtcBlockTx{tx}, ok
+}
+
+func (p *GoatTxPayload) ToGoatCompleteUnlockTx() (*GoatCompleteUnlockTx, bool) {
+	tx, ok := p.tx.(*goattypes.CompleteUnlockTx)
+	return &GoatCompleteUnlockTx{tx}, ok
+}
+
+func (p *GoatTxPayload) ToGoatDistributeRewardTx() (*GoatDistributeRewardTx, bool) {
+	tx, ok := p.tx.(*goattypes.DistributeRewardTx)
+	return &GoatDistributeRewardTx{tx}, ok
+}
+
+type GoatDepositTx struct{ tx *goattypes.DepositTx }
+
+func (t *GoatDepositTx) Txid(ctx context.Context) common.Hash {
+	return t.tx.Txid
+}
+
+func (t *GoatDepositTx) TxOut(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(t.tx.TxOut)
+}
+
+func (t *GoatDepositTx) Target(ctx context.Context) common.Address {
+	return t.tx.Target
+}
+
+func (t *GoatDepositTx) Amount(ctx context.Context) hexutil.Big {
+	return bigValue(t.tx.Amount)
+}
+
+type GoatCancel2Tx struct{ tx *goattypes.Cancel2Tx }
+
+func (t *GoatCancel2Tx) Id(ctx context.Context) hexutil.Big {
+	return bigValue(t.tx.Id)
+}
+
+type GoatPaidTx struct{ tx *goattypes.PaidTx }
+
+func (t *GoatPaidTx) Id(ctx context.Context) hexutil.Big {
+	return bigValue(t.tx.Id)
+}
+
+func (t *GoatPaidTx) Txid(ctx context.Context) common.Hash {
+	return t.tx.Txid
+}
+
+func (t *GoatPaidTx) TxOut(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(t.tx.TxOut)
+}
+
+func (t *GoatPaidTx) Amount(ctx context.Context) hexutil.Big {
+	return bigValue(t.tx.Amount)
+}
+
+type GoatNewBtcBlockTx struct{ tx *goattypes.NewBtcBlockTx }
+
+func (t *GoatNewBtcBlockTx) Hash(ctx context.Context) common.Hash {
+	return t.tx.Hash
+}
+
+type GoatCompleteUnlockTx struct{ tx *goattypes.CompleteUnlockTx }
+
+func (t *GoatCompleteUnlockTx) Id(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(t.tx.Id)
+}
+
+func (t *GoatCompleteUnlockTx) Recipient(ctx context.Context) common.Address {
+	return t.tx.Recipient
+}
+
+func (t *GoatCompleteUnlockTx) Token(ctx context.Context) common.Address {
+	return t.tx.Token
+}
+
+func (t *GoatCompleteUnlockTx) Amount(ctx context.Context) hexutil.Big {
+	return bigValue(t.tx.Amount)
+}
+
+type GoatDistributeRewardTx struct{ tx *goattypes.DistributeRewardTx }
+
+func (t *GoatDistributeRewardTx) Id(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(t.tx.Id)
+}
+
+func (t *GoatDistributeRewardTx) Recipient(ctx context.Context) common.Address {
+	return t.tx.Recipient
+}
+
+func (t *GoatDistributeRewardTx) Goat(ctx context.Context) hexutil.Big {
+	return bigValue(t.tx.Goat)
+}
+
+func (t *GoatDistributeRewardTx) GasReward(ctx context.Context) hexutil.Big {
+	return bigValue(t.tx.GasReward)
+}
+
+// GoatRequests represents the goat requests of a block grouped by module.
+type GoatRequests struct {
+	bridge  goattypes.BridgeRequests
+	relayer goattypes.RelayerRequests
+	locking goattypes.LockingRequests
+}
+
+func (r *GoatRequests) Bridge(ctx context.Context) []*GoatBridgeRequest {
+	res := make([]*GoatBridgeRequest, 0)
+	for _, req := range r.bridge.Withdraws {
+		res = append(res, &GoatBridgeRequest{req})
+	}
+	for _, req := range r.bridge.ReplaceByFees {
+		res = append(res, &GoatBridgeRequest{req})
+	}
+	for _, req := range r.bridge.Cancel1s {
+		res = append(res, &GoatBridgeRequest{req})
+	}
+	return res
+}
+
+func (r *GoatRequests) Locking(ctx context.Context) []*GoatLockingRequest {
+	res := make([]*GoatLockingRequest, 0)
+	for _, req := range r.locking.Gas {
+		res = append(res, &GoatLockingRequest{req})
+	}
+	for _, req := range r.locking.Creates {
+		res = append(res, &GoatLockingRequest{req})
+	}
+	for _, req := range r.locking.Locks {
+		res = append(res, &GoatLockingRequest{req})
+	}
+	for _, req := range r.locking.Unlocks {
+		res = append(res, &GoatLockingRequest{req})
+	}
+	for _, req := range r.locking.Claims {
+		res = append(res, &GoatLockingRequest{req})
+	}
+	for _, req := range r.locking.Grants {
+		res = append(res, &GoatLockingRequest{req})
+	}
+	for _, req := range r.locking.UpdateWeights {
+		res = append(res, &GoatLockingRequest{req})
+	}
+	for _, req := range r.locking.UpdateThresholds {
+		res = append(res, &GoatLockingRequest{req})
+	}
+	return res
+}
+
+func (r *GoatRequests) Relayer(ctx context.Context) []*GoatRelayerRequest {
+	res := make([]*GoatRelayerRequest, 0)
+	for _, req := range r.relayer.Adds {
+		res = append(res, &GoatRelayerRequest{req})
+	}
+	for _, req := range r.relayer.Removes {
+		res = append(res, &GoatRelayerRequest{req})
+	}
+	return res
+}
+
+// GoatBridgeRequest is the union resolver of the bridge requests.
+type GoatBridgeRequest struct {
+	req goattypes.Request
+}
+
+func (r *GoatBridgeRequest) ToGoatWithdrawalRequest() (*GoatWithdrawalRequest, bool) {
+	req, ok := r.req.(*goattypes.WithdrawalRequest)
+	return &GoatWithdrawalRequest{req}, ok
+}
+
+func (r *GoatBridgeRequest) ToGoatReplaceByFeeRequest() (*GoatReplaceByFeeRequest, bool) {
+	req, ok := r.req.(*goattypes.ReplaceByFeeRequest)
+	return &GoatReplaceByFeeRequest{req}, ok
+}
+
+func (r *GoatBridgeRequest) ToGoatCancel1Request() (*GoatCancel1Request, bool) {
+	req, ok := r.req.(*goattypes.Cancel1Request)
+	return &GoatCancel1Request{req}, ok
+}
+
+// GoatLockingRequest is the union resolver of the locking requests.
+type GoatLockingRequest struct {
+	req goattypes.Request
+}
+
+func (r *GoatLockingRequest) ToGoatGasRequest() (*GoatGasRequest, bool) {
+	req, ok := r.req.(*goattypes.GasRequest)
+	return &GoatGasRequest{req}, ok
+}
+
+func (r *GoatLockingRequest) ToGoatCreateRequest() (*GoatCreateRequest, bool) {
+	req, ok := r.req.(*goattypes.CreateRequest)
+	return &GoatCreateRequest{req}, ok
+}
+
+func (r *GoatLockingRequest) ToGoatLockRequest() (*GoatLockRequest, bool) {
+	req, ok := r.req.(*goattypes.LockRequest)
+	return &GoatLockRequest{req}, ok
+}
+
+func (r *GoatLockingRequest) ToGoatUnlockRequest() (*GoatUnlockRequest, bool) {
+	req, ok := r.req.(*goattypes.UnlockRequest)
+	return &GoatUnlockRequest{req}, ok
+}
+
+func (r *GoatLockingRequest) ToGoatClaimRequest() (*GoatClaimRequest, bool) {
+	req, ok := r.req.(*goattypes.ClaimRequest)
+	return &GoatClaimRequest{req}, ok
+}
+
+func (r *GoatLockingRequest) ToGoatGrantRequest() (*GoatGrantRequest, bool) {
+	req, ok := r.req.(*goattypes.GrantRequest)
+	return &GoatGrantRequest{req}, ok
+}
+
+func (r *GoatLockingRequest) ToGoatUpdateTokenWeightRequest() (*GoatUpdateTokenWeightRequest, bool) {
+	req, ok := r.req.(*goattypes.UpdateTokenWeightRequest)
+	return &GoatUpdateTokenWeightRequest{req}, ok
+}
+
+func (r *GoatLockingRequest) ToGoatUpdateTokenThresholdRequest() (*GoatUpdateTokenThresholdRequest, bool) {
+	req, ok := r.req.(*goattypes.UpdateTokenThresholdRequest)
+	return &GoatUpdateTokenThresholdRequest{req}, ok
+}
+
+// GoatRelayerRequest is the union resolver of the relayer requests.
+type GoatRelayerRequest struct {
+	req goattypes.Request
+}
+
+func (r *GoatRelayerRequest) ToGoatAddVoterRequest() (*GoatAddVoterRequest, bool) {
+	req, ok := r.req.(*goattypes.AddVoterRequest)
+	return &GoatAddVoterRequest{req}, ok
+}
+
+func (r *GoatRelayerRequest) ToGoatRemoveVoterRequest() (*GoatRemoveVoterRequest, bool) {
+	req, ok := r.req.(*goattypes.RemoveVoterRequest)
+	return &GoatRemoveVoterRequest{req}, ok
+}
+
+type GoatWithdrawalRequest struct{ req *goattypes.WithdrawalRequest }
+
+func (r *GoatWithdrawalRequest) Id(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.req.Id)
+}
+
+func (r *GoatWithdrawalRequest) Amount(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.req.Amount)
+}
+
+func (r *GoatWithdrawalRequest) TxPrice(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.req.TxPrice)
+}
+
+func (r *GoatWithdrawalRequest) Address(ctx context.Context) string {
+	return r.req.Address
+}
+
+type GoatReplaceByFeeRequest struct {
+	req *goattypes.ReplaceByFeeRequest
+}
+
+func (r *GoatReplaceByFeeRequest) Id(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.req.Id)
+}
+
+func (r *GoatReplaceByFeeRequest) TxPrice(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.req.TxPrice)
+}
+
+type GoatCancel1Request struct{ req *goattypes.Cancel1Request }
+
+func (r *GoatCancel1Request) Id(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.req.Id)
+}
+
+type GoatGasRequest struct{ req *goattypes.GasRequest }
+
+func (r *GoatGasRequest) Height(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.req.Height)
+}
+
+func (r *GoatGasRequest) Amount(ctx context.Context) hexutil.Big {
+	return bigValue(r.req.Amount)
+}
+
+type GoatCreateRequest struct{ req *goattypes.CreateRequest }
+
+func (r *GoatCreateRequest) Validator(ctx context.Context) common.Address {
+	return r.req.Validator
+}
+
+func (r *GoatCreateRequest) Pubkey(ctx context.Context) hexutil.Bytes {
+	return r.req.Pubkey[:]
+}
+
+type GoatLockRequest struct{ req *goattypes.LockRequest }
+
+func (r *GoatLockRequest) Validator(ctx context.Context) common.Address {
+	return r.req.Validator
+}
+
+func (r *GoatLockRequest) Token(ctx context.Context) common.Address {
+	return r.req.Token
+}
+
+func (r *GoatLockRequest) Amount(ctx context.Context) hexutil.Big {
+	return bigValue(r.req.Amount)
+}
+
+type GoatUnlockRequest struct{ req *goattypes.UnlockRequest }
+
+func (r *GoatUnlockRequest) Id(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.req.Id)
+}
+
+func (r *GoatUnlockRequest) Validator(ctx context.Context) common.Address {
+	return r.req.Validator
+}
+
+func (r *GoatUnlockRequest) Recipient(ctx context.Context) common.Address {
+	return r.req.Recipient
+}
+
+func (r *GoatUnlockRequest) Token(ctx context.Context) common.Address {
+	return r.req.Token
+}
+
+func (r *GoatUnlockRequest) Amount(ctx context.Context) hexutil.Big {
+	return bigValue(r.req.Amount)
+}
+
+type GoatClaimRequest struct{ req *goattypes.ClaimRequest }
+
+func (r *GoatClaimRequest) Id(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.req.Id)
+}
+
+func (r *GoatClaimRequest) Validator(ctx context.Context) common.Address {
+	return r.req.Validator
+}
+
+func (r *GoatClaimRequest) Recipient(ctx context.Context) common.Address {
+	return r.req.Recipient
+}
+
+type GoatGrantRequest struct{ req *goattypes.GrantRequest }
+
+func (r *GoatGrantRequest) Amount(ctx context.Context) hexutil.Big {
+	return bigValue(r.req.Amount)
+}
+
+type GoatUpdateTokenWeightRequest struct {
+	req *goattypes.UpdateTokenWeightRequest
+}
+
+func (r *GoatUpdateTokenWeightRequest) Token(ctx context.Context) common.Address {
+	return r.req.Token
+}
+
+func (r *GoatUpdateTokenWeightRequest) Weight(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.req.Weight)
+}
+
+type GoatUpdateTokenThresholdRequest struct {
+	req *goattypes.UpdateTokenThresholdRequest
+}
+
+func (r *GoatUpdateTokenThresholdRequest) Token(ctx context.Context) common.Address {
+	return r.req.Token
+}
+
+func (r *GoatUpdateTokenThresholdRequest) Threshold(ctx context.Context) hexutil.Big {
+	return bigValue(r.req.Threshold)
+}
+
+type GoatAddVoterRequest struct{ req *goattypes.AddVoterRequest }
+
+func (r *GoatAddVoterRequest) Voter(ctx context.Context) common.Address {
+	return r.req.Voter
+}
+
+func (r *GoatAddVoterRequest) Pubkey(ctx context.Context) common.Hash {
+	return r.req.Pubkey
+}
+
+type GoatRemoveVoterRequest struct{ req *goattypes.RemoveVoterRequest }
+
+func (r *GoatRemoveVoterRequest) Voter(ctx context.Context) common.Address {
+	return r.req.Voter
+}
+
+// bigValue converts the nullable big integer into a graphql BigInt.
+func bigValue(v *big.Int) hexutil.Big {
+	if v == nil {
+		return hexutil.Big{}
+	}
+	return hexutil.Big(*v)
+}
@@ -0,0 +1,124 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/types/goattypes"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newGoatGQLService is newGQLService for the goat chains, which are post-merge
+// from the genesis and generate the blocks with their own chain config.
+func newGoatGQLService(t *testing.T, stack *node.Node, gspec *core.Genesis, genBlocks int, genfunc func(i int, gen *core.BlockGen)) *handler {
+	ethConf := &ethconfig.Config{
+		Genesis:        gspec,
+		NetworkId:      1337,
+		TrieCleanCache: 5,
+		TrieDirtyCache: 5,
+		TrieTimeout:    60 * time.Minute,
+		SnapshotCache:  5,
+		RPCGasCap:      1000000,
+		StateScheme:    rawdb.HashScheme,
+	}
+	ethBackend, err := eth.New(stack, ethConf)
+	if err != nil {
+		t.Fatalf("could not create eth backend: %v", err)
+	}
+	chain, _ := core.GenerateChain(gspec.Config, ethBackend.BlockChain().Genesis(),
+		beacon.NewFaker(), ethBackend.ChainDb(), genBlocks, genfunc)
+	if _, err := ethBackend.BlockChain().InsertChain(chain); err != nil {
+		t.Fatalf("could not create import blocks: %v", err)
+	}
+	filterSystem := filters.NewFilterSystem(ethBackend.APIBackend, filters.Config{})
+	handler, err := newHandler(stack, ethBackend.APIBackend, filterSystem, []string{}, []string{})
+	if err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	return handler
+}
+
+func TestGoatTransactions(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		config = *params.AllGoatDebugChainConfig
+
+		genesis = &core.Genesis{
+			Config:   &config,
+			GasLimit: 11500000,
+			Alloc: types.GenesisAlloc{
+				addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+		stack  = createNode(t)
+
+		recipient = common.HexToAddress("0x94d76e24f818426ae84aa404140e8d5f60e10e7e")
+	)
+	defer stack.Close()
+
+	handler := newGoatGQLService(t, stack, genesis, 2, func(i int, gen *core.BlockGen) {
+		if i == 1 {
+			reward := &goattypes.DistributeRewardTx{Id: 1, Recipient: recipient, Goat: big.NewInt(100), GasReward: big.NewInt(10)}
+			gen.AddTx(types.NewTx(types.NewGoatTx(goattypes.LockingModule, goattypes.LockingDistributeRewardAction, 0, reward)))
+		}
+		tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: uint64(i), To: &common.Address{}, Gas: 100000, GasPrice: big.NewInt(params.InitialBaseFee)})
+		gen.AddTx(tx)
+	})
+	// start node
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+
+	for i, tt := range []struct {
+		body string
+		want string
+	}{
+		{
+			body: "{block(number: 1) { goatTxCount transactions { type goat { module } } } }",
+			want: `{"block":{"goatTxCount":"0x0","transactions":[{"type":"0x0","goat":null}]}}`,
+		},
+		{
+			body: "{block(number: 2) { goatTxCount transactions(filter: {goat: true}) { goat { module action sender contract claim { address amount } payload { ... on GoatDistributeRewardTx { id recipient goat gasReward } } } } } }",
+			want: `{"block":{"goatTxCount":"0x1","transactions":[{"goat":{"module":"0x2","action":"0x2","sender":"0xbc10000000000000000000000000000000001001","contract":"0xbc10000000000000000000000000000000000004","claim":{"address":"0x94d76e24f818426ae84aa404140e8d5f60e10e7e","amount":"0xa"},"payload":{"id":"0x1","recipient":"0x94d76e24f818426ae84aa404140e8d5f60e10e7e","goat":"0x64","gasReward":"0xa"}}}]}}`,
+		},
+		{
+			body: "{block(number: 2) { transactions(filter: {module: 1}) { index } } }",
+			want: `{"block":{"transactions":[]}}`,
+		},
+		{
+			body: "{block(number: 2) { transactions(filter: {goat: false}) { index } } }",
+			want: `{"block":{"transactions":[{"index":"0x1"}]}}`,
+		},
+		{
+			body: "{block(number: 1) { goatRequests { bridge { __typename } relayer { __typename } locking { ... on GoatGasRequest { height } } } } }",
+			want: `{"block":{"goatRequests":{"bridge":[],"relayer":[],"locking":[{"height":"0x1"}]}}}`,
+		},
+	} {
+		res := handler.Schema.Exec(context.Background(), tt.body, "", map[string]interface{}{})
+		if res.Errors != nil {
+			t.Fatalf("failed to execute query for testcase #%d: %v", i, res.Errors)
+		}
+		have, err := json.Marshal(res.Data)
+		if err != nil {
+			t.Fatalf("failed to encode graphql response for testcase #%d: %s", i, err)
+		}
+		if string(have) != tt.want {
+			t.Errorf("response unmatch for testcase #%d.\nhave:\n%s\nwant:\n%s", i, have, tt.want)
+		}
+	}
+}
@@ -967,13 +967,16 @@ func (b *Block) TransactionCount(ctx context.Context) (*hexutil.Uint64, error) {
 	return &count, err
 }
 
-func (b *Block) Transactions(ctx context.Context) (*[]*Transaction, error) {
+func (b *Block) Transactions(ctx context.Context, args struct{ Filter *TransactionFilter }) (*[]*Transaction, error) {
 	block, err := b.resolve(ctx)
 	if err != nil || block == nil {
 		return nil, err
 	}
 	ret := make([]*Transaction, 0, len(block.Transactions()))
 	for i, tx := range block.Transactions() {
+		if !args.Filter.match(tx) {
+			continue
+		}
 		ret = append(ret, &Transaction{
 			r:     b.r,
 			hash:  tx.Hash(),
@@ -89,6 +89,186 @@ const schema string = `
         amount: Long!
     }
 
+    # GoatMint is the amount minted to or claimed by an address in a goat transaction.
+    type GoatMint {
+        # Address is the recipient of the amount.
+        address: Address!
+        # Amount is the minted or claimed value, in wei.
+        amount: BigInt!
+    }
+
+    # GoatDepositTx is a bridge deposit from bitcoin.
+    type GoatDepositTx {
+        txid: Bytes32!
+        txOut: Long!
+        target: Address!
+        amount: BigInt!
+    }
+
+    # GoatCancel2Tx cancels a bridge withdrawal.
+    type GoatCancel2Tx {
+        id: BigInt!
+    }
+
+    # GoatPaidTx reports a bridge withdrawal is paid on bitcoin.
+    type GoatPaidTx {
+        id: BigInt!
+        txid: Bytes32!
+        txOut: Long!
+        amount: BigInt!
+    }
+
+    # GoatNewBtcBlockTx adds a new bitcoin block hash.
+    type GoatNewBtcBlockTx {
+        hash: Bytes32!
+    }
+
+    # GoatCompleteUnlockTx completes an unlock of the locking module.
+    type GoatCompleteUnlockTx {
+        id: Long!
+        recipient: Address!
+        token: Address!
+        amount: BigInt!
+    }
+
+    # GoatDistributeRewardTx pays the validator reward of the locking module.
+    type GoatDistributeRewardTx {
+        id: Long!
+        recipient: Address!
+        goat: BigInt!
+        gasReward: BigInt!
+    }
+
+    # GoatTxPayload is the decoded payload of a goat transaction.
+    union GoatTxPayload = GoatDepositTx | GoatCancel2Tx | GoatPaidTx | GoatNewBtcBlockTx | GoatCompleteUnlockTx | GoatDistributeRewardTx
+
+    # GoatTransaction is a transaction from the goat consensus layer.
+    type GoatTransaction {
+        # Module is the goat module of the transaction, 1 for bridge and 2 for locking.
+        module: Long!
+        # Action is the action id in the module.
+        action: Long!
+        # Sender is the executor address of the module.
+        sender: Address!
+        # Contract is the goat predeployed contract called by the transaction.
+        contract: Address!
+        # Payload is the decoded payload of the transaction.
+        payload: GoatTxPayload!
+        # Mint is the deposit amount minted by the transaction, if any.
+        mint: GoatMint
+        # Claim is the amount claimed from the locking contract, if any.
+        claim: GoatMint
+    }
+
+    # GoatWithdrawalRequest is a bridge withdrawal to bitcoin.
+    type GoatWithdrawalRequest {
+        id: Long!
+        amount: Long!
+        txPrice: Long!
+        address: String!
+    }
+
+    # GoatReplaceByFeeRequest replaces the tx price of a bridge withdrawal.
+    type GoatReplaceByFeeRequest {
+        id: Long!
+        txPrice: Long!
+    }
+
+    # GoatCancel1Request requests to cancel a bridge withdrawal.
+    type GoatCancel1Request {
+        id: Long!
+    }
+
+    # GoatGasRequest is the gas revenue handed to the locking module in the block.
+    type GoatGasRequest {
+        height: Long!
+        amount: BigInt!
+    }
+
+    # GoatCreateRequest creates a validator.
+    type GoatCreateRequest {
+        validator: Address!
+        pubkey: Bytes!
+    }
+
+    # GoatLockRequest locks tokens for a validator.
+    type GoatLockRequest {
+        validator: Address!
+        token: Address!
+        amount: BigInt!
+    }
+
+    # GoatUnlockRequest unlocks tokens of a validator.
+    type GoatUnlockRequest {
+        id: Long!
+        validator: Address!
+        recipient: Address!
+        token: Address!
+        amount: BigInt!
+    }
+
+    # GoatClaimRequest claims the reward of a validator.
+    type GoatClaimRequest {
+        id: Long!
+        validator: Address!
+        recipient: Address!
+    }
+
+    # GoatGrantRequest grants goat token to the reward pool.
+    type GoatGrantRequest {
+        amount: BigInt!
+    }
+
+    # GoatUpdateTokenWeightRequest updates the weight of a locking token.
+    type GoatUpdateTokenWeightRequest {
+        token: Address!
+        weight: Long!
+    }
+
+    # GoatUpdateTokenThresholdRequest updates the threshold of a locking token.
+    type GoatUpdateTokenThresholdRequest {
+        token: Address!
+        threshold: BigInt!
+    }
+
+    # GoatAddVoterRequest adds a relayer voter.
+    type GoatAddVoterRequest {
+        voter: Address!
+        pubkey: Bytes32!
+    }
+
+    # GoatRemoveVoterRequest removes a relayer voter.
+    type GoatRemoveVoterRequest {
+        voter: Address!
+    }
+
+    # GoatBridgeRequest is a request to the goat bridge module.
+    union GoatBridgeRequest = GoatWithdrawalRequest | GoatReplaceByFeeRequest | GoatCancel1Request
+
+    # GoatLockingRequest is a request to the goat locking module.
+    union GoatLockingRequest = GoatGasRequest | GoatCreateRequest | GoatLockRequest | GoatUnlockRequest | GoatClaimRequest | GoatGrantRequest | GoatUpdateTokenWeightRequest | GoatUpdateTokenThresholdRequest
+
+    # GoatRelayerRequest is a request to the goat relayer module.
+    union GoatRelayerRequest = GoatAddVoterRequest | GoatRemoveVoterRequest
+
+    # GoatRequests are the requests sent to the goat consensus layer by a block.
+    type GoatRequests {
+        bridge: [GoatBridgeRequest!]!
+        locking: [GoatLockingRequest!]!
+        relayer: [GoatRelayerRequest!]!
+    }
+
+    # TransactionFilter restricts the transactions of a block. All fields are optional.
+    input TransactionFilter {
+        # Goat matches goat transactions only if true, and non-goat
+        # transactions only if false.
+        goat: Boolean
+        # Module matches goat transactions of the module.
+        module: Long
+        # Action matches goat transactions of the action.
+        action: Long
+    }
+
     # Transaction is an Ethereum transaction.
     type Transaction {
         # Hash is the hash of this transaction.
@@ -170,6 +350,9 @@ const schema string = `
         rawReceipt: Bytes!
         # BlobVersionedHashes is a set of hash outputs from the blobs in the transaction.
         blobVersionedHashes: [Bytes32!]
+        # Goat is the goat transaction detail. If the transaction is not a goat
+        # transaction, this field will be null.
+        goat: GoatTransaction
     }
 
     # BlockFilterCriteria encapsulates log filter criteria for a filter applied
@@ -248,7 +431,8 @@ const schema string = `
         ommerHash: Bytes32!
         # Transactions is a list of transactions associated with this block. If
         # transactions are unavailable for this block, this field will be null.
-        transactions: [Transaction!]
+        # The optional filter restricts the list by goat module and action.
+        transactions(filter: TransactionFilter): [Transaction!]
         # TransactionAt returns the transaction at the specified index. If
         # transactions are unavailable for this block, or if the index is out of
         # bounds, this field will be null.
@@ -276,6 +460,15 @@ const schema string = `
         blobGasUsed: Long
         # ExcessBlobGas is a running total of blob gas consumed in excess of the target, prior to the block.
         excessBlobGas: Long
+        # GoatTxRoot is the root of the goat transactions parsed from the extra data.
+        # If the block is not a goat block, this field will be null.
+        goatTxRoot: Bytes32
+        # GoatTxCount is the number of goat transactions parsed from the extra data.
+        # If the block is not a goat block, this field will be null.
+        goatTxCount: Long
+        # GoatRequests are the requests sent to the goat consensus layer by this block.
+        # If the requests are unavailable for this block, this field will be null.
+        goatRequests: GoatRequests
     }
 
     # CallData represents the data associated with a local contract call.
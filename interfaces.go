@@ -156,6 +156,9 @@ type CallMsg struct {
 	// For BlobTxType
 	BlobGasFeeCap *big.Int
 	BlobHashes    []common.Hash
+
+	// For SetCodeTxType
+	AuthorizationList []types.SetCodeAuthorization
 }
 
 // A ContractCaller provides contract calls, essentially transactions that are executed by
@@ -185,6 +188,13 @@ type FilterQuery struct {
 	// {{A}, {B}}         matches topic A in first position AND B in second position
 	// {{A, B}, {C, D}}   matches topic (A OR B) in first position AND (C OR D) in second position
 	Topics [][]common.Hash
+
+	// Limit and Cursor page through the matching logs. Limit is the maximum number
+	// of logs returned at once, 0 means no limit. Cursor is the opaque position to
+	// resume from, as returned with the previous page. They are only supported by
+	// paged queries.
+	Limit  uint64
+	Cursor string
 }
 
 // LogFilterer provides access to contract log events using a one-off query or continuous
@@ -41,7 +41,7 @@ import (
 // The structure can be summarized through this definition:
 //
 //	era1 := Version | block-tuple* | other-entries* | Accumulator | BlockIndex
-//	block-tuple :=  CompressedHeader | CompressedBody | CompressedReceipts | TotalDifficulty
+//	block-tuple :=  CompressedHeader | CompressedBody | CompressedReceipts | CompressedGoatRequests? | TotalDifficulty
 //
 // Each basic element is its own entry:
 //
@@ -49,10 +49,15 @@ import (
 //	CompressedHeader   = { type: [0x03, 0x00], data: snappyFramed(rlp(header)) }
 //	CompressedBody     = { type: [0x04, 0x00], data: snappyFramed(rlp(body)) }
 //	CompressedReceipts = { type: [0x05, 0x00], data: snappyFramed(rlp(receipts)) }
+//	CompressedGoatRequests = { type: [0x60, 0x00], data: snappyFramed(rlp(requests)) }
 //	TotalDifficulty    = { type: [0x06, 0x00], data: uint256(header.total_difficulty) }
 //	AccumulatorRoot    = { type: [0x07, 0x00], data: accumulator-root }
 //	BlockIndex         = { type: [0x32, 0x66], data: block-index }
 //
+// CompressedGoatRequests is only present in the archives of goat chains. The
+// requests are committed by the header requests hash, so they are covered by
+// the accumulator through the block hash.
+//
 // Accumulator is computed by constructing an SSZ list of header-records of length at most
 // 8192 and then calculating the hash_tree_root of that list.
 //
@@ -111,9 +116,41 @@ func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int)
 	return b.AddRLP(eh, eb, er, block.NumberU64(), block.Hash(), td, block.Difficulty())
 }
 
+// AddWithRequests writes a compressed block entry, compressed receipts entry
+// and compressed goat requests entry to the underlying e2store file.
+func (b *Builder) AddWithRequests(block *types.Block, receipts types.Receipts, requests [][]byte, td *big.Int) error {
+	eh, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		return err
+	}
+	eb, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		return err
+	}
+	er, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		return err
+	}
+	if requests == nil {
+		requests = [][]byte{}
+	}
+	eq, err := rlp.EncodeToBytes(requests)
+	if err != nil {
+		return err
+	}
+	return b.AddRLPWithRequests(eh, eb, er, eq, block.NumberU64(), block.Hash(), td, block.Difficulty())
+}
+
 // AddRLP writes a compressed block entry and compressed receipts entry to the
 // underlying e2store file.
 func (b *Builder) AddRLP(header, body, receipts []byte, number uint64, hash common.Hash, td, difficulty *big.Int) error {
+	return b.AddRLPWithRequests(header, body, receipts, nil, number, hash, td, difficulty)
+}
+
+// AddRLPWithRequests writes a compressed block entry, compressed receipts entry
+// and compressed goat requests entry to the underlying e2store file. The goat
+// requests entry is omitted if requests is nil.
+func (b *Builder) AddRLPWithRequests(header, body, receipts, requests []byte, number uint64, hash common.Hash, td, difficulty *big.Int) error {
 	// Write Era1 version entry before first block.
 	if b.startNum == nil {
 		n, err := b.w.Write(TypeVersion, nil)
@@ -143,6 +180,11 @@ func (b *Builder) AddRLP(header, body, receipts []byte, number uint64, hash comm
 	if err := b.snappyWrite(TypeCompressedReceipts, receipts); err != nil {
 		return err
 	}
+	if requests != nil {
+		if err := b.snappyWrite(TypeCompressedGoatRequests, requests); err != nil {
+			return err
+		}
+	}
 
 	// Also write total difficulty, but don't snappy encode.
 	btd := bigToBytes32(td)
@@ -44,6 +44,8 @@ var (
 	TypeAccumulator        uint16 = 0x07
 	TypeBlockIndex         uint16 = 0x3266
 
+	TypeCompressedGoatRequests uint16 = 0x60
+
 	MaxEra1Size = 8192
 )
 
@@ -154,6 +156,87 @@ func (e *Era) GetBlockByNumber(num uint64) (*types.Block, error) {
 	return types.NewBlockWithHeader(&header).WithBody(body), nil
 }
 
+// GetRawHeaderByNumber returns the RLP-encoded header of the given block.
+func (e *Era) GetRawHeaderByNumber(num uint64) ([]byte, error) {
+	return e.readEntry(num, TypeCompressedHeader, 0)
+}
+
+// GetRawBodyByNumber returns the RLP-encoded body of the given block.
+func (e *Era) GetRawBodyByNumber(num uint64) ([]byte, error) {
+	return e.readEntry(num, TypeCompressedBody, 1)
+}
+
+// GetRawReceiptsByNumber returns the RLP-encoded receipts of the given block.
+func (e *Era) GetRawReceiptsByNumber(num uint64) ([]byte, error) {
+	return e.readEntry(num, TypeCompressedReceipts, 2)
+}
+
+// readEntry decompresses the entry of the given type of a block, which is at
+// the given position of the block tuple.
+func (e *Era) readEntry(num uint64, typ uint16, skip int) ([]byte, error) {
+	if e.m.start > num || e.m.start+e.m.count <= num {
+		return nil, errors.New("out-of-bounds")
+	}
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < skip; i++ {
+		length, err := e.s.LengthAt(off)
+		if err != nil {
+			return nil, err
+		}
+		off += length
+	}
+	r, _, err := newSnappyReader(e.s, typ, off)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// GetGoatRequestsByNumber returns the goat requests of the given block, it
+// returns nil if the archive carries no goat requests.
+func (e *Era) GetGoatRequestsByNumber(num uint64) ([][]byte, error) {
+	if e.m.start > num || e.m.start+e.m.count <= num {
+		return nil, errors.New("out-of-bounds")
+	}
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	// Skip over the header, body and receipts.
+	for i := 0; i < 3; i++ {
+		length, err := e.s.LengthAt(off)
+		if err != nil {
+			return nil, err
+		}
+		off += length
+	}
+	r, _, err := e.goatRequestsReader(off)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	requests := [][]byte{}
+	if err := rlp.Decode(r, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// goatRequestsReader returns a reader of the goat requests entry at the given
+// offset, the reader is nil if the entry at the offset is not goat requests.
+func (e *Era) goatRequestsReader(off int64) (io.Reader, int64, error) {
+	typ, _, err := e.s.ReadMetadataAt(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	if typ != TypeCompressedGoatRequests {
+		return nil, 0, nil
+	}
+	return newSnappyReader(e.s, TypeCompressedGoatRequests, off)
+}
+
 // Accumulator reads the accumulator entry in the Era1 file.
 func (e *Era) Accumulator() (common.Hash, error) {
 	entry, err := e.s.Find(TypeAccumulator)
@@ -187,8 +270,16 @@ func (e *Era) InitialTD() (*big.Int, error) {
 	}
 	off += n
 
-	// Skip over next two records.
-	for i := 0; i < 2; i++ {
+	// Skip over the records until the total difficulty, the goat requests
+	// entry is optional.
+	for {
+		typ, _, err := e.s.ReadMetadataAt(off)
+		if err != nil {
+			return nil, err
+		}
+		if typ == TypeTotalDifficulty {
+			break
+		}
 		length, err := e.s.LengthAt(off)
 		if err != nil {
 			return nil, err
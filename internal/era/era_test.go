@@ -21,9 +21,12 @@ import (
 	"io"
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 type testchain struct {
@@ -124,6 +127,110 @@ func TestEra1Builder(t *testing.T) {
 			t.Fatalf("mismatched tds: want %s, got %s", chain.tds[i], td)
 		}
 	}
+	// Verify the raw accessors.
+	for i := uint64(0); i < uint64(len(chain.headers)); i++ {
+		for _, item := range []struct {
+			get  func(uint64) ([]byte, error)
+			want []byte
+		}{
+			{e.GetRawHeaderByNumber, chain.headers[i]},
+			{e.GetRawBodyByNumber, chain.bodies[i]},
+			{e.GetRawReceiptsByNumber, chain.receipts[i]},
+		} {
+			have, err := item.get(i)
+			if err != nil {
+				t.Fatalf("error reading entry %d: %v", i, err)
+			}
+			if !bytes.Equal(have, item.want) {
+				t.Fatalf("mismatched entry %d: want %s, got %s", i, item.want, have)
+			}
+		}
+	}
+	if _, err := e.GetRawBodyByNumber(uint64(len(chain.headers))); err == nil {
+		t.Fatal("expected error for out-of-bounds block")
+	}
+}
+
+func TestEra1BuilderGoatRequests(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.CreateTemp("", "era1-goat-test")
+	if err != nil {
+		t.Fatalf("error creating temp file: %v", err)
+	}
+	defer f.Close()
+
+	var (
+		builder  = NewBuilder(f)
+		requests [][][]byte
+	)
+	for i := 0; i < 16; i++ {
+		reqs := [][]byte{{0x60, byte(i)}, {0x61, byte(i), 0x01}}
+		if i == 0 {
+			reqs = [][]byte{}
+		}
+		requests = append(requests, reqs)
+		enc, err := rlp.EncodeToBytes(reqs)
+		if err != nil {
+			t.Fatalf("error encoding requests: %v", err)
+		}
+		header, err := rlp.EncodeToBytes(&types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(1)})
+		if err != nil {
+			t.Fatalf("error encoding header: %v", err)
+		}
+		body, receipts := []byte{'b', byte(i)}, []byte{'r', byte(i)}
+		if err := builder.AddRLPWithRequests(header, body, receipts, enc, uint64(i), common.Hash{byte(i)}, big.NewInt(int64(i+10)), big.NewInt(1)); err != nil {
+			t.Fatalf("error adding entry: %v", err)
+		}
+	}
+	if _, err := builder.Finalize(); err != nil {
+		t.Fatalf("error finalizing era1: %v", err)
+	}
+
+	e, err := Open(f.Name())
+	if err != nil {
+		t.Fatalf("failed to open era: %v", err)
+	}
+	defer e.Close()
+
+	if td, err := e.InitialTD(); err != nil {
+		t.Fatalf("error reading initial td: %v", err)
+	} else if td.Int64() != 9 {
+		t.Fatalf("mismatched initial td: want 9, got %s", td)
+	}
+	it, err := NewIterator(e)
+	if err != nil {
+		t.Fatalf("failed to make iterator: %s", err)
+	}
+	for i := 0; i < len(requests); i++ {
+		if !it.Next() {
+			t.Fatalf("expected more entries")
+		}
+		if it.Error() != nil {
+			t.Fatalf("unexpected error %v", it.Error())
+		}
+		have, err := it.GoatRequests()
+		if err != nil {
+			t.Fatalf("error reading goat requests: %v", err)
+		}
+		if have == nil || !reflect.DeepEqual(have, requests[i]) {
+			t.Fatalf("mismatched goat requests %d: want %x, got %x", i, requests[i], have)
+		}
+		td, err := it.TotalDifficulty()
+		if err != nil {
+			t.Fatalf("error reading td: %v", err)
+		}
+		if td.Int64() != int64(i+10) {
+			t.Fatalf("mismatched tds: want %d, got %s", i+10, td)
+		}
+		have, err = e.GetGoatRequestsByNumber(uint64(i))
+		if err != nil {
+			t.Fatalf("error reading goat requests by number: %v", err)
+		}
+		if !reflect.DeepEqual(have, requests[i]) {
+			t.Fatalf("mismatched goat requests %d: want %x, got %x", i, requests[i], have)
+		}
+	}
 }
 
 func TestEraFilename(t *testing.T) {
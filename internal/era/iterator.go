@@ -100,6 +100,19 @@ func (it *Iterator) BlockAndReceipts() (*types.Block, types.Receipts, error) {
 	return b, r, nil
 }
 
+// GoatRequests returns the goat requests for the iterator's current position,
+// it returns nil if the archive carries no goat requests.
+func (it *Iterator) GoatRequests() ([][]byte, error) {
+	if it.inner.GoatRequests == nil {
+		return nil, nil
+	}
+	requests := [][]byte{}
+	if err := rlp.Decode(it.inner.GoatRequests, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
 // TotalDifficulty returns the total difficulty for the iterator's current
 // position.
 func (it *Iterator) TotalDifficulty() (*big.Int, error) {
@@ -119,6 +132,7 @@ type RawIterator struct {
 	Header          io.Reader
 	Body            io.Reader
 	Receipts        io.Reader
+	GoatRequests    io.Reader // nil if the archive carries no goat requests
 	TotalDifficulty io.Reader
 }
 
@@ -166,6 +180,11 @@ func (it *RawIterator) Next() bool {
 		return true
 	}
 	off += n
+	if it.GoatRequests, n, it.err = it.e.goatRequestsReader(off); it.err != nil {
+		it.clear()
+		return true
+	}
+	off += n
 	if it.TotalDifficulty, _, it.err = it.e.s.ReaderAt(TypeTotalDifficulty, off); it.err != nil {
 		it.clear()
 		return true
@@ -193,5 +212,6 @@ func (it *RawIterator) clear() {
 	it.Header = nil
 	it.Body = nil
 	it.Receipts = nil
+	it.GoatRequests = nil
 	it.TotalDifficulty = nil
 }
@@ -38,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -237,13 +238,120 @@ func (api *TxPoolAPI) ContentFrom(addr common.Address) map[string]map[string]*RP
 	return content
 }
 
-// Status returns the number of pending and queued transaction in the pool.
-func (api *TxPoolAPI) Status() map[string]hexutil.Uint {
-	pending, queue := api.b.Stats()
-	return map[string]hexutil.Uint{
-		"pending": hexutil.Uint(pending),
-		"queued":  hexutil.Uint(queue),
+// RPCTxEvent is a step in the lifecycle of a transaction in the pool.
+type RPCTxEvent struct {
+	Hash        common.Hash        `json:"hash"`
+	Kind        txpool.TxEventKind `json:"kind"`
+	Time        time.Time          `json:"time"`
+	Source      string             `json:"source,omitempty"`
+	Reason      string             `json:"reason,omitempty"`
+	ReplacedBy  *common.Hash       `json:"replacedBy,omitempty"`
+	BlockNumber *hexutil.Uint64    `json:"blockNumber,omitempty"`
+}
+
+// newRPCTxEvent converts the lifecycle event into its RPC representation.
+func newRPCTxEvent(ev txpool.TxEvent) *RPCTxEvent {
+	result := &RPCTxEvent{
+		Hash:   ev.Hash,
+		Kind:   ev.Kind,
+		Time:   ev.Time,
+		Source: ev.Source,
+		Reason: ev.Reason,
+	}
+	switch ev.Kind {
+	case txpool.TxEventReplaced:
+		result.ReplacedBy = &ev.ReplacedBy
+	case txpool.TxEventIncluded:
+		number := hexutil.Uint64(ev.Block)
+		result.BlockNumber = &number
 	}
+	return result
+}
+
+// RPCTxLifecycle is the current status and the recent lifecycle of a transaction
+// seen by the pool.
+type RPCTxLifecycle struct {
+	Hash   common.Hash   `json:"hash"`
+	Status string        `json:"status"` // pending, queued, included, dropped or unknown
+	Events []*RPCTxEvent `json:"events"`
+}
+
+// Status returns the number of pending and queued transaction in the pool. If
+// a transaction hash is given, it returns the status of the transaction and its
+// recorded lifecycle instead: received, validated, promoted, replaced, evicted
+// or included, along with the reasons of any rejection or eviction.
+func (api *TxPoolAPI) Status(hash *common.Hash) interface{} {
+	if hash == nil {
+		pending, queue := api.b.Stats()
+		return map[string]hexutil.Uint{
+			"pending": hexutil.Uint(pending),
+			"queued":  hexutil.Uint(queue),
+		}
+	}
+	status, events := api.b.TxPoolHistory(*hash)
+	result := &RPCTxLifecycle{
+		Hash:   *hash,
+		Status: "unknown",
+		Events: make([]*RPCTxEvent, 0, len(events)),
+	}
+	for _, ev := range events {
+		result.Events = append(result.Events, newRPCTxEvent(ev))
+	}
+	switch status {
+	case txpool.TxStatusPending:
+		result.Status = "pending"
+	case txpool.TxStatusQueued:
+		result.Status = "queued"
+	default:
+		// The transaction left the pool, report how according to the history
+		if len(events) > 0 {
+			switch events[len(events)-1].Kind {
+			case txpool.TxEventIncluded:
+				result.Status = "included"
+			case txpool.TxEventRejected, txpool.TxEventReplaced, txpool.TxEventEvicted:
+				result.Status = "dropped"
+			}
+		}
+	}
+	return result
+}
+
+// Lifecycle creates a subscription that fires for the lifecycle events of the
+// transactions in the pool, optionally limited to the given hashes.
+func (api *TxPoolAPI) Lifecycle(ctx context.Context, hashes *[]common.Hash) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	var filter map[common.Hash]struct{}
+	if hashes != nil {
+		filter = make(map[common.Hash]struct{}, len(*hashes))
+		for _, hash := range *hashes {
+			filter[hash] = struct{}{}
+		}
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan txpool.TxEvent, 128)
+		eventsSub := api.b.SubscribeTxPoolHistory(events)
+		defer eventsSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-events:
+				if _, ok := filter[ev.Hash]; filter != nil && !ok {
+					continue
+				}
+				notifier.Notify(rpcSub.ID, newRPCTxEvent(ev))
+			case <-rpcSub.Err():
+				return
+			case <-eventsSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
 }
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
@@ -845,6 +953,9 @@ func (api *BlockChainAPI) GetBlockByNumber(ctx context.Context, number rpc.Block
 				response[field] = nil
 			}
 		}
+		if number >= 0 {
+			CacheFinalizedResponse(ctx, api.b, block.Header())
+		}
 		return response, nil
 	}
 	return nil, err
@@ -958,7 +1069,9 @@ func (api *BlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rp
 	for i, receipt := range receipts {
 		result[i] = marshalReceipt(receipt, block.Hash(), block.NumberU64(), signer, txs[i], i)
 	}
-
+	if number, ok := blockNrOrHash.Number(); !ok || number >= 0 {
+		CacheFinalizedResponse(ctx, api.b, block.Header())
+	}
 	return result, nil
 }
 
@@ -1441,28 +1554,29 @@ func RPCMarshalBlock(block *types.Block, inclTx bool, fullTx bool, config *param
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash           *common.Hash      `json:"blockHash"`
-	BlockNumber         *hexutil.Big      `json:"blockNumber"`
-	From                common.Address    `json:"from"`
-	Gas                 hexutil.Uint64    `json:"gas"`
-	GasPrice            *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap           *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap           *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	MaxFeePerBlobGas    *hexutil.Big      `json:"maxFeePerBlobGas,omitempty"`
-	Hash                common.Hash       `json:"hash"`
-	Input               hexutil.Bytes     `json:"input"`
-	Nonce               hexutil.Uint64    `json:"nonce"`
-	To                  *common.Address   `json:"to"`
-	TransactionIndex    *hexutil.Uint64   `json:"transactionIndex"`
-	Value               *hexutil.Big      `json:"value"`
-	Type                hexutil.Uint64    `json:"type"`
-	Accesses            *types.AccessList `json:"accessList,omitempty"`
-	ChainID             *hexutil.Big      `json:"chainId,omitempty"`
-	BlobVersionedHashes []common.Hash     `json:"blobVersionedHashes,omitempty"`
-	V                   *hexutil.Big      `json:"v"`
-	R                   *hexutil.Big      `json:"r"`
-	S                   *hexutil.Big      `json:"s"`
-	YParity             *hexutil.Uint64   `json:"yParity,omitempty"`
+	BlockHash           *common.Hash                 `json:"blockHash"`
+	BlockNumber         *hexutil.Big                 `json:"blockNumber"`
+	From                common.Address               `json:"from"`
+	Gas                 hexutil.Uint64               `json:"gas"`
+	GasPrice            *hexutil.Big                 `json:"gasPrice"`
+	GasFeeCap           *hexutil.Big                 `json:"maxFeePerGas,omitempty"`
+	GasTipCap           *hexutil.Big                 `json:"maxPriorityFeePerGas,omitempty"`
+	MaxFeePerBlobGas    *hexutil.Big                 `json:"maxFeePerBlobGas,omitempty"`
+	Hash                common.Hash                  `json:"hash"`
+	Input               hexutil.Bytes                `json:"input"`
+	Nonce               hexutil.Uint64               `json:"nonce"`
+	To                  *common.Address              `json:"to"`
+	TransactionIndex    *hexutil.Uint64              `json:"transactionIndex"`
+	Value               *hexutil.Big                 `json:"value"`
+	Type                hexutil.Uint64               `json:"type"`
+	Accesses            *types.AccessList            `json:"accessList,omitempty"`
+	ChainID             *hexutil.Big                 `json:"chainId,omitempty"`
+	BlobVersionedHashes []common.Hash                `json:"blobVersionedHashes,omitempty"`
+	AuthorizationList   []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+	V                   *hexutil.Big                 `json:"v"`
+	R                   *hexutil.Big                 `json:"r"`
+	S                   *hexutil.Big                 `json:"s"`
+	YParity             *hexutil.Uint64              `json:"yParity,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1537,6 +1651,22 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		}
 		result.MaxFeePerBlobGas = (*hexutil.Big)(tx.BlobGasFeeCap())
 		result.BlobVersionedHashes = tx.BlobHashes()
+
+	case types.SetCodeTxType:
+		al := tx.AccessList()
+		yparity := hexutil.Uint64(v.Sign())
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+		result.YParity = &yparity
+		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		// if the transaction has been mined, compute the effective gas price
+		if baseFee != nil && blockHash != (common.Hash{}) {
+			result.GasPrice = (*hexutil.Big)(effectiveGasPrice(tx, baseFee))
+		} else {
+			result.GasPrice = (*hexutil.Big)(tx.GasFeeCap())
+		}
+		result.AuthorizationList = tx.SetCodeAuthorizations()
 	}
 	return result
 }
@@ -1825,9 +1955,33 @@ func (api *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash commo
 
 	// Derive the sender.
 	signer := types.MakeSigner(api.b.ChainConfig(), header.Number, header.Time)
+	CacheFinalizedResponse(ctx, api.b, header)
 	return marshalReceipt(receipt, blockHash, blockNumber, signer, tx, int(index)), nil
 }
 
+// HeaderReader retrieves the headers of the chain by number.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
+}
+
+// CacheFinalizedResponse marks the response of the call as cacheable if it's
+// derived from a finalized canonical block. Callers must ensure the response
+// is fully determined by the block and the parameters of the call.
+func CacheFinalizedResponse(ctx context.Context, b HeaderReader, header *types.Header) {
+	if !rpc.CachingResponse(ctx) {
+		return
+	}
+	finalized, err := b.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
+	if err != nil || finalized == nil || header.Number.Cmp(finalized.Number) > 0 {
+		return
+	}
+	canonical, err := b.HeaderByNumber(ctx, rpc.BlockNumber(header.Number.Int64()))
+	if err != nil || canonical == nil || canonical.Hash() != header.Hash() {
+		return
+	}
+	rpc.CacheResponse(ctx, header.Number.Uint64(), header.Hash())
+}
+
 // marshalReceipt marshals a transaction receipt into a JSON object.
 func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber uint64, signer types.Signer, tx *types.Transaction, txIndex int) map[string]interface{} {
 	from, _ := types.Sender(signer, tx)
@@ -1885,6 +2039,13 @@ func (api *TransactionAPI) sign(addr common.Address, tx *types.Transaction) (*ty
 
 // SubmitTransaction is a helper function that submits tx to txPool and logs a message.
 func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
+	return submitTransaction(ctx, b, tx, false)
+}
+
+// submitTransaction is a helper function that submits tx to txPool, either as
+// a regular transaction or as a private one which is never announced to the
+// network, and logs a message.
+func submitTransaction(ctx context.Context, b Backend, tx *types.Transaction, private bool) (common.Hash, error) {
 	// If the transaction fee cap is already specified, ensure the
 	// fee of the given transaction is _reasonable_.
 	if err := checkTxFee(tx.GasPrice(), tx.Gas(), b.RPCTxFeeCap()); err != nil {
@@ -1894,7 +2055,11 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (c
 		// Ensure only eip155 signed transactions are submitted if EIP155Required is set.
 		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
 	}
-	if err := b.SendTx(ctx, tx); err != nil {
+	send := b.SendTx
+	if private {
+		send = b.SendPrivateTx
+	}
+	if err := send(ctx, tx); err != nil {
 		return common.Hash{}, err
 	}
 	// Print a log with full tx details for manual investigations and interventions
@@ -1978,6 +2143,47 @@ func (api *TransactionAPI) SendRawTransaction(ctx context.Context, input hexutil
 	return SubmitTransaction(ctx, api.b, tx)
 }
 
+// SendPrivateTransaction will add the signed transaction to the transaction pool
+// as a private one, which is only included in the blocks built by this node and
+// never announced to the network. It's dropped if it's not included within the
+// configured number of blocks.
+func (api *TransactionAPI) SendPrivateTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	return submitTransaction(ctx, api.b, tx, true)
+}
+
+// maxConditionalCost is the maximum number of storage lookups the preconditions
+// of a conditional transaction may need.
+const maxConditionalCost = 1000
+
+// SendRawTransactionConditional will add the signed transaction to the transaction
+// pool together with its preconditions. Like private transactions, it's only
+// included in locally built blocks and never announced to the network, so the
+// preconditions can't be bypassed by other block builders. The transaction is
+// dropped once its preconditions can't be met anymore.
+func (api *TransactionAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, cond types.TransactionConditional) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if cost := cond.Cost(); cost > maxConditionalCost {
+		return common.Hash{}, &invalidParamsError{fmt.Sprintf("conditional cost %d exceeds limit %d", cost, maxConditionalCost)}
+	}
+	if err := cond.Validate(); err != nil {
+		return common.Hash{}, &invalidParamsError{err.Error()}
+	}
+	tx.SetConditional(&cond)
+
+	hash, err := submitTransaction(ctx, api.b, tx, true)
+	if errors.Is(err, types.ErrConditionalFailed) {
+		return common.Hash{}, &conditionalFailedError{err.Error()}
+	}
+	return hash, err
+}
+
 // Sign calculates an ECDSA signature for:
 // keccak256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
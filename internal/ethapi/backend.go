@@ -27,8 +27,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -75,6 +75,7 @@ type Backend interface {
 
 	// Transaction pool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
+	SendPrivateTx(ctx context.Context, signedTx *types.Transaction) error
 	GetTransaction(ctx context.Context, txHash common.Hash) (bool, *types.Transaction, common.Hash, uint64, uint64, error)
 	GetPoolTransactions() (types.Transactions, error)
 	GetPoolTransaction(txHash common.Hash) *types.Transaction
@@ -83,6 +84,8 @@ type Backend interface {
 	TxPoolContent() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction)
 	TxPoolContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
+	TxPoolHistory(hash common.Hash) (txpool.TxStatus, []txpool.TxEvent)
+	SubscribeTxPoolHistory(ch chan<- txpool.TxEvent) event.Subscription
 
 	ChainConfig() *params.ChainConfig
 	Engine() consensus.Engine
@@ -94,16 +97,16 @@ type Backend interface {
 	GetLogs(ctx context.Context, blockHash common.Hash, number uint64) ([][]*types.Log, error)
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
-	BloomStatus() (uint64, uint64)
-	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
+	LogIndexStatus() (uint64, uint64, uint64)
 }
 
 func GetAPIs(apiBackend Backend) []rpc.API {
 	nonceLock := new(AddrLocker)
 	return []rpc.API{
 		{
-			Namespace: "eth",
-			Service:   NewEthereumAPI(apiBackend),
+			Namespace:   "eth",
+			Service:     NewEthereumAPI(apiBackend),
+			Description: "Chain state, blocks, transactions and logs",
 		}, {
 			Namespace: "eth",
 			Service:   NewBlockChainAPI(apiBackend),
@@ -111,17 +114,20 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Namespace: "eth",
 			Service:   NewTransactionAPI(apiBackend, nonceLock),
 		}, {
-			Namespace: "txpool",
-			Service:   NewTxPoolAPI(apiBackend),
+			Namespace:   "txpool",
+			Service:     NewTxPoolAPI(apiBackend),
+			Description: "Transaction pool contents and status",
 		}, {
-			Namespace: "debug",
-			Service:   NewDebugAPI(apiBackend),
+			Namespace:   "debug",
+			Service:     NewDebugAPI(apiBackend),
+			Description: "Tracing, raw chain data and node diagnostics",
 		}, {
 			Namespace: "eth",
 			Service:   NewEthereumAccountAPI(apiBackend.AccountManager()),
 		}, {
-			Namespace: "personal",
-			Service:   NewPersonalAccountAPI(apiBackend, nonceLock),
+			Namespace:   "personal",
+			Service:     NewPersonalAccountAPI(apiBackend, nonceLock),
+			Description: "Deprecated account management",
 		},
 	}
 }
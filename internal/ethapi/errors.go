@@ -79,6 +79,23 @@ func (e *TxIndexingError) ErrorCode() int {
 // ErrorData returns the hex encoded revert reason.
 func (e *TxIndexingError) ErrorData() interface{} { return "transaction indexing is in progress" }
 
+// PrunedHistoryError is an API error that indicates the requested block body or
+// receipts have been pruned from the local history.
+type PrunedHistoryError struct{}
+
+// NewPrunedHistoryError creates a PrunedHistoryError instance.
+func NewPrunedHistoryError() *PrunedHistoryError { return &PrunedHistoryError{} }
+
+// Error implement error interface, returning the error message.
+func (e *PrunedHistoryError) Error() string {
+	return "pruned history unavailable"
+}
+
+// ErrorCode returns the JSON error code for the pruned history.
+func (e *PrunedHistoryError) ErrorCode() int {
+	return errCodePrunedHistory
+}
+
 type callError struct {
 	Message string `json:"message"`
 	Code    int    `json:"code"`
@@ -104,10 +121,12 @@ const (
 	errCodeSenderIsNotEOA          = -38024
 	errCodeMaxInitCodeSizeExceeded = -38025
 	errCodeClientLimitExceeded     = -38026
+	errCodeConditionalFailed       = -32003
 	errCodeInternalError           = -32603
 	errCodeInvalidParams           = -32602
 	errCodeReverted                = -32000
 	errCodeVMError                 = -32015
+	errCodePrunedHistory           = 4444
 )
 
 func txValidationError(err error) *invalidTxError {
@@ -168,3 +187,8 @@ type blockGasLimitReachedError struct{ message string }
 
 func (e *blockGasLimitReachedError) Error() string  { return e.message }
 func (e *blockGasLimitReachedError) ErrorCode() int { return errCodeBlockGasLimitReached }
+
+type conditionalFailedError struct{ message string }
+
+func (e *conditionalFailedError) Error() string  { return e.message }
+func (e *conditionalFailedError) ErrorCode() int { return errCodeConditionalFailed }
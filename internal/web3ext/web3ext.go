@@ -22,6 +22,7 @@ var Modules = map[string]string{
 	"clique":   CliqueJs,
 	"debug":    DebugJs,
 	"eth":      EthJs,
+	"goat":     GoatJs,
 	"miner":    MinerJs,
 	"net":      NetJs,
 	"personal": PersonalJs,
@@ -159,6 +160,11 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'setTxPoolPolicy',
+			call: 'admin_setTxPoolPolicy',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -173,6 +179,10 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'txPoolPolicy',
+			getter: 'admin_txPoolPolicy'
+		}),
 	]
 });
 `
@@ -579,6 +589,11 @@ web3._extend({
 			call: 'eth_getLogs',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'getLogsPage',
+			call: 'eth_getLogsPage',
+			params: 1,
+		}),
 		new web3._extend.Method({
 			name: 'call',
 			call: 'eth_call',
@@ -596,6 +611,26 @@ web3._extend({
 			call: 'eth_getBlockReceipts',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'sendPrivateTransaction',
+			call: 'eth_sendPrivateTransaction',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'sendRawTransactionConditional',
+			call: 'eth_sendRawTransactionConditional',
+			params: 2,
+		}),
+		new web3._extend.Method({
+			name: 'sendBundle',
+			call: 'eth_sendBundle',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'callBundle',
+			call: 'eth_callBundle',
+			params: 1,
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -640,6 +675,11 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.utils.fromDecimal]
 		}),
+		new web3._extend.Method({
+			name: 'setOrderingPolicy',
+			call: 'miner_setOrderingPolicy',
+			params: 1,
+		}),
 	],
 	properties: []
 });
@@ -717,7 +757,13 @@ web3._extend({
 const RpcJs = `
 web3._extend({
 	property: 'rpc',
-	methods: [],
+	methods: [
+		new web3._extend.Method({
+			name: 'discover',
+			call: 'rpc.discover',
+			params: 0
+		}),
+	],
 	properties: [
 		new web3._extend.Property({
 			name: 'modules',
@@ -755,6 +801,11 @@ web3._extend({
 			call: 'txpool_contentFrom',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'transactionStatus',
+			call: 'txpool_status',
+			params: 1,
+		}),
 	]
 });
 `
@@ -777,3 +828,24 @@ web3._extend({
 	],
 });
 `
+
+const GoatJs = `
+web3._extend({
+	property: 'goat',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getRewards',
+			call: 'goat_getRewards',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getGasRevenue',
+			call: 'goat_getGasRevenue',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+	],
+});
+`
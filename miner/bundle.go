@@ -0,0 +1,375 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// maxBundles is the maximum number of bundles waiting for inclusion.
+	maxBundles = 1024
+
+	// maxBundleTxs is the maximum number of transactions in a bundle.
+	maxBundleTxs = 64
+
+	// maxBundleBlocks is the maximum distance of the last block of a submitted
+	// bundle from the current head.
+	maxBundleBlocks = 32
+
+	// maxBundleSimulations is the maximum number of bundles simulated when
+	// building a block, the others wait for the next one.
+	maxBundleSimulations = 32
+)
+
+var (
+	errEmptyBundle        = errors.New("bundle has no transactions")
+	errBundleTooLarge     = errors.New("bundle has too many transactions")
+	errBundleRange        = errors.New("invalid bundle block range")
+	errBundleUnbounded    = fmt.Errorf("bundle max block must be within %d blocks of the head", maxBundleBlocks)
+	errBundleExpired      = errors.New("bundle block range has passed")
+	errBundleKnown        = errors.New("bundle already known")
+	errBundlePoolFull     = errors.New("bundle pool is full")
+	errBundleTxType       = errors.New("blob and goat transactions are not allowed in bundles")
+	errBundleReverted     = errors.New("bundle transaction reverted")
+	errBundleUnprofitable = errors.New("bundle doesn't pay the minimum tip")
+)
+
+// Bundle is an ordered list of transactions which are included atomically,
+// either all of them in sequence at the top of the block or none of them.
+type Bundle struct {
+	Txs          types.Transactions
+	MinBlock     uint64        // First block the bundle can be included in, 0 if unbounded
+	MaxBlock     uint64        // Last block the bundle can be included in, 0 if unbounded (simulation only)
+	RevertingTxs []common.Hash // Transactions of the bundle allowed to revert
+}
+
+// Hash returns the identifier of the bundle, which is the hash of the
+// transaction hashes.
+func (b *Bundle) Hash() common.Hash {
+	hashes := make([]byte, 0, len(b.Txs)*common.HashLength)
+	for _, tx := range b.Txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// eligible reports whether the bundle can be included in the given block.
+func (b *Bundle) eligible(number uint64) bool {
+	return number >= b.MinBlock && !b.expired(number)
+}
+
+// expired reports whether the block range of the bundle ends before the given
+// block.
+func (b *Bundle) expired(number uint64) bool {
+	return b.MaxBlock != 0 && number > b.MaxBlock
+}
+
+// BundleResult is the outcome of executing a bundle.
+type BundleResult struct {
+	Hash     common.Hash
+	GasUsed  uint64
+	Profit   *big.Int         // Fees and direct payments received by the fee recipient
+	Receipts []*types.Receipt // Receipts of the bundle transactions
+}
+
+// bundlePool is the set of bundles waiting for inclusion, in arrival order.
+type bundlePool struct {
+	bundles  []*Bundle
+	hashes   map[common.Hash]struct{}
+	included map[common.Hash]uint64 // Blocks the bundles were last built into
+	lock     sync.Mutex
+}
+
+func newBundlePool() *bundlePool {
+	return &bundlePool{
+		hashes:   make(map[common.Hash]struct{}),
+		included: make(map[common.Hash]uint64),
+	}
+}
+
+// add inserts the bundle into the pool.
+func (p *bundlePool) add(bundle *Bundle) (common.Hash, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	hash := bundle.Hash()
+	if _, ok := p.hashes[hash]; ok {
+		return common.Hash{}, errBundleKnown
+	}
+	if len(p.bundles) >= maxBundles {
+		return common.Hash{}, errBundlePoolFull
+	}
+	p.bundles = append(p.bundles, bundle)
+	p.hashes[hash] = struct{}{}
+	return hash, nil
+}
+
+// eligible removes the expired bundles and the ones included in a canonical
+// block below the given one, and returns the ones which can be included in it.
+// The included callback reports whether a bundle is part of a canonical block.
+func (p *bundlePool) eligible(number uint64, included func(bundle *Bundle, number uint64) bool) []*Bundle {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var eligible []*Bundle
+	p.bundles = slices.DeleteFunc(p.bundles, func(bundle *Bundle) bool {
+		hash := bundle.Hash()
+		if bundle.expired(number) {
+			delete(p.hashes, hash)
+			delete(p.included, hash)
+			return true
+		}
+		// The bundle was built into an earlier block, drop it if that block
+		// made it into the chain.
+		if built, ok := p.included[hash]; ok && built < number {
+			delete(p.included, hash)
+			if included != nil && included(bundle, built) {
+				delete(p.hashes, hash)
+				return true
+			}
+		}
+		if bundle.eligible(number) {
+			eligible = append(eligible, bundle)
+		}
+		return false
+	})
+	return eligible
+}
+
+// markIncluded records that the bundle was built into the given block, it's
+// dropped once the block is canonical.
+func (p *bundlePool) markIncluded(bundle *Bundle, number uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	hash := bundle.Hash()
+	if _, ok := p.hashes[hash]; ok {
+		p.included[hash] = number
+	}
+}
+
+// remove drops the bundle from the pool.
+func (p *bundlePool) remove(bundle *Bundle) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	hash := bundle.Hash()
+	if _, ok := p.hashes[hash]; !ok {
+		return
+	}
+	delete(p.hashes, hash)
+	delete(p.included, hash)
+	p.bundles = slices.DeleteFunc(p.bundles, func(b *Bundle) bool {
+		return b.Hash() == hash
+	})
+}
+
+// requeue moves the bundle to the back of the pool, so that the bundles which
+// can't be included yet don't starve the others of simulations.
+func (p *bundlePool) requeue(bundle *Bundle) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	hash := bundle.Hash()
+	index := slices.IndexFunc(p.bundles, func(b *Bundle) bool {
+		return b.Hash() == hash
+	})
+	if index < 0 {
+		return
+	}
+	p.bundles = append(slices.Delete(p.bundles, index, index+1), bundle)
+}
+
+// validateBundle checks the bundle is well-formed and signed.
+func (miner *Miner) validateBundle(bundle *Bundle) error {
+	if len(bundle.Txs) == 0 {
+		return errEmptyBundle
+	}
+	if len(bundle.Txs) > maxBundleTxs {
+		return errBundleTooLarge
+	}
+	if bundle.MaxBlock != 0 && bundle.MaxBlock < bundle.MinBlock {
+		return errBundleRange
+	}
+	if bundle.expired(miner.chain.CurrentBlock().Number.Uint64() + 1) {
+		return errBundleExpired
+	}
+	signer := types.LatestSigner(miner.chainConfig)
+	for _, tx := range bundle.Txs {
+		if tx.Type() == types.BlobTxType || tx.IsGoatTx() {
+			return errBundleTxType
+		}
+		if _, err := types.Sender(signer, tx); err != nil {
+			return fmt.Errorf("invalid bundle transaction %s: %w", tx.Hash(), err)
+		}
+	}
+	return nil
+}
+
+// SendBundle validates the bundle and adds it to the set of bundles included
+// in the blocks built within its block range, which must end within a bounded
+// number of blocks of the head.
+func (miner *Miner) SendBundle(bundle *Bundle) (common.Hash, error) {
+	if err := miner.validateBundle(bundle); err != nil {
+		return common.Hash{}, err
+	}
+	if bundle.MaxBlock == 0 || bundle.MaxBlock > miner.chain.CurrentBlock().Number.Uint64()+maxBundleBlocks {
+		return common.Hash{}, errBundleUnbounded
+	}
+	return miner.bundles.add(bundle)
+}
+
+// CallBundle simulates the bundle on top of the current head in the context
+// of the next block, regardless of its block range and revert protection.
+func (miner *Miner) CallBundle(bundle *Bundle) (*BundleResult, error) {
+	if err := miner.validateBundle(bundle); err != nil {
+		return nil, err
+	}
+	header := miner.chain.CurrentHeader()
+	env, err := miner.prepareWork(&generateParams{
+		timestamp:  uint64(time.Now().Unix()),
+		parentHash: header.Hash(),
+		coinbase:   miner.config.PendingFeeRecipient,
+		beaconRoot: header.ParentBeaconRoot,
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	return miner.simulateBundle(env, bundle)
+}
+
+// simulateBundle executes the bundle transactions on the given environment and
+// returns the gas used and the profit of the fee recipient. The execution stops
+// at the first invalid transaction, the reverted ones are left to the caller.
+func (miner *Miner) simulateBundle(env *environment, bundle *Bundle) (*BundleResult, error) {
+	var (
+		result = &BundleResult{Hash: bundle.Hash(), Profit: new(big.Int)}
+		before = env.state.GetBalance(env.coinbase).ToBig()
+	)
+	for _, tx := range bundle.Txs {
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if err := miner.commitTransaction(env, tx); err != nil {
+			return nil, fmt.Errorf("bundle transaction %s: %w", tx.Hash(), err)
+		}
+		receipt := env.receipts[len(env.receipts)-1]
+		result.GasUsed += receipt.GasUsed
+		result.Receipts = append(result.Receipts, receipt)
+
+		// The fees are not credited to the fee recipient on goat network, they
+		// are distributed at the end of the block.
+		if miner.chainConfig.Goat != nil {
+			tip, _ := tx.EffectiveGasTip(env.header.BaseFee)
+			result.Profit.Add(result.Profit, new(big.Int).Mul(tip, new(big.Int).SetUint64(receipt.GasUsed)))
+		}
+	}
+	result.Profit.Add(result.Profit, new(big.Int).Sub(env.state.GetBalance(env.coinbase).ToBig(), before))
+	return result, nil
+}
+
+// commitBundle includes the bundle in the block if all of its transactions are
+// valid, none of them reverts unless allowed, and it pays at least the minimum
+// tip for its gas. As the state journal is flushed after every transaction, the
+// bundle is simulated on a copy of the environment first and only applied if
+// it's accepted as a whole.
+func (miner *Miner) commitBundle(env *environment, bundle *Bundle, tip *big.Int) error {
+	result, err := miner.simulateBundle(env.copy(), bundle)
+	if err != nil {
+		return err
+	}
+	for i, receipt := range result.Receipts {
+		if receipt.Status == types.ReceiptStatusFailed && !slices.Contains(bundle.RevertingTxs, bundle.Txs[i].Hash()) {
+			return fmt.Errorf("%w: %s", errBundleReverted, bundle.Txs[i].Hash())
+		}
+	}
+	minimum := new(big.Int)
+	if tip != nil {
+		minimum.Mul(tip, new(big.Int).SetUint64(result.GasUsed))
+	}
+	if result.Profit.Sign() <= 0 || result.Profit.Cmp(minimum) < 0 {
+		return errBundleUnprofitable
+	}
+	for _, tx := range bundle.Txs {
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if err := miner.commitTransaction(env, tx); err != nil {
+			log.Error("Failed to apply simulated bundle", "hash", result.Hash, "tx", tx.Hash(), "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// bundleIncluded reports whether the bundle is part of the canonical block of
+// the given number.
+func (miner *Miner) bundleIncluded(bundle *Bundle, number uint64) bool {
+	block := miner.chain.GetBlockByNumber(number)
+	return block != nil && block.Transaction(bundle.Txs[0].Hash()) != nil
+}
+
+// transientBundleError reports whether the bundle failed because of the block
+// being built or the current chain conditions, and may succeed in a later one.
+func transientBundleError(err error) bool {
+	return errors.Is(err, core.ErrNonceTooHigh) ||
+		errors.Is(err, core.ErrGasLimitReached) ||
+		errors.Is(err, core.ErrFeeCapTooLow) ||
+		errors.Is(err, errBundleUnprofitable)
+}
+
+// commitBundles includes the eligible bundles in arrival order ahead of the
+// pending transactions of the txpool, simulating a limited number of them per
+// block. The bundles which failed for good and the ones included in the chain
+// are dropped, the ones which may succeed later are moved to the back.
+func (miner *Miner) commitBundles(env *environment, tip *big.Int, interrupt *atomic.Int32) error {
+	number := env.header.Number.Uint64()
+	for i, bundle := range miner.bundles.eligible(number, miner.bundleIncluded) {
+		if i >= maxBundleSimulations {
+			break
+		}
+		if interrupt != nil {
+			if signal := interrupt.Load(); signal != commitInterruptNone {
+				return signalToErr(signal)
+			}
+		}
+		err := miner.commitBundle(env, bundle, tip)
+		switch {
+		case err == nil:
+			log.Debug("Included bundle", "hash", bundle.Hash(), "txs", len(bundle.Txs), "number", env.header.Number)
+			miner.bundles.markIncluded(bundle, number)
+
+		case transientBundleError(err):
+			log.Trace("Skipping bundle", "hash", bundle.Hash(), "err", err)
+			miner.bundles.requeue(bundle)
+
+		default:
+			log.Debug("Dropping failed bundle", "hash", bundle.Hash(), "err", err)
+			miner.bundles.remove(bundle)
+		}
+	}
+	return nil
+}
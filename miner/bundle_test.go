@@ -0,0 +1,268 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newBundleTestWorker creates a miner with a minimum tip and a fee recipient
+// distinct from the funded test account.
+func newBundleTestWorker(t *testing.T, recipient common.Address) (*Miner, *testWorkerBackend) {
+	backend := newTestWorkerBackend(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	backend.txPool.Add(pendingTxs, true, true)
+
+	config := testConfig
+	config.PendingFeeRecipient = recipient
+	config.GasPrice = big.NewInt(params.GWei / 10)
+	return New(backend, config, ethash.NewFaker()), backend
+}
+
+// makeBundleTxs creates a transfer and a reverting contract creation of the
+// funded test account with the given tip.
+func makeBundleTxs(tip int64) types.Transactions {
+	signer := types.LatestSigner(params.TestChainConfig)
+	return types.Transactions{
+		types.MustSignNewTx(testBankKey, signer, &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     0,
+			To:        &testUserAddress,
+			Value:     big.NewInt(1000),
+			Gas:       params.TxGas,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: big.NewInt(2 * params.GWei),
+		}),
+		types.MustSignNewTx(testBankKey, signer, &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     1,
+			Gas:       100000,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: big.NewInt(2 * params.GWei),
+			Data:      hexutil.MustDecode("0x60006000fd"), // PUSH1 0 PUSH1 0 REVERT
+		}),
+	}
+}
+
+func TestBundleInclusion(t *testing.T) {
+	var (
+		recipient = common.HexToAddress("0xdeadbeef")
+		txs       = makeBundleTxs(params.GWei)
+		pool      = types.Transactions{pendingTxs[0]}
+	)
+	tests := []struct {
+		name   string
+		bundle *Bundle
+		want   types.Transactions
+	}{
+		{
+			name:   "revert not allowed",
+			bundle: &Bundle{Txs: txs, MaxBlock: 1},
+			want:   pool,
+		},
+		{
+			name:   "revert allowed",
+			bundle: &Bundle{Txs: txs, MaxBlock: 1, RevertingTxs: []common.Hash{txs[1].Hash()}},
+			want:   txs,
+		},
+		{
+			name:   "not profitable",
+			bundle: &Bundle{Txs: makeBundleTxs(params.GWei / 100), MaxBlock: 1, RevertingTxs: []common.Hash{txs[1].Hash()}},
+			want:   pool,
+		},
+		{
+			name:   "future block range",
+			bundle: &Bundle{Txs: txs, MinBlock: 2, MaxBlock: 2, RevertingTxs: []common.Hash{txs[1].Hash()}},
+			want:   pool,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w, b := newBundleTestWorker(t, recipient)
+			if _, err := w.SendBundle(test.bundle); err != nil {
+				t.Fatalf("failed to send bundle: %v", err)
+			}
+			result := w.generateWork(&generateParams{
+				parentHash: b.chain.CurrentBlock().Hash(),
+				timestamp:  uint64(time.Now().Unix()),
+				coinbase:   recipient,
+			}, false)
+			if result.err != nil {
+				t.Fatalf("failed to generate work: %v", result.err)
+			}
+			have := result.block.Transactions()
+			if len(have) != len(test.want) {
+				t.Fatalf("transaction count mismatch: have %d, want %d", len(have), len(test.want))
+			}
+			for i, tx := range have {
+				if tx.Hash() != test.want[i].Hash() {
+					t.Errorf("transaction %d mismatch: have %x, want %x", i, tx.Hash(), test.want[i].Hash())
+				}
+			}
+		})
+	}
+}
+
+func TestBundlePool(t *testing.T) {
+	w, _ := newBundleTestWorker(t, common.HexToAddress("0xdeadbeef"))
+
+	txs := makeBundleTxs(params.GWei)
+	if _, err := w.SendBundle(&Bundle{}); !errors.Is(err, errEmptyBundle) {
+		t.Fatalf("empty bundle: have %v, want %v", err, errEmptyBundle)
+	}
+	if _, err := w.SendBundle(&Bundle{Txs: txs, MinBlock: 3, MaxBlock: 2}); !errors.Is(err, errBundleRange) {
+		t.Fatalf("invalid range: have %v, want %v", err, errBundleRange)
+	}
+	if _, err := w.SendBundle(&Bundle{Txs: txs}); !errors.Is(err, errBundleUnbounded) {
+		t.Fatalf("unbounded range: have %v, want %v", err, errBundleUnbounded)
+	}
+	if _, err := w.SendBundle(&Bundle{Txs: txs, MaxBlock: maxBundleBlocks + 1}); !errors.Is(err, errBundleUnbounded) {
+		t.Fatalf("distant range: have %v, want %v", err, errBundleUnbounded)
+	}
+	hash, err := w.SendBundle(&Bundle{Txs: txs, MaxBlock: 1})
+	if err != nil {
+		t.Fatalf("failed to send bundle: %v", err)
+	}
+	if want := (&Bundle{Txs: txs}).Hash(); hash != want {
+		t.Fatalf("bundle hash mismatch: have %x, want %x", hash, want)
+	}
+	if _, err := w.SendBundle(&Bundle{Txs: txs, MaxBlock: 1}); !errors.Is(err, errBundleKnown) {
+		t.Fatalf("duplicate bundle: have %v, want %v", err, errBundleKnown)
+	}
+	if eligible := w.bundles.eligible(1, nil); len(eligible) != 1 {
+		t.Fatalf("eligible bundle count mismatch: have %d, want 1", len(eligible))
+	}
+	if eligible := w.bundles.eligible(2, nil); len(eligible) != 0 || len(w.bundles.bundles) != 0 {
+		t.Fatalf("expired bundle is not dropped")
+	}
+}
+
+// Tests that the bundles are dropped once they fail for good or are included
+// in the chain, and the transient failures are retried later.
+func TestBundleEviction(t *testing.T) {
+	var (
+		recipient = common.HexToAddress("0xdeadbeef")
+		txs       = makeBundleTxs(params.GWei)
+		cheap     = makeBundleTxs(params.GWei / 100)
+	)
+	w, b := newBundleTestWorker(t, recipient)
+	build := func() *types.Block {
+		t.Helper()
+		result := w.generateWork(&generateParams{
+			parentHash: b.chain.CurrentBlock().Hash(),
+			timestamp:  uint64(time.Now().Unix()),
+			coinbase:   recipient,
+		}, false)
+		if result.err != nil {
+			t.Fatalf("failed to generate work: %v", result.err)
+		}
+		return result.block
+	}
+	reverting, _ := w.SendBundle(&Bundle{Txs: txs, MaxBlock: 2})
+	unprofitable, _ := w.SendBundle(&Bundle{Txs: cheap, MaxBlock: 2, RevertingTxs: []common.Hash{cheap[1].Hash()}})
+	build()
+	if _, ok := w.bundles.hashes[reverting]; ok {
+		t.Error("reverting bundle is not dropped")
+	}
+	if _, ok := w.bundles.hashes[unprofitable]; !ok {
+		t.Error("unprofitable bundle is dropped")
+	}
+	// Included bundles are kept until their block is canonical
+	included, _ := w.SendBundle(&Bundle{Txs: txs, MaxBlock: 2, RevertingTxs: []common.Hash{txs[1].Hash()}})
+	block := build()
+	if block.Transaction(txs[0].Hash()) == nil {
+		t.Fatal("bundle not included")
+	}
+	if _, ok := w.bundles.hashes[included]; !ok {
+		t.Fatal("bundle dropped before its block is canonical")
+	}
+	if _, err := b.chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	w.bundles.eligible(2, w.bundleIncluded)
+	if _, ok := w.bundles.hashes[included]; ok {
+		t.Error("included bundle is not dropped")
+	}
+}
+
+// Tests that only a limited number of bundles is simulated per block, and the
+// skipped ones are retried first in the next one.
+func TestBundleSimulationCap(t *testing.T) {
+	recipient := common.HexToAddress("0xdeadbeef")
+	w, b := newBundleTestWorker(t, recipient)
+
+	// Send more unprofitable bundles than simulated per block
+	var hashes []common.Hash
+	for i := 0; i <= maxBundleSimulations; i++ {
+		txs := makeBundleTxs(int64(i + 1))
+		hash, err := w.SendBundle(&Bundle{Txs: txs, MaxBlock: 1, RevertingTxs: []common.Hash{txs[1].Hash()}})
+		if err != nil {
+			t.Fatalf("failed to send bundle %d: %v", i, err)
+		}
+		hashes = append(hashes, hash)
+	}
+	result := w.generateWork(&generateParams{
+		parentHash: b.chain.CurrentBlock().Hash(),
+		timestamp:  uint64(time.Now().Unix()),
+		coinbase:   recipient,
+	}, false)
+	if result.err != nil {
+		t.Fatalf("failed to generate work: %v", result.err)
+	}
+	if len(w.bundles.bundles) != len(hashes) {
+		t.Fatalf("bundle count mismatch: have %d, want %d", len(w.bundles.bundles), len(hashes))
+	}
+	if have := w.bundles.bundles[0].Hash(); have != hashes[maxBundleSimulations] {
+		t.Errorf("skipped bundle not first: have %x, want %x", have, hashes[maxBundleSimulations])
+	}
+}
+
+func TestCallBundle(t *testing.T) {
+	recipient := common.HexToAddress("0xdeadbeef")
+	w, _ := newBundleTestWorker(t, recipient)
+
+	txs := makeBundleTxs(params.GWei)
+	result, err := w.CallBundle(&Bundle{Txs: txs})
+	if err != nil {
+		t.Fatalf("failed to call bundle: %v", err)
+	}
+	if len(result.Receipts) != 2 {
+		t.Fatalf("receipt count mismatch: have %d, want 2", len(result.Receipts))
+	}
+	if result.Receipts[0].Status != types.ReceiptStatusSuccessful || result.Receipts[1].Status != types.ReceiptStatusFailed {
+		t.Fatalf("unexpected receipt status: %d %d", result.Receipts[0].Status, result.Receipts[1].Status)
+	}
+	if want := result.Receipts[0].GasUsed + result.Receipts[1].GasUsed; result.GasUsed != want {
+		t.Fatalf("gas used mismatch: have %d, want %d", result.GasUsed, want)
+	}
+	if want := new(big.Int).Mul(big.NewInt(params.GWei), new(big.Int).SetUint64(result.GasUsed)); result.Profit.Cmp(want) != 0 {
+		t.Fatalf("profit mismatch: have %v, want %v", result.Profit, want)
+	}
+	// The simulation doesn't touch the bundle pool
+	if len(w.bundles.bundles) != 0 {
+		t.Fatal("simulated bundle is added to the pool")
+	}
+}
@@ -48,6 +48,8 @@ type Config struct {
 	GasCeil             uint64         // Target gas ceiling for mined blocks.
 	GasPrice            *big.Int       // Minimum gas price for mining a transaction
 	Recommit            time.Duration  // The time interval for miner to re-create mining work.
+	OrderingPolicy      string         // Ordering policy of the pending transactions in the block
+	SenderTxLimit       int            // Maximum number of transactions per sender of the fair ordering policy
 }
 
 // DefaultConfig contains default settings for miner.
@@ -60,12 +62,15 @@ var DefaultConfig = Config{
 	// for payload generation. It should be enough for Geth to
 	// run 3 rounds.
 	Recommit: 3 * time.Second / 2,
+
+	OrderingPolicy: PriceOrdering,
+	SenderTxLimit:  16,
 }
 
 // Miner is the main object which takes care of submitting new work to consensus
 // engine and gathering the sealing result.
 type Miner struct {
-	confMu      sync.RWMutex // The lock used to protect the config fields: GasCeil, GasTip and Extradata
+	confMu      sync.RWMutex // The lock used to protect the config fields: GasCeil, GasTip, Extradata and OrderingPolicy
 	config      *Config
 	chainConfig *params.ChainConfig
 	engine      consensus.Engine
@@ -73,6 +78,7 @@ type Miner struct {
 	chain       *core.BlockChain
 	pending     *pending
 	pendingMu   sync.Mutex // Lock protects the pending block
+	bundles     *bundlePool
 }
 
 // New creates a new miner with provided config.
@@ -84,6 +90,7 @@ func New(eth Backend, config Config, engine consensus.Engine) *Miner {
 		txpool:      eth.TxPool(),
 		chain:       eth.BlockChain(),
 		pending:     &pending{},
+		bundles:     newBundlePool(),
 	}
 }
 
@@ -125,6 +132,18 @@ func (miner *Miner) SetGasTip(tip *big.Int) error {
 	return nil
 }
 
+// SetOrderingPolicy sets the ordering policy of the pending transactions in the
+// built blocks.
+func (miner *Miner) SetOrderingPolicy(name string) error {
+	if _, err := lookupOrderingPolicy(name); err != nil {
+		return err
+	}
+	miner.confMu.Lock()
+	miner.config.OrderingPolicy = name
+	miner.confMu.Unlock()
+	return nil
+}
+
 // BuildPayload builds the payload according to the provided parameters.
 func (miner *Miner) BuildPayload(args *BuildPayloadArgs, witness bool) (*Payload, error) {
 	return miner.buildPayload(args, witness)
@@ -156,6 +175,7 @@ func (miner *Miner) getPending() *newPayloadResult {
 		withdrawals: withdrawal,
 		beaconRoot:  nil,
 		noTxs:       false,
+		noPrivate:   true,
 	}, false) // we will never make a witness for a pending block
 	if ret.err != nil {
 		return nil
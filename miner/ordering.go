@@ -54,43 +54,58 @@ func newTxWithMinerFee(tx *txpool.LazyTransaction, from common.Address, baseFee
 	}, nil
 }
 
-// txByPriceAndTime implements both the sort and the heap interface, making it useful
-// for all at once sorting as well as individually adding and removing elements.
-type txByPriceAndTime []*txWithMinerFee
-
-func (s txByPriceAndTime) Len() int { return len(s) }
-func (s txByPriceAndTime) Less(i, j int) bool {
-	// If the prices are equal, use the time the transaction was first seen for
-	// deterministic sorting
-	cmp := s[i].fees.Cmp(s[j].fees)
+// txByPriceAndTime orders the transactions by their effective miner tip. If
+// the prices are equal, the time the transaction was first seen is used for
+// deterministic sorting.
+func txByPriceAndTime(a, b *txWithMinerFee) bool {
+	cmp := a.fees.Cmp(b.fees)
 	if cmp == 0 {
-		return s[i].tx.Time.Before(s[j].tx.Time)
+		return a.tx.Time.Before(b.tx.Time)
 	}
 	return cmp > 0
 }
-func (s txByPriceAndTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
-func (s *txByPriceAndTime) Push(x interface{}) {
-	*s = append(*s, x.(*txWithMinerFee))
+// txByTime orders the transactions by the time they were first seen.
+func txByTime(a, b *txWithMinerFee) bool {
+	return a.tx.Time.Before(b.tx.Time)
 }
 
-func (s *txByPriceAndTime) Pop() interface{} {
-	old := *s
+// txHeap implements both the sort and the heap interface with the given
+// ordering, making it useful for all at once sorting as well as individually
+// adding and removing elements.
+type txHeap struct {
+	txs  []*txWithMinerFee
+	less func(a, b *txWithMinerFee) bool
+}
+
+func (s *txHeap) Len() int           { return len(s.txs) }
+func (s *txHeap) Less(i, j int) bool { return s.less(s.txs[i], s.txs[j]) }
+func (s *txHeap) Swap(i, j int)      { s.txs[i], s.txs[j] = s.txs[j], s.txs[i] }
+
+func (s *txHeap) Push(x interface{}) {
+	s.txs = append(s.txs, x.(*txWithMinerFee))
+}
+
+func (s *txHeap) Pop() interface{} {
+	old := s.txs
 	n := len(old)
 	x := old[n-1]
 	old[n-1] = nil
-	*s = old[0 : n-1]
+	s.txs = old[0 : n-1]
 	return x
 }
 
-// transactionsByPriceAndNonce represents a set of transactions that can return
-// transactions in a profit-maximizing sorted order, while supporting removing
-// entire batches of transactions for non-executable accounts.
-type transactionsByPriceAndNonce struct {
+// sortedTransactions represents a set of transactions that can return
+// transactions in the order of a policy, while supporting removing entire
+// batches of transactions for non-executable accounts.
+type sortedTransactions struct {
 	txs     map[common.Address][]*txpool.LazyTransaction // Per account nonce-sorted list of transactions
-	heads   txByPriceAndTime                             // Next transaction for each unique account (price heap)
+	heads   *txHeap                                      // Next transaction for each unique account
 	signer  types.Signer                                 // Signer for the set of transactions
 	baseFee *uint256.Int                                 // Current base fee
+
+	limit  int                    // Maximum number of transactions per account, 0 if unlimited
+	counts map[common.Address]int // Number of shifted transactions per account
 }
 
 // newTransactionsByPriceAndNonce creates a transaction set that can retrieve
@@ -98,69 +113,85 @@ type transactionsByPriceAndNonce struct {
 //
 // Note, the input map is reowned so the caller should not interact any more with
 // if after providing it to the constructor.
-func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *transactionsByPriceAndNonce {
+func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *sortedTransactions {
+	return newSortedTransactions(signer, txs, baseFee, txByPriceAndTime, 0)
+}
+
+// newSortedTransactions creates a transaction set that can retrieve the
+// transactions sorted by the given ordering in a nonce-honouring way, with at
+// most limit transactions per account if it's non-zero.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func newSortedTransactions(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, less func(a, b *txWithMinerFee) bool, limit int) *sortedTransactions {
 	// Convert the basefee from header format to uint256 format
 	var baseFeeUint *uint256.Int
 	if baseFee != nil {
 		baseFeeUint = uint256.MustFromBig(baseFee)
 	}
-	// Initialize a price and received time based heap with the head transactions
-	heads := make(txByPriceAndTime, 0, len(txs))
+	// Initialize a heap with the head transactions
+	heads := &txHeap{txs: make([]*txWithMinerFee, 0, len(txs)), less: less}
 	for from, accTxs := range txs {
 		wrapped, err := newTxWithMinerFee(accTxs[0], from, baseFeeUint)
 		if err != nil {
 			delete(txs, from)
 			continue
 		}
-		heads = append(heads, wrapped)
+		heads.txs = append(heads.txs, wrapped)
 		txs[from] = accTxs[1:]
 	}
-	heap.Init(&heads)
+	heap.Init(heads)
 
 	// Assemble and return the transaction set
-	return &transactionsByPriceAndNonce{
+	return &sortedTransactions{
 		txs:     txs,
 		heads:   heads,
 		signer:  signer,
 		baseFee: baseFeeUint,
+		limit:   limit,
+		counts:  make(map[common.Address]int),
 	}
 }
 
-// Peek returns the next transaction by price.
-func (t *transactionsByPriceAndNonce) Peek() (*txpool.LazyTransaction, *uint256.Int) {
-	if len(t.heads) == 0 {
+// Peek returns the next transaction and its effective miner tip.
+func (t *sortedTransactions) Peek() (*txpool.LazyTransaction, *uint256.Int) {
+	if t.heads.Len() == 0 {
 		return nil, nil
 	}
-	return t.heads[0].tx, t.heads[0].fees
+	return t.heads.txs[0].tx, t.heads.txs[0].fees
 }
 
-// Shift replaces the current best head with the next one from the same account.
-func (t *transactionsByPriceAndNonce) Shift() {
-	acc := t.heads[0].from
-	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
-		if wrapped, err := newTxWithMinerFee(txs[0], acc, t.baseFee); err == nil {
-			t.heads[0], t.txs[acc] = wrapped, txs[1:]
-			heap.Fix(&t.heads, 0)
-			return
+// Shift replaces the current best head with the next one from the same account,
+// unless the account has reached the transaction limit.
+func (t *sortedTransactions) Shift() {
+	acc := t.heads.txs[0].from
+	t.counts[acc]++
+	if t.limit == 0 || t.counts[acc] < t.limit {
+		if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
+			if wrapped, err := newTxWithMinerFee(txs[0], acc, t.baseFee); err == nil {
+				t.heads.txs[0], t.txs[acc] = wrapped, txs[1:]
+				heap.Fix(t.heads, 0)
+				return
+			}
 		}
 	}
-	heap.Pop(&t.heads)
+	heap.Pop(t.heads)
 }
 
 // Pop removes the best transaction, *not* replacing it with the next one from
 // the same account. This should be used when a transaction cannot be executed
 // and hence all subsequent ones should be discarded from the same account.
-func (t *transactionsByPriceAndNonce) Pop() {
-	heap.Pop(&t.heads)
+func (t *sortedTransactions) Pop() {
+	heap.Pop(t.heads)
 }
 
-// Empty returns if the price heap is empty. It can be used to check it simpler
+// Empty returns if the heap is empty. It can be used to check it simpler
 // than calling peek and checking for nil return.
-func (t *transactionsByPriceAndNonce) Empty() bool {
-	return len(t.heads) == 0
+func (t *sortedTransactions) Empty() bool {
+	return t.heads.Len() == 0
 }
 
 // Clear removes the entire content of the heap.
-func (t *transactionsByPriceAndNonce) Clear() {
-	t.heads, t.txs = nil, nil
+func (t *sortedTransactions) Clear() {
+	t.heads.txs, t.txs = nil, nil
 }
@@ -0,0 +1,118 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// The names of the built-in transaction ordering policies.
+const (
+	PriceOrdering = "price" // Highest effective tip first, the default
+	FIFOOrdering  = "fifo"  // First seen first
+	FairOrdering  = "fair"  // Highest effective tip first, capped per sender
+)
+
+// TxOrderer is a set of pending transactions, which yields them in the order
+// they are included in the block. The transactions of an account must be
+// yielded in nonce order.
+type TxOrderer interface {
+	// Peek returns the next transaction and its effective miner tip, or nil
+	// if the set is empty.
+	Peek() (*txpool.LazyTransaction, *uint256.Int)
+
+	// Shift replaces the next transaction with the following one from the
+	// same account, after the next one is included.
+	Shift()
+
+	// Pop removes the next transaction and all the following ones from the
+	// same account, after the next one is found not executable.
+	Pop()
+
+	// Empty returns whether the set is empty.
+	Empty() bool
+
+	// Clear removes all the transactions from the set.
+	Clear()
+}
+
+// OrderingPolicy creates the ordered set of the pending transactions, which
+// are grouped by account and nonce-sorted. The transaction map is reowned by
+// the policy.
+type OrderingPolicy func(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, config *Config) TxOrderer
+
+var (
+	orderingPolicies = map[string]OrderingPolicy{
+		PriceOrdering: func(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, config *Config) TxOrderer {
+			return newTransactionsByPriceAndNonce(signer, txs, baseFee)
+		},
+		FIFOOrdering: func(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, config *Config) TxOrderer {
+			return newSortedTransactions(signer, txs, baseFee, txByTime, 0)
+		},
+		FairOrdering: func(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, config *Config) TxOrderer {
+			return newSortedTransactions(signer, txs, baseFee, txByPriceAndTime, config.SenderTxLimit)
+		},
+	}
+	orderingPoliciesLock sync.RWMutex
+)
+
+// RegisterOrderingPolicy registers a custom transaction ordering policy, which
+// can be selected by name in the config. It should be called before the node
+// is constructed, registering an existing name replaces the policy.
+func RegisterOrderingPolicy(name string, policy OrderingPolicy) {
+	orderingPoliciesLock.Lock()
+	defer orderingPoliciesLock.Unlock()
+
+	orderingPolicies[name] = policy
+}
+
+// OrderingPolicies returns the names of the registered ordering policies.
+func OrderingPolicies() []string {
+	orderingPoliciesLock.RLock()
+	defer orderingPoliciesLock.RUnlock()
+
+	names := make([]string, 0, len(orderingPolicies))
+	for name := range orderingPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupOrderingPolicy retrieves the ordering policy by name, the empty name
+// denotes the default one.
+func lookupOrderingPolicy(name string) (OrderingPolicy, error) {
+	if name == "" {
+		name = PriceOrdering
+	}
+	orderingPoliciesLock.RLock()
+	policy, ok := orderingPolicies[name]
+	orderingPoliciesLock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown ordering policy %q, available: %v", name, OrderingPolicies())
+	}
+	return policy, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// makeOrderingTxs creates count transactions for each of the accounts, with the
+// gas price and the first seen time rising by account.
+func makeOrderingTxs(keys []*ecdsa.PrivateKey, count int) map[common.Address][]*txpool.LazyTransaction {
+	signer := types.HomesteadSigner{}
+	groups := make(map[common.Address][]*txpool.LazyTransaction)
+	for i, key := range keys {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		for nonce := 0; nonce < count; nonce++ {
+			tx, _ := types.SignTx(types.NewTransaction(uint64(nonce), common.Address{}, big.NewInt(100), 100, big.NewInt(int64(i+1)), nil), signer, key)
+			tx.SetTime(time.Unix(0, int64(i*count+nonce)))
+
+			groups[addr] = append(groups[addr], &txpool.LazyTransaction{
+				Hash:      tx.Hash(),
+				Tx:        tx,
+				Time:      tx.Time(),
+				GasFeeCap: uint256.MustFromBig(tx.GasFeeCap()),
+				GasTipCap: uint256.MustFromBig(tx.GasTipCap()),
+				Gas:       tx.Gas(),
+			})
+		}
+	}
+	return groups
+}
+
+// drainOrderer shifts all the transactions out of the set.
+func drainOrderer(orderer TxOrderer) types.Transactions {
+	var txs types.Transactions
+	for tx, _ := orderer.Peek(); tx != nil; tx, _ = orderer.Peek() {
+		txs = append(txs, tx.Tx)
+		orderer.Shift()
+	}
+	return txs
+}
+
+func TestOrderingPolicies(t *testing.T) {
+	t.Parallel()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := types.HomesteadSigner{}
+	config := &Config{SenderTxLimit: 2}
+
+	tests := []struct {
+		policy string
+		want   []uint64 // Gas prices of the yielded transactions
+	}{
+		{PriceOrdering, []uint64{3, 3, 3, 2, 2, 2, 1, 1, 1}},
+		{FIFOOrdering, []uint64{1, 1, 1, 2, 2, 2, 3, 3, 3}},
+		{FairOrdering, []uint64{3, 3, 2, 2, 1, 1}},
+	}
+	for _, test := range tests {
+		policy, err := lookupOrderingPolicy(test.policy)
+		if err != nil {
+			t.Fatalf("%s: failed to lookup policy: %v", test.policy, err)
+		}
+		txs := drainOrderer(policy(signer, makeOrderingTxs(keys, 3), nil, config))
+
+		var (
+			have   []uint64
+			nonces = make(map[common.Address]uint64)
+		)
+		for _, tx := range txs {
+			have = append(have, tx.GasPrice().Uint64())
+
+			from, _ := types.Sender(signer, tx)
+			if tx.Nonce() != nonces[from] {
+				t.Errorf("%s: invalid nonce ordering: have %d, want %d", test.policy, tx.Nonce(), nonces[from])
+			}
+			nonces[from]++
+		}
+		if !slices.Equal(have, test.want) {
+			t.Errorf("%s: ordering mismatch: have %v, want %v", test.policy, have, test.want)
+		}
+	}
+}
+
+func TestRegisterOrderingPolicy(t *testing.T) {
+	if _, err := lookupOrderingPolicy("reverse"); err == nil {
+		t.Fatal("unknown policy is resolved")
+	}
+	RegisterOrderingPolicy("reverse", func(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, config *Config) TxOrderer {
+		return newSortedTransactions(signer, txs, baseFee, func(a, b *txWithMinerFee) bool {
+			return txByTime(b, a)
+		}, 0)
+	})
+	defer func() {
+		orderingPoliciesLock.Lock()
+		delete(orderingPolicies, "reverse")
+		orderingPoliciesLock.Unlock()
+	}()
+
+	if !slices.Contains(OrderingPolicies(), "reverse") {
+		t.Fatal("registered policy is not listed")
+	}
+	w, _ := newBundleTestWorker(t, common.Address{})
+	if err := w.SetOrderingPolicy("reverse"); err != nil {
+		t.Fatalf("failed to set registered policy: %v", err)
+	}
+	if err := w.SetOrderingPolicy("unknown"); err == nil {
+		t.Fatal("unknown policy is accepted")
+	}
+	if w.config.OrderingPolicy != "reverse" {
+		t.Fatalf("ordering policy mismatch: have %s, want reverse", w.config.OrderingPolicy)
+	}
+}
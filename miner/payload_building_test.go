@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
@@ -118,6 +119,7 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 			return crypto.Sign(crypto.Keccak256(data), testBankKey)
 		})
 	case *ethash.Ethash:
+	case *beacon.Beacon:
 	default:
 		t.Fatalf("unexpected consensus engine type: %T", engine)
 	}
@@ -197,6 +199,35 @@ func TestBuildPayload(t *testing.T) {
 	}
 }
 
+func TestBuildPayloadGoatCLRoot(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		config = *params.AllGoatDebugChainConfig
+	)
+	config.Goat = &params.GoatConfig{CLRootTime: new(uint64)}
+	w, b := newTestWorker(t, &config, beacon.New(ethash.NewFaker()), db, 0)
+
+	build := func(root *common.Hash) error {
+		args := &BuildPayloadArgs{
+			Parent:     b.chain.CurrentBlock().Hash(),
+			Timestamp:  b.chain.CurrentBlock().Time + 1,
+			BeaconRoot: root,
+			Version:    engine.PayloadV3,
+		}
+		_, err := w.buildPayload(args, false)
+		return err
+	}
+	if err := build(nil); err == nil {
+		t.Fatal("expected error for missing CL root")
+	}
+	if err := build(&common.Hash{}); err == nil {
+		t.Fatal("expected error for empty CL root")
+	}
+	if err := build(&common.Hash{0x01}); err != nil {
+		t.Fatalf("failed to build payload: %v", err)
+	}
+}
+
 func TestPayloadId(t *testing.T) {
 	t.Parallel()
 	ids := make(map[string]int)
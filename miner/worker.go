@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 	"sync/atomic"
 	"time"
 
@@ -62,6 +63,27 @@ type environment struct {
 	witness *stateless.Witness
 }
 
+// copy creates a deep copy of the environment, which can be used for executing
+// transactions without affecting the original one.
+func (env *environment) copy() *environment {
+	cpy := &environment{
+		signer:   env.signer,
+		state:    env.state.Copy(),
+		tcount:   env.tcount,
+		coinbase: env.coinbase,
+		header:   types.CopyHeader(env.header),
+		txs:      slices.Clone(env.txs),
+		receipts: slices.Clone(env.receipts),
+		sidecars: slices.Clone(env.sidecars),
+		blobs:    env.blobs,
+	}
+	if env.gasPool != nil {
+		gasPool := *env.gasPool
+		cpy.gasPool = &gasPool
+	}
+	return cpy
+}
+
 const (
 	commitInterruptNone int32 = iota
 	commitInterruptNewHead
@@ -91,6 +113,7 @@ type generateParams struct {
 	withdrawals types.Withdrawals // List of withdrawals to include in block (shanghai field)
 	beaconRoot  *common.Hash      // The beacon root (cancun field).
 	noTxs       bool              // Flag whether an empty block without any transaction is expected
+	noPrivate   bool              // Flag whether the private transactions and bundles are left out (pending block)
 
 	// goat txs from cosmos
 	txs types.Transactions
@@ -109,7 +132,7 @@ func (miner *Miner) generateWork(params *generateParams, witness bool) *newPaylo
 		})
 		defer timer.Stop()
 
-		err := miner.fillTransactions(interrupt, work)
+		err := miner.fillTransactions(interrupt, work, !params.noPrivate)
 		if errors.Is(err, errBlockInterruptedByTimeout) {
 			log.Warn("Block building is interrupted", "allowance", common.PrettyDuration(miner.config.Recommit))
 		}
@@ -266,6 +289,10 @@ func (miner *Miner) prepareWork(genParams *generateParams, witness bool) (*envir
 		header.ExcessBlobGas = &excessBlobGas
 		header.ParentBeaconRoot = genParams.beaconRoot
 	}
+	if err := core.ValidateGoatCLRoot(miner.chainConfig, header); err != nil {
+		log.Error("Invalid goat CL block root", "err", err)
+		return nil, err
+	}
 	// Could potentially happen if starting to mine in an odd state.
 	// Note genParams.coinbase can be different with header.Coinbase
 	// since clique algorithm can modify the coinbase field in header.
@@ -377,7 +404,7 @@ func (miner *Miner) applyTransaction(env *environment, tx *types.Transaction) (*
 	return receipt, err
 }
 
-func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *transactionsByPriceAndNonce, interrupt *atomic.Int32) error {
+func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs TxOrderer, interrupt *atomic.Int32) error {
 	gasLimit := env.header.GasLimit
 	if env.gasPool == nil {
 		env.gasPool = new(core.GasPool).AddGas(gasLimit)
@@ -404,7 +431,7 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		// Retrieve the next transaction and abort if all done.
 		var (
 			ltx *txpool.LazyTransaction
-			txs *transactionsByPriceAndNonce
+			txs TxOrderer
 		)
 		pltx, ptip := plainTxs.Peek()
 		bltx, btip := blobTxs.Peek()
@@ -453,6 +480,15 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 			txs.Pop()
 			continue
 		}
+		// Skip the transactions whose preconditions don't hold in this block, the
+		// pool drops them once they can't be met anymore.
+		if cond := tx.Conditional(); cond != nil {
+			if err := miner.checkConditional(env, cond); err != nil {
+				log.Debug("Skipping conditional transaction", "hash", ltx.Hash, "err", err)
+				txs.Pop()
+				continue
+			}
+		}
 		// Start executing the transaction
 		env.state.SetTxContext(tx.Hash(), env.tcount)
 
@@ -477,17 +513,41 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 	return nil
 }
 
+// checkConditional verifies the preconditions of a transaction against the block
+// being built and its state after the already included transactions.
+func (miner *Miner) checkConditional(env *environment, cond *types.TransactionConditional) error {
+	if err := cond.CheckBlock(env.header.Number.Uint64(), env.header.Time); err != nil {
+		return err
+	}
+	// The storage roots are only updated by hashing the state, only pay for it
+	// if any of them is expected.
+	for _, account := range cond.KnownAccounts {
+		if account.StorageRoot != nil {
+			env.state.IntermediateRoot(miner.chainConfig.IsEIP158(env.header.Number))
+			break
+		}
+	}
+	return cond.CheckState(env.state)
+}
+
 // fillTransactions retrieves the pending transactions from the txpool and fills them
-// into the given sealing block. The transaction selection and ordering strategy can
-// be customized with the plugin in the future.
-func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment) error {
+// into the given sealing block, ordered by the configured ordering policy. The
+// private transactions and the bundles are only included if requested.
+func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment, private bool) error {
 	miner.confMu.RLock()
-	tip := miner.config.GasPrice
+	config := *miner.config
 	miner.confMu.RUnlock()
 
+	tip := config.GasPrice
+	policy, err := lookupOrderingPolicy(config.OrderingPolicy)
+	if err != nil {
+		return err
+	}
+
 	// Retrieve the pending transactions pre-filtered by the 1559/4844 dynamic fees
 	filter := txpool.PendingFilter{
-		MinTip: uint256.MustFromBig(tip),
+		MinTip:         uint256.MustFromBig(tip),
+		IncludePrivate: private,
 	}
 	if env.header.BaseFee != nil {
 		filter.BaseFee = uint256.MustFromBig(env.header.BaseFee)
@@ -515,18 +575,25 @@ func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment)
 			localBlobTxs[account] = txs
 		}
 	}
+	// Include the bundles ahead of the pending transactions, the goat txs are
+	// already at the top of the block.
+	if private {
+		if err := miner.commitBundles(env, tip, interrupt); err != nil {
+			return err
+		}
+	}
 	// Fill the block with all available pending transactions.
 	if len(localPlainTxs) > 0 || len(localBlobTxs) > 0 {
-		plainTxs := newTransactionsByPriceAndNonce(env.signer, localPlainTxs, env.header.BaseFee)
-		blobTxs := newTransactionsByPriceAndNonce(env.signer, localBlobTxs, env.header.BaseFee)
+		plainTxs := policy(env.signer, localPlainTxs, env.header.BaseFee, &config)
+		blobTxs := policy(env.signer, localBlobTxs, env.header.BaseFee, &config)
 
 		if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
 			return err
 		}
 	}
 	if len(remotePlainTxs) > 0 || len(remoteBlobTxs) > 0 {
-		plainTxs := newTransactionsByPriceAndNonce(env.signer, remotePlainTxs, env.header.BaseFee)
-		blobTxs := newTransactionsByPriceAndNonce(env.signer, remoteBlobTxs, env.header.BaseFee)
+		plainTxs := policy(env.signer, remotePlainTxs, env.header.BaseFee, &config)
+		blobTxs := policy(env.signer, remoteBlobTxs, env.header.BaseFee, &config)
 
 		if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
 			return err
@@ -0,0 +1,130 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the conditional transactions are only included in the blocks
+// satisfying their preconditions.
+func TestConditionalTransactions(t *testing.T) {
+	var (
+		root    = types.EmptyRootHash
+		minimum = uint64(2)
+		maximum = uint64(1)
+	)
+	tests := []struct {
+		name     string
+		cond     *types.TransactionConditional
+		included bool
+	}{
+		{
+			name:     "future block",
+			cond:     &types.TransactionConditional{BlockNumberMin: &minimum},
+			included: false,
+		},
+		{
+			name: "matching storage",
+			cond: &types.TransactionConditional{
+				KnownAccounts: map[common.Address]types.KnownAccount{
+					testUserAddress: {StorageRoot: &root},
+					testBankAddress: {StorageSlots: map[common.Hash]common.Hash{{}: {}}},
+				},
+			},
+			included: true,
+		},
+		{
+			name:     "past timestamp",
+			cond:     &types.TransactionConditional{TimestampMax: &maximum},
+			included: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := newTestWorkerBackend(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+			w := New(b, testConfig, ethash.NewFaker())
+
+			tx := types.MustSignNewTx(testBankKey, types.LatestSigner(params.TestChainConfig), &types.LegacyTx{
+				Nonce:    0,
+				To:       &testUserAddress,
+				Value:    big.NewInt(1000),
+				Gas:      params.TxGas,
+				GasPrice: big.NewInt(params.InitialBaseFee),
+			})
+			tx.SetConditional(test.cond)
+			if err := b.txPool.AddPrivate(tx); err != nil {
+				t.Fatalf("failed to add conditional transaction: %v", err)
+			}
+			result := w.generateWork(&generateParams{
+				parentHash: b.chain.CurrentBlock().Hash(),
+				timestamp:  uint64(time.Now().Unix()),
+				coinbase:   testUserAddress,
+			}, false)
+			if result.err != nil {
+				t.Fatalf("failed to generate work: %v", result.err)
+			}
+			if included := len(result.block.Transactions()) == 1; included != test.included {
+				t.Fatalf("inclusion mismatch: have %v, want %v", included, test.included)
+			}
+		})
+	}
+}
+
+// Tests that the private transactions are included in the built blocks, but
+// left out of the pending block served to the users.
+func TestPendingBlockPrivate(t *testing.T) {
+	b := newTestWorkerBackend(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	w := New(b, testConfig, ethash.NewFaker())
+
+	tx := types.MustSignNewTx(testBankKey, types.LatestSigner(params.TestChainConfig), &types.LegacyTx{
+		Nonce:    0,
+		To:       &testUserAddress,
+		Value:    big.NewInt(1000),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(params.InitialBaseFee),
+	})
+	if err := b.txPool.AddPrivate(tx); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	pending := w.getPending()
+	if pending == nil {
+		t.Fatal("failed to generate pending block")
+	}
+	if n := len(pending.block.Transactions()); n != 0 {
+		t.Fatalf("private transaction in pending block: %d txs", n)
+	}
+	result := w.generateWork(&generateParams{
+		parentHash: b.chain.CurrentBlock().Hash(),
+		timestamp:  uint64(time.Now().Unix()),
+		coinbase:   testUserAddress,
+	}, false)
+	if result.err != nil {
+		t.Fatalf("failed to generate work: %v", result.err)
+	}
+	if n := len(result.block.Transactions()); n != 1 {
+		t.Fatalf("private transaction not included: %d txs", n)
+	}
+}
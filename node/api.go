@@ -35,8 +35,9 @@ import (
 func (n *Node) apis() []rpc.API {
 	return []rpc.API{
 		{
-			Namespace: "admin",
-			Service:   &adminAPI{n},
+			Namespace:   "admin",
+			Service:     &adminAPI{n},
+			Description: "Node administration, peers and RPC endpoints",
 		}, {
 			Namespace: "debug",
 			Service:   debug.Handler,
@@ -44,8 +45,9 @@ func (n *Node) apis() []rpc.API {
 			Namespace: "debug",
 			Service:   &p2pDebugAPI{n},
 		}, {
-			Namespace: "web3",
-			Service:   &web3API{n},
+			Namespace:   "web3",
+			Service:     &web3API{n},
+			Description: "Client version and hashing utilities",
 		},
 	}
 }
@@ -181,6 +183,8 @@ func (api *adminAPI) StartHTTP(host *string, port *int, cors *string, apis *stri
 		rpcEndpointConfig: rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			rateLimit:              api.node.config.RPCRateLimit,
+			responseCache:          api.node.responseCache,
 		},
 	}
 	if cors != nil {
@@ -259,6 +263,8 @@ func (api *adminAPI) StartWS(host *string, port *int, allowedOrigins *string, ap
 		rpcEndpointConfig: rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			rateLimit:              api.node.config.RPCRateLimit,
+			responseCache:          api.node.responseCache,
 		},
 	}
 	if apis != nil {
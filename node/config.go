@@ -204,6 +204,13 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// RPCRateLimit is the per-client rate limit of the HTTP and WebSocket RPC calls.
+	RPCRateLimit *rpc.RateLimitConfig `toml:",omitempty"`
+
+	// RPCResponseCache is the size in megabytes of the cache of the HTTP and WebSocket
+	// responses derived from finalized blocks. Zero disables the cache.
+	RPCResponseCache int `toml:",omitempty"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
@@ -57,14 +57,15 @@ type Node struct {
 	state         int           // Tracks state of node lifecycle
 
 	lock          sync.Mutex
-	lifecycles    []Lifecycle // All registered backends, services, and auxiliary services that have a lifecycle
-	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	http          *httpServer //
-	ws            *httpServer //
-	httpAuth      *httpServer //
-	wsAuth        *httpServer //
-	ipc           *ipcServer  // Stores information about the ipc http server
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	lifecycles    []Lifecycle        // All registered backends, services, and auxiliary services that have a lifecycle
+	rpcAPIs       []rpc.API          // List of APIs currently provided by the node
+	http          *httpServer        //
+	ws            *httpServer        //
+	httpAuth      *httpServer        //
+	wsAuth        *httpServer        //
+	ipc           *ipcServer         // Stores information about the ipc http server
+	inprocHandler *rpc.Server        // In-process RPC request handler to process the API requests
+	responseCache *rpc.ResponseCache // Cache of immutable RPC responses, nil if disabled
 
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
@@ -114,6 +115,9 @@ func New(conf *Config) (*Node, error) {
 		server:        &p2p.Server{Config: conf.P2P},
 		databases:     make(map[*closeTrackingDB]struct{}),
 	}
+	if conf.RPCResponseCache > 0 {
+		node.responseCache = rpc.NewResponseCache(uint64(conf.RPCResponseCache) * 1024 * 1024)
+	}
 
 	// Register built-in APIs.
 	node.rpcAPIs = append(node.rpcAPIs, node.apis()...)
@@ -405,6 +409,8 @@ func (n *Node) startRPC() error {
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		rateLimit:              n.config.RPCRateLimit,
+		responseCache:          n.responseCache,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -623,6 +629,12 @@ func (n *Node) Attach() *rpc.Client {
 	return rpc.DialInProc(n.inprocHandler)
 }
 
+// ResponseCache returns the cache of immutable HTTP and WebSocket RPC responses,
+// or nil if response caching is disabled.
+func (n *Node) ResponseCache() *rpc.ResponseCache {
+	return n.responseCache
+}
+
 // RPCHandler returns the in-process RPC request handler.
 func (n *Node) RPCHandler() (*rpc.Server, error) {
 	n.lock.Lock()
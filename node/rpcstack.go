@@ -58,6 +58,8 @@ type rpcEndpointConfig struct {
 	batchItemLimit         int
 	batchResponseSizeLimit int
 	httpBodyLimit          int
+	rateLimit              *rpc.RateLimitConfig // optional per-client rate limits
+	responseCache          *rpc.ResponseCache   // optional cache of immutable responses
 }
 
 type rpcHandler struct {
@@ -309,6 +311,10 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
+	if err := srv.SetRateLimit(config.rateLimit); err != nil {
+		return err
+	}
+	srv.SetResponseCache(config.responseCache)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -344,6 +350,10 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
+	if err := srv.SetRateLimit(config.rateLimit); err != nil {
+		return err
+	}
+	srv.SetResponseCache(config.responseCache)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -561,6 +571,11 @@ func (w *gzipResponseWriter) Flush() {
 	}
 }
 
+// Unwrap returns the underlying response writer, for http.ResponseController.
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.resp
+}
+
 func (w *gzipResponseWriter) close() {
 	if w.gz == nil {
 		return
@@ -646,6 +661,11 @@ func RegisterApis(apis []rpc.API, modules []string, srv *rpc.Server) error {
 			if err := srv.RegisterName(api.Namespace, api.Service); err != nil {
 				return err
 			}
+			if api.Description != "" {
+				if err := srv.SetServiceDescription(api.Namespace, api.Description); err != nil {
+					return err
+				}
+			}
 		}
 	}
 	return nil
@@ -455,6 +455,15 @@ func (c *ChainConfig) Description() string {
 	if c.VerkleTime != nil {
 		banner += fmt.Sprintf(" - Verkle:                      @%-10v\n", *c.VerkleTime)
 	}
+	if c.Goat != nil && c.Goat.CLRootTime != nil {
+		banner += fmt.Sprintf(" - Goat CL root:                @%-10v\n", *c.Goat.CLRootTime)
+	}
+	if c.Goat != nil && c.Goat.P256VerifyTime != nil {
+		banner += fmt.Sprintf(" - Goat P256 verify:            @%-10v\n", *c.Goat.P256VerifyTime)
+	}
+	if c.Goat != nil && c.Goat.SetCodeTime != nil {
+		banner += fmt.Sprintf(" - Goat set code:               @%-10v\n", *c.Goat.SetCodeTime)
+	}
 	return banner
 }
 
@@ -724,6 +733,9 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.VerkleTime, newcfg.VerkleTime, headTimestamp) {
 		return newTimestampCompatError("Verkle fork timestamp", c.VerkleTime, newcfg.VerkleTime)
 	}
+	if err := c.checkGoatCompatible(newcfg, headTimestamp); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -896,6 +908,9 @@ type Rules struct {
 	IsBerlin, IsLondon                                      bool
 	IsMerge, IsShanghai, IsCancun, IsPrague                 bool
 	IsVerkle                                                bool
+
+	// goat
+	IsGoatP256Verify, IsGoatSetCode bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -926,5 +941,9 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 		IsPrague:         isMerge && c.IsPrague(num, timestamp),
 		IsVerkle:         isVerkle,
 		IsEIP4762:        isVerkle,
+
+		// goat
+		IsGoatP256Verify: isMerge && c.IsCancun(num, timestamp) && c.IsGoatP256Verify(timestamp),
+		IsGoatSetCode:    isMerge && c.IsCancun(num, timestamp) && c.IsGoatSetCode(timestamp),
 	}
 }
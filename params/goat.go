@@ -2,7 +2,51 @@ package params
 
 import "math/big"
 
-type GoatConfig struct{}
+type GoatConfig struct {
+	CLRootTime     *uint64 `json:"clRootTime,omitempty"`     // CL block root history switch time (nil = no fork, 0 = already on)
+	P256VerifyTime *uint64 `json:"p256VerifyTime,omitempty"` // RIP-7212 secp256r1 precompile switch time (nil = no fork, 0 = already on), requires cancun
+	SetCodeTime    *uint64 `json:"setCodeTime,omitempty"`    // EIP-7702 set code transaction switch time (nil = no fork, 0 = already on), requires cancun
+}
+
+// IsGoatCLRoot returns whether time is either equal to the CL block root history
+// fork time or greater.
+func (c *ChainConfig) IsGoatCLRoot(time uint64) bool {
+	return c.Goat != nil && isTimestampForked(c.Goat.CLRootTime, time)
+}
+
+// IsGoatP256Verify returns whether time is either equal to the RIP-7212 secp256r1
+// precompile fork time or greater.
+func (c *ChainConfig) IsGoatP256Verify(time uint64) bool {
+	return c.Goat != nil && isTimestampForked(c.Goat.P256VerifyTime, time)
+}
+
+// IsGoatSetCode returns whether time is either equal to the EIP-7702 set code
+// transaction fork time or greater.
+func (c *ChainConfig) IsGoatSetCode(time uint64) bool {
+	return c.Goat != nil && isTimestampForked(c.Goat.SetCodeTime, time)
+}
+
+// IsSetCode returns whether EIP-7702 set code transactions are enabled, either
+// by Prague or by the goat set code fork after Cancun.
+func (c *ChainConfig) IsSetCode(num *big.Int, time uint64) bool {
+	return c.IsPrague(num, time) || (c.IsCancun(num, time) && c.IsGoatSetCode(time))
+}
+
+func (c *ChainConfig) checkGoatCompatible(newcfg *ChainConfig, headTimestamp uint64) *ConfigCompatError {
+	if c.Goat == nil || newcfg.Goat == nil {
+		return nil
+	}
+	if isForkTimestampIncompatible(c.Goat.CLRootTime, newcfg.Goat.CLRootTime, headTimestamp) {
+		return newTimestampCompatError("Goat CL root fork timestamp", c.Goat.CLRootTime, newcfg.Goat.CLRootTime)
+	}
+	if isForkTimestampIncompatible(c.Goat.P256VerifyTime, newcfg.Goat.P256VerifyTime, headTimestamp) {
+		return newTimestampCompatError("Goat P256 verify fork timestamp", c.Goat.P256VerifyTime, newcfg.Goat.P256VerifyTime)
+	}
+	if isForkTimestampIncompatible(c.Goat.SetCodeTime, newcfg.Goat.SetCodeTime, headTimestamp) {
+		return newTimestampCompatError("Goat set code fork timestamp", c.Goat.SetCodeTime, newcfg.Goat.SetCodeTime)
+	}
+	return nil
+}
 
 const (
 	GoatHeaderExtraLengthV0 = 33
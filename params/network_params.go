@@ -20,13 +20,12 @@ package params
 // aren't necessarily consensus related.
 
 const (
-	// BloomBitsBlocks is the number of blocks a single bloom bit section vector
-	// contains on the server side.
-	BloomBitsBlocks uint64 = 4096
+	// LogIndexBlocks is the number of blocks a single log index section contains.
+	LogIndexBlocks uint64 = 4096
 
-	// BloomConfirms is the number of confirmation blocks before a bloom section is
-	// considered probably final and its rotated bits are calculated.
-	BloomConfirms = 256
+	// LogIndexConfirms is the number of confirmation blocks before a log index
+	// section is considered probably final and its index is generated.
+	LogIndexConfirms = 256
 
 	// FullImmutabilityThreshold is the number of blocks after which a chain segment is
 	// considered immutable (i.e. soft finality). It is used by the downloader as a
@@ -0,0 +1,146 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+)
+
+// ResponseCache is a size bounded cache of the serialized results of immutable
+// queries, shared by the servers of a node. Methods opt in by reporting through
+// CacheResponse that their result is derived from a finalized block.
+type ResponseCache struct {
+	size    uint64
+	entries *lru.SizeConstrainedCache[string, json.RawMessage]
+	methods sync.Map // Methods which produced cacheable results, string -> struct{}
+
+	// Highest block a cached result is derived from. As long as it's canonical,
+	// all the blocks below it are too.
+	highest     uint64
+	highestHash common.Hash
+	lock        sync.Mutex
+}
+
+// NewResponseCache creates a response cache holding up to size bytes of results.
+func NewResponseCache(size uint64) *ResponseCache {
+	return &ResponseCache{
+		size:    size,
+		entries: lru.NewSizeConstrainedCache[string, json.RawMessage](size),
+	}
+}
+
+// cacheMarkerKey is the context key of the cacheMarker of a call.
+type cacheMarkerKey struct{}
+
+// cacheMarker records whether the result of a call may be cached, and the block
+// it's derived from.
+type cacheMarker struct {
+	cacheable bool
+	number    uint64
+	hash      common.Hash
+}
+
+// CachingResponse reports whether the response of the call may be cached, for
+// methods to skip the checks needed to call CacheResponse.
+func CachingResponse(ctx context.Context) bool {
+	_, ok := ctx.Value(cacheMarkerKey{}).(*cacheMarker)
+	return ok
+}
+
+// CacheResponse marks the result of the call as cacheable. It must only be used
+// for results which are fully determined by the parameters of the call and the
+// given finalized block, i.e. not for queries of blocks by tags like "finalized".
+// The cached results are dropped if the block is reorged.
+func CacheResponse(ctx context.Context, number uint64, hash common.Hash) {
+	if marker, ok := ctx.Value(cacheMarkerKey{}).(*cacheMarker); ok {
+		marker.cacheable, marker.number, marker.hash = true, number, hash
+	}
+}
+
+// key derives the cache key of a call from its method and canonical parameters.
+func (c *ResponseCache) key(method string, args []reflect.Value) (string, bool) {
+	params := make([]interface{}, len(args))
+	for i, arg := range args {
+		params[i] = arg.Interface()
+	}
+	enc, err := json.Marshal(params)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(enc)
+	return method + string(sum[:]), true
+}
+
+// get returns the cached result of a call, if the method has ever produced a
+// cacheable result.
+func (c *ResponseCache) get(method string, args []reflect.Value) (json.RawMessage, bool) {
+	if _, ok := c.methods.Load(method); !ok {
+		return nil, false
+	}
+	key, ok := c.key(method, args)
+	if !ok {
+		return nil, false
+	}
+	c.lock.Lock()
+	entries := c.entries
+	c.lock.Unlock()
+
+	if result, ok := entries.Get(key); ok {
+		responseCacheHitMeter.Mark(1)
+		return result, true
+	}
+	responseCacheMissMeter.Mark(1)
+	return nil, false
+}
+
+// add caches the result of a call derived from the given block.
+func (c *ResponseCache) add(method string, args []reflect.Value, result json.RawMessage, marker *cacheMarker) {
+	key, ok := c.key(method, args)
+	if !ok {
+		return
+	}
+	c.methods.Store(method, struct{}{})
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if marker.number >= c.highest {
+		c.highest, c.highestHash = marker.number, marker.hash
+	}
+	c.entries.Add(key, result)
+}
+
+// Verify drops the cached results if the highest block they're derived from is
+// not canonical anymore, i.e. after a reorg below the finalized block.
+func (c *ResponseCache) Verify(canonical func(number uint64) common.Hash) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.highestHash == (common.Hash{}) || canonical(c.highest) == c.highestHash {
+		return
+	}
+	c.entries = lru.NewSizeConstrainedCache[string, json.RawMessage](c.size)
+	c.highest, c.highestHash = 0, common.Hash{}
+	responseCachePurgeMeter.Mark(1)
+}
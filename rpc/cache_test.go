@@ -0,0 +1,125 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// cacheTestService serves the number of calls made, caching the responses of
+// the blocks up to the finalized one.
+type cacheTestService struct {
+	finalized uint64
+	calls     atomic.Uint64
+	caching   atomic.Bool
+}
+
+func (s *cacheTestService) Block(ctx context.Context, number uint64) uint64 {
+	s.caching.Store(CachingResponse(ctx))
+	if number <= s.finalized {
+		CacheResponse(ctx, number, common.Hash{byte(number)})
+	}
+	return s.calls.Add(1)
+}
+
+func TestResponseCache(t *testing.T) {
+	var (
+		server  = NewServer()
+		service = &cacheTestService{finalized: 2}
+		cache   = NewResponseCache(1024 * 1024)
+	)
+	defer server.Stop()
+	if err := server.RegisterName("test", service); err != nil {
+		t.Fatal(err)
+	}
+	server.SetResponseCache(cache)
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	call := func(number uint64) uint64 {
+		t.Helper()
+		var result uint64
+		if err := client.Call(&result, "test_block", number); err != nil {
+			t.Fatalf("call failed: %v", err)
+		}
+		return result
+	}
+	// Finalized responses are cached, the others aren't
+	if have := call(1); have != 1 {
+		t.Fatalf("first call mismatch: have %d, want 1", have)
+	}
+	if !service.caching.Load() {
+		t.Error("caching not reported to the method")
+	}
+	if have := call(1); have != 1 {
+		t.Errorf("finalized response not cached: have %d, want 1", have)
+	}
+	if have := call(3); have != 2 {
+		t.Errorf("non-finalized response mismatch: have %d, want 2", have)
+	}
+	if have := call(3); have != 3 {
+		t.Errorf("non-finalized response cached: have %d, want 3", have)
+	}
+	if have := call(2); have != 4 {
+		t.Errorf("response mismatch: have %d, want 4", have)
+	}
+	// The cache survives as long as the highest cached block is canonical
+	cache.Verify(func(number uint64) common.Hash { return common.Hash{byte(number)} })
+	if have := call(1); have != 1 {
+		t.Errorf("response dropped without reorg: have %d, want 1", have)
+	}
+	cache.Verify(func(number uint64) common.Hash {
+		if number == 2 {
+			return common.Hash{0xff}
+		}
+		return common.Hash{byte(number)}
+	})
+	if have := call(1); have != 5 {
+		t.Errorf("response not dropped after reorg: have %d, want 5", have)
+	}
+}
+
+func TestResponseCacheDisabled(t *testing.T) {
+	var (
+		server  = NewServer()
+		service = &cacheTestService{finalized: 2}
+	)
+	defer server.Stop()
+	if err := server.RegisterName("test", service); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var result uint64
+	for i := uint64(1); i <= 2; i++ {
+		if err := client.Call(&result, "test_block", 1); err != nil {
+			t.Fatalf("call failed: %v", err)
+		}
+		if result != i {
+			t.Errorf("call %d: have %d, want %d", i, result, i)
+		}
+	}
+	if service.caching.Load() {
+		t.Error("caching reported without a cache")
+	}
+}
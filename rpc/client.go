@@ -89,6 +89,8 @@ type Client struct {
 	// config fields
 	batchItemLimit       int
 	batchResponseMaxSize int
+	rateLimit            *rateLimiter
+	responseCache        *ResponseCache
 
 	// writeConn is used for writing to the connection on the caller's goroutine. It should
 	// only be accessed outside of dispatch, with the write lock held. The write lock is
@@ -120,7 +122,12 @@ func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
+	if sse, ok := conn.(*sseServerConn); ok && sse.lastEventID != "" {
+		ctx = context.WithValue(ctx, lastEventIDKey{}, sse.lastEventID)
+	}
 	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseMaxSize)
+	handler.rateLimit = c.rateLimit
+	handler.responseCache = c.responseCache
 	return &clientConn{conn, handler}
 }
 
@@ -248,6 +255,8 @@ func initClient(conn ServerCodec, services *serviceRegistry, cfg *clientConfig)
 		idgen:                cfg.idgen,
 		batchItemLimit:       cfg.batchItemLimit,
 		batchResponseMaxSize: cfg.batchResponseLimit,
+		rateLimit:            cfg.rateLimit,
+		responseCache:        cfg.responseCache,
 		writeConn:            conn,
 		close:                make(chan struct{}),
 		closing:              make(chan struct{}),
@@ -298,6 +307,7 @@ func (c *Client) SupportedModules() (map[string]string, error) {
 // Close closes the client, aborting any in-flight requests.
 func (c *Client) Close() {
 	if c.isHTTP {
+		c.writeConn.(*httpConn).close() // ends the subscriptions
 		return
 	}
 	select {
@@ -514,14 +524,13 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 	if chanVal.IsNil() {
 		panic("channel given to Subscribe must not be nil")
 	}
-	if c.isHTTP {
-		return nil, ErrNotificationsUnsupported
-	}
-
 	msg, err := c.newMessage(namespace+subscribeMethodSuffix, args...)
 	if err != nil {
 		return nil, err
 	}
+	if c.isHTTP {
+		return c.subscribeSSE(ctx, namespace, chanVal, msg)
+	}
 	op := &requestOp{
 		ids:  []json.RawMessage{msg.ID},
 		resp: make(chan []*jsonrpcMessage, 1),
@@ -542,8 +551,14 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 // SupportsSubscriptions reports whether subscriptions are supported by the client
 // transport. When this returns false, Subscribe and related methods will return
 // ErrNotificationsUnsupported.
+//
+// Over HTTP, subscriptions are streamed as server-sent events. The server is
+// asked whether it supports them, which requires a round trip on the first call.
 func (c *Client) SupportsSubscriptions() bool {
-	return !c.isHTTP
+	if c.isHTTP {
+		return c.writeConn.(*httpConn).supportsSSE()
+	}
+	return true
 }
 
 func (c *Client) newMessage(method string, paramsIn ...interface{}) (*jsonrpcMessage, error) {
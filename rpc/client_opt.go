@@ -41,6 +41,8 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	rateLimit          *rateLimiter
+	responseCache      *ResponseCache
 }
 
 func (cfg *clientConfig) initHeaders() {
@@ -65,6 +65,8 @@ type handler struct {
 	allowSubscribe       bool
 	batchRequestLimit    int
 	batchResponseMaxSize int
+	rateLimit            *rateLimiter   // nil if calls are not rate limited
+	responseCache        *ResponseCache // nil if responses are not cached
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -506,13 +508,18 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if callb == nil {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
+	if callb != h.unsubscribeCb {
+		if err := h.checkRateLimit(cp, msg); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
 
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	answer := h.runCachedMethod(cp.ctx, msg, callb, args)
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -546,6 +553,9 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	if callb == nil {
 		return msg.errorResponse(&subscriptionNotFoundError{namespace, name})
 	}
+	if err := h.checkRateLimit(cp, msg); err != nil {
+		return msg.errorResponse(err)
+	}
 
 	// Parse subscription name arg too, but remove it before calling the callback.
 	argTypes := append([]reflect.Type{stringType}, callb.argTypes...)
@@ -563,6 +573,31 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	return h.runMethod(ctx, msg, callb, args)
 }
 
+// checkRateLimit charges the call to the rate limits of the client.
+func (h *handler) checkRateLimit(cp *callProc, msg *jsonrpcMessage) error {
+	if h.rateLimit == nil {
+		return nil
+	}
+	return h.rateLimit.allow(PeerInfoFromContext(cp.ctx), msg.Method)
+}
+
+// runCachedMethod runs the Go callback for an RPC method, serving the response
+// from the response cache if possible.
+func (h *handler) runCachedMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
+	if h.responseCache == nil || callb == h.unsubscribeCb {
+		return h.runMethod(ctx, msg, callb, args)
+	}
+	if result, ok := h.responseCache.get(msg.Method, args); ok {
+		return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: result}
+	}
+	marker := new(cacheMarker)
+	answer := h.runMethod(context.WithValue(ctx, cacheMarkerKey{}, marker), msg, callb, args)
+	if marker.cacheable && answer.Error == nil {
+		h.responseCache.add(msg.Method, args, answer.Result, marker)
+	}
+	return answer
+}
+
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
 	result, err := callb.call(ctx, msg.Method, args)
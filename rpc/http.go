@@ -48,6 +48,10 @@ type httpConn struct {
 	mu        sync.Mutex // protects headers
 	headers   http.Header
 	auth      HTTPAuth
+
+	sseMu        sync.Mutex // protects the server-sent event support fields
+	sseProbed    bool
+	sseSupported bool
 }
 
 // httpConn implements ServerCodec, but it is treated specially by Client
@@ -202,13 +206,20 @@ func (c *Client) sendBatchHTTP(ctx context.Context, op *requestOp, msgs []*jsonr
 }
 
 func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadCloser, error) {
+	body, _, err := hc.doRequestWithHeader(ctx, msg, nil)
+	return body, err
+}
+
+// doRequestWithHeader sends the message with the given additional headers, returning
+// the body and the headers of the response.
+func (hc *httpConn) doRequestWithHeader(ctx context.Context, msg interface{}, header http.Header) (io.ReadCloser, http.Header, error) {
 	body, err := json.Marshal(msg)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hc.url, io.NopCloser(bytes.NewReader(body)))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	req.ContentLength = int64(len(body))
 	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
@@ -218,17 +229,18 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 	req.Header = hc.headers.Clone()
 	hc.mu.Unlock()
 	setHeaders(req.Header, headersFromContext(ctx))
+	setHeaders(req.Header, header)
 
 	if hc.auth != nil {
 		if err := hc.auth(req.Header); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	// do request
 	resp, err := hc.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		var buf bytes.Buffer
@@ -237,13 +249,13 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 			body = buf.Bytes()
 		}
 		resp.Body.Close()
-		return nil, HTTPError{
+		return nil, nil, HTTPError{
 			Status:     resp.Status,
 			StatusCode: resp.StatusCode,
 			Body:       body,
 		}
 	}
-	return resp.Body, nil
+	return resp.Body, resp.Header, nil
 }
 
 // httpServerConn turns a HTTP connection into a Conn.
@@ -309,20 +321,32 @@ func (t *httpServerConn) SetWriteDeadline(time.Time) error { return nil }
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Permit dumb empty requests for remote health-checks (AWS)
 	if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" {
+		// Advertise the support of server-sent events to the probing clients
+		if isSSERequest(r) {
+			w.Header().Set("content-type", sseContentType)
+		}
 		w.WriteHeader(http.StatusOK)
 		return
 	}
+	// Collect the connection info of the client.
+	connInfo := PeerInfo{Transport: "http", RemoteAddr: r.RemoteAddr}
+	connInfo.HTTP.Version = r.Proto
+	connInfo.HTTP.Host = r.Host
+	connInfo.HTTP.Origin = r.Header.Get("Origin")
+	connInfo.HTTP.UserAgent = r.Header.Get("User-Agent")
+	connInfo.HTTP.APIKey = r.Header.Get(APIKeyHeader)
+
+	// Serve subscriptions as server-sent event streams.
+	if isSSERequest(r) {
+		s.serveSSE(w, r, connInfo)
+		return
+	}
 	if code, err := s.validateRequest(r); err != nil {
 		http.Error(w, err.Error(), code)
 		return
 	}
 
 	// Create request-scoped context.
-	connInfo := PeerInfo{Transport: "http", RemoteAddr: r.RemoteAddr}
-	connInfo.HTTP.Version = r.Proto
-	connInfo.HTTP.Host = r.Host
-	connInfo.HTTP.Origin = r.Header.Get("Origin")
-	connInfo.HTTP.UserAgent = r.Header.Get("User-Agent")
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
 
@@ -338,12 +362,8 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // validateRequest returns a non-zero response code and error message if the
 // request is invalid.
 func (s *Server) validateRequest(r *http.Request) (int, error) {
-	if r.Method == http.MethodPut || r.Method == http.MethodDelete {
-		return http.StatusMethodNotAllowed, errors.New("method not allowed")
-	}
-	if r.ContentLength > int64(s.httpBodyLimit) {
-		err := fmt.Errorf("content length too large (%d>%d)", r.ContentLength, s.httpBodyLimit)
-		return http.StatusRequestEntityTooLarge, err
+	if code, err := s.validateRequestLimits(r); err != nil {
+		return code, err
 	}
 	// Allow OPTIONS (regardless of content-type)
 	if r.Method == http.MethodOptions {
@@ -362,6 +382,19 @@ func (s *Server) validateRequest(r *http.Request) (int, error) {
 	return http.StatusUnsupportedMediaType, err
 }
 
+// validateRequestLimits returns a non-zero response code and error message if the
+// request method is not allowed or the request is too large.
+func (s *Server) validateRequestLimits(r *http.Request) (int, error) {
+	if r.Method == http.MethodPut || r.Method == http.MethodDelete {
+		return http.StatusMethodNotAllowed, errors.New("method not allowed")
+	}
+	if r.ContentLength > int64(s.httpBodyLimit) {
+		err := fmt.Errorf("content length too large (%d>%d)", r.ContentLength, s.httpBodyLimit)
+		return http.StatusRequestEntityTooLarge, err
+	}
+	return 0, nil
+}
+
 // ContextRequestTimeout returns the request timeout derived from the given context.
 func ContextRequestTimeout(ctx context.Context) (time.Duration, bool) {
 	timeout := time.Duration(math.MaxInt64)
@@ -32,6 +32,15 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	// rateLimitedMeterName is the prefix of the per-method rate limited call meters.
+	rateLimitedMeterName = "rpc/ratelimited"
+
+	rateLimitedMeter = metrics.NewRegisteredMeter("rpc/ratelimited/all", nil)
+
+	responseCacheHitMeter   = metrics.NewRegisteredMeter("rpc/cache/hit", nil)
+	responseCacheMissMeter  = metrics.NewRegisteredMeter("rpc/cache/miss", nil)
+	responseCachePurgeMeter = metrics.NewRegisteredMeter("rpc/cache/purge", nil)
 )
 
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
@@ -48,3 +57,8 @@ func updateServeTimeHistogram(method string, success bool, elapsed time.Duration
 	}
 	metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(elapsed.Nanoseconds())
 }
+
+// updateRateLimitedMeter tracks the calls of a method rejected by rate limiting.
+func updateRateLimitedMeter(method string) {
+	metrics.GetOrRegisterMeter(fmt.Sprintf("%s/%s", rateLimitedMeterName, method), nil).Mark(1)
+}
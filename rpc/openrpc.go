@@ -0,0 +1,394 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// DiscoverMethod is the OpenRPC service discovery method.
+	DiscoverMethod = "rpc.discover"
+
+	// discoverCallback is the registered name of the discovery method, which
+	// doesn't follow the naming scheme of the namespaces.
+	discoverCallback = MetadataApi + serviceMethodSeparator + "discover"
+
+	// openRPCVersion is the version of the OpenRPC specification the service
+	// discovery documents conform to.
+	openRPCVersion = "1.2.6"
+)
+
+// OpenRPCDocument is an OpenRPC service description.
+type OpenRPCDocument struct {
+	OpenRPC    string            `json:"openrpc"`
+	Info       OpenRPCInfo       `json:"info"`
+	Methods    []*OpenRPCMethod  `json:"methods"`
+	Components OpenRPCComponents `json:"components"`
+}
+
+// OpenRPCInfo is the metadata of the described API.
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCComponents holds the schemas of the named types, referred to from the
+// method descriptions.
+type OpenRPCComponents struct {
+	Schemas map[string]*OpenRPCSchema `json:"schemas"`
+}
+
+// OpenRPCTag groups the methods of a namespace.
+type OpenRPCTag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenRPCMethod describes a method of the API.
+type OpenRPCMethod struct {
+	Name           string                      `json:"name"`
+	Description    string                      `json:"description,omitempty"`
+	Tags           []OpenRPCTag                `json:"tags,omitempty"`
+	ParamStructure string                      `json:"paramStructure"`
+	Params         []*OpenRPCContentDescriptor `json:"params"`
+	Result         *OpenRPCContentDescriptor   `json:"result"`
+}
+
+// OpenRPCContentDescriptor describes a parameter or the result of a method.
+type OpenRPCContentDescriptor struct {
+	Name     string         `json:"name"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenRPCSchema `json:"schema"`
+}
+
+// OpenRPCSchema is the subset of JSON Schema describing the values of the API.
+type OpenRPCSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Title                string                    `json:"title,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Items                *OpenRPCSchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenRPCSchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *OpenRPCSchema            `json:"additionalProperties,omitempty"`
+	OneOf                []*OpenRPCSchema          `json:"oneOf,omitempty"`
+}
+
+const (
+	quantityPattern = "^0x(0|[1-9a-fA-F][0-9a-fA-F]*)$"
+	bytesPattern    = "^0x([0-9a-fA-F]{2})*$"
+)
+
+var (
+	quantitySchema = &OpenRPCSchema{Title: "quantity", Type: "string", Pattern: quantityPattern}
+	hashSchema     = &OpenRPCSchema{Title: "hash", Type: "string", Pattern: "^0x[0-9a-fA-F]{64}$"}
+	blockTagSchema = &OpenRPCSchema{
+		Title: "blockTag",
+		Type:  "string",
+		Enum:  []string{"earliest", "finalized", "safe", "latest", "pending"},
+	}
+	blockNumberSchema = &OpenRPCSchema{Title: "blockNumber", OneOf: []*OpenRPCSchema{quantitySchema, blockTagSchema}}
+
+	// wellKnownSchemas are the schemas of the types with custom encodings,
+	// which can't be derived by reflection.
+	wellKnownSchemas = map[reflect.Type]*OpenRPCSchema{
+		reflect.TypeOf(common.Hash{}):     hashSchema,
+		reflect.TypeOf(common.Address{}):  {Title: "address", Type: "string", Pattern: "^0x[0-9a-fA-F]{40}$"},
+		reflect.TypeOf(hexutil.Bytes{}):   {Title: "bytes", Type: "string", Pattern: bytesPattern},
+		reflect.TypeOf(hexutil.Big{}):     quantitySchema,
+		reflect.TypeOf(hexutil.U256{}):    quantitySchema,
+		reflect.TypeOf(hexutil.Uint64(0)): quantitySchema,
+		reflect.TypeOf(hexutil.Uint(0)):   quantitySchema,
+		reflect.TypeOf(big.Int{}):         {Type: "integer"},
+		reflect.TypeOf(time.Time{}):       {Type: "string", Format: "date-time"},
+		reflect.TypeOf(json.RawMessage{}): {},
+		reflect.TypeOf(BlockNumber(0)):    blockNumberSchema,
+		reflect.TypeOf(BlockNumberOrHash{}): {
+			Title: "blockNumberOrHash",
+			OneOf: []*OpenRPCSchema{blockNumberSchema, hashSchema, {
+				Type: "object",
+				Properties: map[string]*OpenRPCSchema{
+					"blockNumber":      blockNumberSchema,
+					"blockHash":        hashSchema,
+					"requireCanonical": {Type: "boolean"},
+				},
+			}},
+		},
+		reflect.TypeOf(ID("")): {Title: "subscriptionId", Type: "string"},
+	}
+
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaBuilder derives the JSON schemas of Go types, collecting the schemas of
+// the named struct types as components.
+type schemaBuilder struct {
+	components map[string]*OpenRPCSchema
+	names      map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: make(map[string]*OpenRPCSchema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// typeName returns the qualified name of a named type, usable as a component key.
+func typeName(typ reflect.Type) string {
+	name := path.Base(typ.PkgPath()) + "." + typ.Name()
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// schema returns the JSON schema of the values of the given type.
+func (b *schemaBuilder) schema(typ reflect.Type) *OpenRPCSchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if s, ok := wellKnownSchemas[typ]; ok {
+		return s
+	}
+	// Custom encodings are opaque, unless they are known to be strings
+	ptr := reflect.PointerTo(typ)
+	if typ.Implements(jsonMarshalerType) || ptr.Implements(jsonMarshalerType) {
+		if typ.Name() != "" {
+			return &OpenRPCSchema{Title: typeName(typ)}
+		}
+		return &OpenRPCSchema{}
+	}
+	if typ.Implements(textMarshalerType) || ptr.Implements(textMarshalerType) {
+		return &OpenRPCSchema{Title: typeName(typ), Type: "string"}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &OpenRPCSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &OpenRPCSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenRPCSchema{Type: "number"}
+	case reflect.String:
+		return &OpenRPCSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 && typ.Kind() == reflect.Slice {
+			return &OpenRPCSchema{Type: "string", Format: "byte"}
+		}
+		return &OpenRPCSchema{Type: "array", Items: b.schema(typ.Elem())}
+	case reflect.Map:
+		return &OpenRPCSchema{Type: "object", AdditionalProperties: b.schema(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return b.structSchema(typ)
+		}
+		name, ok := b.names[typ]
+		if !ok {
+			// Reserve the component before deriving the fields, the type may
+			// be recursive.
+			name = typeName(typ)
+			for i := 2; b.components[name] != nil; i++ {
+				name = fmt.Sprintf("%s%d", typeName(typ), i)
+			}
+			b.names[typ] = name
+			b.components[name] = new(OpenRPCSchema)
+			*b.components[name] = *b.structSchema(typ)
+		}
+		return &OpenRPCSchema{Ref: "#/components/schemas/" + name}
+	default:
+		// Interfaces, channels and functions can't be described
+		return &OpenRPCSchema{}
+	}
+}
+
+// structSchema returns the JSON schema of a struct type, following the field
+// naming rules of encoding/json.
+func (b *schemaBuilder) structSchema(typ reflect.Type) *OpenRPCSchema {
+	s := &OpenRPCSchema{Type: "object", Properties: make(map[string]*OpenRPCSchema)}
+	if typ.Name() != "" {
+		s.Title = typeName(typ)
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			// Embedded structs are flattened into the parent
+			ftyp := field.Type
+			for ftyp.Kind() == reflect.Ptr {
+				ftyp = ftyp.Elem()
+			}
+			if ftyp.Kind() == reflect.Struct && !ftyp.Implements(jsonMarshalerType) {
+				embedded := b.structSchema(ftyp)
+				for name, prop := range embedded.Properties {
+					if _, ok := s.Properties[name]; !ok {
+						s.Properties[name] = prop
+					}
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		prop := b.schema(field.Type)
+		if slices.Contains(strings.Split(opts, ","), "string") {
+			prop = &OpenRPCSchema{Type: "string"}
+		}
+		s.Properties[name] = prop
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, name)
+		}
+	}
+	slices.Sort(s.Required)
+	s.Required = slices.Compact(s.Required)
+	return s
+}
+
+// paramNames names the positional parameters of a method after their types.
+func paramNames(types []reflect.Type) []string {
+	names := make([]string, len(types))
+	seen := make(map[string]bool)
+	for i, typ := range types {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		name := fmt.Sprintf("arg%d", i)
+		if typ.Name() != "" && typ.PkgPath() != "" {
+			name = formatName(typ.Name())
+		}
+		if seen[name] {
+			name = fmt.Sprintf("%s%d", name, i)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// describe returns the OpenRPC description of a callback.
+func (b *schemaBuilder) describe(name string, tag OpenRPCTag, cb *callback) *OpenRPCMethod {
+	m := &OpenRPCMethod{
+		Name:           name,
+		ParamStructure: "by-position",
+		Params:         make([]*OpenRPCContentDescriptor, len(cb.argTypes)),
+		Result:         &OpenRPCContentDescriptor{Name: "result", Schema: &OpenRPCSchema{Type: "null"}},
+	}
+	if tag.Name != "" {
+		m.Tags = []OpenRPCTag{tag}
+	}
+	// Trailing pointer parameters may be omitted, all others are required.
+	required := false
+	names := paramNames(cb.argTypes)
+	for i := len(cb.argTypes) - 1; i >= 0; i-- {
+		required = required || cb.argTypes[i].Kind() != reflect.Ptr
+		m.Params[i] = &OpenRPCContentDescriptor{
+			Name:     names[i],
+			Required: required,
+			Schema:   b.schema(cb.argTypes[i]),
+		}
+	}
+	if fntype := cb.fn.Type(); fntype.NumOut() > 0 && cb.errPos != 0 {
+		m.Result.Schema = b.schema(fntype.Out(0))
+	}
+	return m
+}
+
+// discover generates the OpenRPC description of the registered services.
+func (r *serviceRegistry) discover() *OpenRPCDocument {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		b   = newSchemaBuilder()
+		doc = &OpenRPCDocument{
+			OpenRPC: openRPCVersion,
+			Info:    OpenRPCInfo{Title: "Ethereum JSON-RPC API", Version: "1.0.0"},
+		}
+	)
+	for _, svc := range r.services {
+		tag := OpenRPCTag{Name: svc.name, Description: svc.description}
+		for name, cb := range svc.callbacks {
+			method := svc.name + serviceMethodSeparator + name
+			if method == discoverCallback {
+				method = DiscoverMethod
+			}
+			doc.Methods = append(doc.Methods, b.describe(method, tag, cb))
+		}
+		if len(svc.subscriptions) == 0 {
+			continue
+		}
+		// Subscriptions are all created through the subscribe method of the
+		// namespace, named by the first parameter.
+		names := make([]string, 0, len(svc.subscriptions))
+		for name := range svc.subscriptions {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		doc.Methods = append(doc.Methods, &OpenRPCMethod{
+			Name:           svc.name + subscribeMethodSuffix,
+			Description:    "Creates a subscription, the further parameters depend on the subscription.",
+			Tags:           []OpenRPCTag{tag},
+			ParamStructure: "by-position",
+			Params: []*OpenRPCContentDescriptor{
+				{Name: "subscription", Required: true, Schema: &OpenRPCSchema{Type: "string", Enum: names}},
+			},
+			Result: &OpenRPCContentDescriptor{Name: "subscriptionId", Schema: b.schema(reflect.TypeOf(ID("")))},
+		}, &OpenRPCMethod{
+			Name:           svc.name + unsubscribeMethodSuffix,
+			Description:    "Cancels a subscription.",
+			Tags:           []OpenRPCTag{tag},
+			ParamStructure: "by-position",
+			Params: []*OpenRPCContentDescriptor{
+				{Name: "subscriptionId", Required: true, Schema: b.schema(reflect.TypeOf(ID("")))},
+			},
+			Result: &OpenRPCContentDescriptor{Name: "result", Schema: &OpenRPCSchema{Type: "boolean"}},
+		})
+	}
+	slices.SortFunc(doc.Methods, func(a, b *OpenRPCMethod) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	doc.Components.Schemas = b.components
+	return doc
+}
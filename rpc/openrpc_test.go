@@ -0,0 +1,117 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	if err := server.SetServiceDescription("test", "Test methods"); err != nil {
+		t.Fatalf("failed to set description: %v", err)
+	}
+	if err := server.SetServiceDescription("missing", "Missing methods"); err == nil {
+		t.Fatal("description of unregistered namespace accepted")
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var doc OpenRPCDocument
+	if err := client.Call(&doc, DiscoverMethod); err != nil {
+		t.Fatalf("failed to discover: %v", err)
+	}
+	methods := make(map[string]*OpenRPCMethod)
+	for _, method := range doc.Methods {
+		methods[method.Name] = method
+	}
+	for _, name := range []string{DiscoverMethod, "rpc_modules", "test_echo", "nftest_subscribe", "nftest_unsubscribe"} {
+		if methods[name] == nil {
+			t.Errorf("method %s missing", name)
+		}
+	}
+	if methods["rpc_discover"] != nil {
+		t.Error("discovery method listed under its registered name")
+	}
+	// Check the parameters and the result of a method
+	echo := methods["test_echo"]
+	if len(echo.Tags) != 1 || echo.Tags[0] != (OpenRPCTag{Name: "test", Description: "Test methods"}) {
+		t.Errorf("tags mismatch: %v", echo.Tags)
+	}
+	var (
+		names    []string
+		required []bool
+	)
+	for _, param := range echo.Params {
+		names = append(names, param.Name)
+		required = append(required, param.Required)
+	}
+	if want := []string{"arg0", "arg1", "echoArgs"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("parameter names mismatch: have %v, want %v", names, want)
+	}
+	if want := []bool{true, true, false}; !reflect.DeepEqual(required, want) {
+		t.Errorf("parameter requirements mismatch: have %v, want %v", required, want)
+	}
+	if echo.Params[0].Schema.Type != "string" || echo.Params[1].Schema.Type != "integer" {
+		t.Errorf("parameter types mismatch: %s, %s", echo.Params[0].Schema.Type, echo.Params[1].Schema.Type)
+	}
+	if ref := echo.Result.Schema.Ref; ref != "#/components/schemas/rpc.echoResult" {
+		t.Errorf("result reference mismatch: %s", ref)
+	}
+	result := doc.Components.Schemas["rpc.echoResult"]
+	if result == nil {
+		t.Fatal("result schema missing")
+	}
+	if args := result.Properties["Args"]; args == nil || args.Ref != "#/components/schemas/rpc.echoArgs" {
+		t.Errorf("nested schema mismatch: %v", args)
+	}
+	if want := []string{"Int", "String"}; !reflect.DeepEqual(result.Required, want) {
+		t.Errorf("required properties mismatch: have %v, want %v", result.Required, want)
+	}
+	if subs := methods["nftest_subscribe"].Params[0].Schema.Enum; !reflect.DeepEqual(subs, []string{"hangSubscription", "someSubscription"}) {
+		t.Errorf("subscriptions mismatch: %v", subs)
+	}
+}
+
+func TestSchemaWellKnownTypes(t *testing.T) {
+	type inner struct {
+		Number BlockNumber `json:"number"`
+	}
+	type outer struct {
+		inner
+		Hidden  int               `json:"-"`
+		Amount  uint64            `json:"amount,string"`
+		Entries map[string][]byte `json:"entries,omitempty"`
+		Self    *outer            `json:"self"`
+	}
+	b := newSchemaBuilder()
+	ref := b.schema(reflect.TypeOf(&outer{}))
+
+	schema := b.components[ref.Ref[len("#/components/schemas/"):]]
+	if schema == nil {
+		t.Fatalf("schema of %s missing", ref.Ref)
+	}
+	enc, _ := json.Marshal(schema.Properties)
+	want := `{"amount":{"type":"string"},"entries":{"type":"object","additionalProperties":{"type":"string","format":"byte"}},"number":{"title":"blockNumber","oneOf":[{"title":"quantity","type":"string","pattern":"^0x(0|[1-9a-fA-F][0-9a-fA-F]*)$"},{"title":"blockTag","type":"string","enum":["earliest","finalized","safe","latest","pending"]}]},"self":{"$ref":"` + ref.Ref + `"}}`
+	if string(enc) != want {
+		t.Errorf("schema mismatch:\nhave %s\nwant %s", enc, want)
+	}
+}
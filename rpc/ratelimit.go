@@ -0,0 +1,271 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// APIKeyHeader is the HTTP header carrying the API key of a client.
+	APIKeyHeader = "X-API-Key"
+
+	// rateLimitClients is the number of clients the rate limits are tracked
+	// for, the least recently active ones are forgotten first.
+	rateLimitClients = 65536
+)
+
+// DefaultMethodCosts are the cost weights of the expensive methods, applied
+// unless overridden by the rate limit configuration.
+var DefaultMethodCosts = map[string]int{
+	"eth_getLogs":          10,
+	"eth_getFilterLogs":    10,
+	"eth_callBundle":       10,
+	"debug_trace*":         50,
+	"debug_getBadBlocks":   10,
+	"debug_storageRangeAt": 10,
+}
+
+// Quota is a token bucket of cost units, refilled at Rate units per second up
+// to Burst units. Calls costing more than Burst units are always rejected. The
+// zero quota is unlimited.
+type Quota struct {
+	Rate  float64 `toml:",omitempty"`
+	Burst int     `toml:",omitempty"`
+}
+
+// unlimited reports whether the quota applies no limit.
+func (q Quota) unlimited() bool {
+	return q.Rate == 0 && q.Burst == 0
+}
+
+// RateLimitConfig is the per-client rate limit configuration of the server.
+// Clients presenting a known API key in the APIKeyHeader are limited by the
+// quota of their key, all others by the quota of their IP address. Every call
+// costs one unit unless weighted otherwise. Method names can end in '*' to
+// match all methods with the given prefix.
+type RateLimitConfig struct {
+	Client  Quota            `toml:",omitempty"` // Quota of each IP address
+	APIKeys map[string]Quota `toml:",omitempty"` // Quotas of the API key holders
+	Methods map[string]Quota `toml:",omitempty"` // Per-client quotas of specific methods, on top of the client quota
+	Costs   map[string]int   `toml:",omitempty"` // Cost weights of the methods, on top of DefaultMethodCosts
+}
+
+// Validate checks the rate limit configuration is well-formed.
+func (c *RateLimitConfig) Validate() error {
+	check := func(name string, q Quota) error {
+		if q.Rate < 0 || q.Burst < 0 || (q.Rate == 0) != (q.Burst == 0) {
+			return fmt.Errorf("invalid rate limit quota for %s: rate %v, burst %d", name, q.Rate, q.Burst)
+		}
+		return nil
+	}
+	if err := check("clients", c.Client); err != nil {
+		return err
+	}
+	for key, q := range c.APIKeys {
+		if key == "" {
+			return errors.New("empty API key")
+		}
+		if err := check("API key", q); err != nil {
+			return err
+		}
+	}
+	for method, q := range c.Methods {
+		if err := check(method, q); err != nil {
+			return err
+		}
+	}
+	for method, cost := range c.Costs {
+		if cost < 0 {
+			return fmt.Errorf("invalid cost for %s: %d", method, cost)
+		}
+	}
+	return nil
+}
+
+// MaxCost returns the largest cost weight of the methods, which is the smallest
+// burst allowing all of them to be called.
+func (c *RateLimitConfig) MaxCost() int {
+	cost := 1
+	for method, v := range DefaultMethodCosts {
+		if _, ok := c.Costs[method]; !ok {
+			cost = max(cost, v)
+		}
+	}
+	for _, v := range c.Costs {
+		cost = max(cost, v)
+	}
+	return cost
+}
+
+// methodTable resolves method names against a set of exact names and prefix
+// patterns, the longest pattern wins.
+type methodTable[T any] struct {
+	exact    map[string]T
+	prefixes map[string]T
+}
+
+func newMethodTable[T any](entries map[string]T) methodTable[T] {
+	t := methodTable[T]{exact: make(map[string]T), prefixes: make(map[string]T)}
+	for name, v := range entries {
+		if prefix, ok := strings.CutSuffix(name, "*"); ok {
+			t.prefixes[prefix] = v
+		} else {
+			t.exact[name] = v
+		}
+	}
+	return t
+}
+
+// lookup returns the entry of the method and the pattern it was found under.
+func (t methodTable[T]) lookup(method string) (T, string, bool) {
+	if v, ok := t.exact[method]; ok {
+		return v, method, true
+	}
+	var (
+		best  T
+		match string
+		found bool
+	)
+	for prefix, v := range t.prefixes {
+		if strings.HasPrefix(method, prefix) && (!found || len(prefix) > len(match)) {
+			best, match, found = v, prefix, true
+		}
+	}
+	return best, match + "*", found
+}
+
+// clientLimiters are the token buckets of a single client.
+type clientLimiters struct {
+	total   *rate.Limiter            // nil if the client is unlimited
+	methods map[string]*rate.Limiter // keyed by the method pattern
+}
+
+// rateLimiter enforces the rate limit configuration of a server.
+type rateLimiter struct {
+	config  RateLimitConfig
+	costs   methodTable[int]
+	methods methodTable[Quota]
+
+	clients lru.BasicLRU[string, *clientLimiters]
+	lock    sync.Mutex
+}
+
+func newRateLimiter(config *RateLimitConfig) (*rateLimiter, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	costs := make(map[string]int, len(DefaultMethodCosts)+len(config.Costs))
+	for method, cost := range DefaultMethodCosts {
+		costs[method] = cost
+	}
+	for method, cost := range config.Costs {
+		costs[method] = cost
+	}
+	return &rateLimiter{
+		config:  *config,
+		costs:   newMethodTable(costs),
+		methods: newMethodTable(config.Methods),
+		clients: lru.NewBasicLRU[string, *clientLimiters](rateLimitClients),
+	}, nil
+}
+
+// cost returns the cost weight of the method.
+func (l *rateLimiter) cost(method string) int {
+	if cost, _, ok := l.costs.lookup(method); ok {
+		return cost
+	}
+	return 1
+}
+
+// allow charges the cost of the method to the client, returning an error if it
+// exceeds any of its quotas. Only remote clients over HTTP and WebSocket are
+// limited.
+func (l *rateLimiter) allow(info PeerInfo, method string) error {
+	if info.Transport != "http" && info.Transport != "ws" {
+		return nil
+	}
+	// Identify the client by its API key if known, by its IP otherwise
+	id, quota := "", l.config.Client
+	if key := info.HTTP.APIKey; key != "" {
+		if q, ok := l.config.APIKeys[key]; ok {
+			id, quota = "key:"+key, q
+		}
+	}
+	if id == "" {
+		host, _, err := net.SplitHostPort(info.RemoteAddr)
+		if err != nil {
+			host = info.RemoteAddr
+		}
+		id = "ip:" + host
+	}
+	methodQuota, pattern, limited := l.methods.lookup(method)
+	if quota.unlimited() && (!limited || methodQuota.unlimited()) {
+		return nil
+	}
+	cost := l.cost(method)
+	if cost == 0 {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	client, ok := l.clients.Get(id)
+	if !ok {
+		client = &clientLimiters{methods: make(map[string]*rate.Limiter)}
+		if !quota.unlimited() {
+			client.total = rate.NewLimiter(rate.Limit(quota.Rate), quota.Burst)
+		}
+		l.clients.Add(id, client)
+	}
+	var (
+		now          = time.Now()
+		reservations []*rate.Reservation
+	)
+	if limited && !methodQuota.unlimited() {
+		limiter := client.methods[pattern]
+		if limiter == nil {
+			limiter = rate.NewLimiter(rate.Limit(methodQuota.Rate), methodQuota.Burst)
+			client.methods[pattern] = limiter
+		}
+		reservations = append(reservations, limiter.ReserveN(now, cost))
+	}
+	if client.total != nil {
+		reservations = append(reservations, client.total.ReserveN(now, cost))
+	}
+	// Only charge the client if all the quotas allow the call. The reservation
+	// fails if the cost exceeds the burst, such calls are never allowed.
+	for _, r := range reservations {
+		if !r.OK() || r.DelayFrom(now) > 0 {
+			for _, r := range reservations {
+				r.CancelAt(now)
+			}
+			rateLimitedMeter.Mark(1)
+			updateRateLimitedMeter(method)
+			return &limitExceededError{method: method}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+// newRateLimitedClients starts a rate limited HTTP server and dials it with each
+// of the given API keys.
+func newRateLimitedClients(t *testing.T, config *RateLimitConfig, keys ...string) []*Client {
+	server := newTestServer()
+	if err := server.SetRateLimit(config); err != nil {
+		t.Fatalf("failed to set rate limit: %v", err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(func() {
+		ts.Close()
+		server.Stop()
+	})
+	var clients []*Client
+	for _, key := range keys {
+		client, err := DialOptions(context.Background(), ts.URL, WithHeader(APIKeyHeader, key))
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		t.Cleanup(client.Close)
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// checkCalls makes the given number of calls, expecting the last one to be rate
+// limited if limited is set.
+func checkCalls(t *testing.T, client *Client, method string, n int, limited bool) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		err := client.Call(nil, method, "x", 1)
+		if i < n-1 || !limited {
+			if err != nil {
+				t.Fatalf("call %d of %s failed: %v", i, method, err)
+			}
+			continue
+		}
+		var rpcErr Error
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeLimitExceeded {
+			t.Fatalf("call %d of %s: have %v, want rate limit error", i, method, err)
+		}
+	}
+}
+
+func TestRateLimitClients(t *testing.T) {
+	config := &RateLimitConfig{
+		Client:  Quota{Rate: 0.001, Burst: 2},
+		APIKeys: map[string]Quota{"secret": {Rate: 0.001, Burst: 4}},
+	}
+	clients := newRateLimitedClients(t, config, "", "secret", "unknown")
+	anonymous, keyed, unknown := clients[0], clients[1], clients[2]
+
+	checkCalls(t, anonymous, "test_repeat", 3, true)
+	checkCalls(t, keyed, "test_repeat", 5, true)
+
+	// Unknown API keys are limited by the quota of their IP address
+	checkCalls(t, unknown, "test_repeat", 1, true)
+}
+
+func TestRateLimitMethods(t *testing.T) {
+	config := &RateLimitConfig{
+		Client:  Quota{Rate: 0.001, Burst: 6},
+		Methods: map[string]Quota{"test_echo*": {Rate: 0.001, Burst: 1}},
+		Costs:   map[string]int{"test_repeat": 2},
+	}
+	client := newRateLimitedClients(t, config, "")[0]
+
+	// The method quota doesn't charge the client quota when exceeded
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatalf("failed to call: %v", err)
+	}
+	checkCalls(t, client, "test_echoWithCtx", 1, true)
+
+	// The remaining five units allow two weighted calls
+	checkCalls(t, client, "test_repeat", 3, true)
+	checkCalls(t, client, "test_echo", 1, true)
+}
+
+func TestRateLimitCostExceedsBurst(t *testing.T) {
+	config := &RateLimitConfig{
+		Client:  Quota{Rate: 0.001, Burst: 4},
+		Methods: map[string]Quota{"test_echo*": {Rate: 0.001, Burst: 2}},
+		Costs:   map[string]int{"test_repeat": 5, "test_echo": 3},
+	}
+	client := newRateLimitedClients(t, config, "")[0]
+
+	// Calls costing more than the burst are rejected without charging the client
+	checkCalls(t, client, "test_repeat", 1, true)
+	checkCalls(t, client, "test_echo", 1, true)
+	checkCalls(t, client, "test_echoWithCtx", 3, true)
+
+	if have := config.MaxCost(); have != 50 {
+		t.Errorf("max cost mismatch: have %d, want 50", have)
+	}
+	config.Costs["debug_trace*"] = 20
+	if have := config.MaxCost(); have != 20 {
+		t.Errorf("max cost mismatch: have %d, want 20", have)
+	}
+}
+
+func TestRateLimitConfigValidate(t *testing.T) {
+	invalid := []*RateLimitConfig{
+		{Client: Quota{Rate: 1}},
+		{Client: Quota{Burst: 1}},
+		{Client: Quota{Rate: -1, Burst: 1}},
+		{APIKeys: map[string]Quota{"": {Rate: 1, Burst: 1}}},
+		{Methods: map[string]Quota{"eth_call": {Rate: 1}}},
+		{Costs: map[string]int{"eth_call": -1}},
+	}
+	for i, config := range invalid {
+		if err := config.Validate(); err == nil {
+			t.Errorf("config %d: invalid config accepted", i)
+		}
+	}
+	if err := (&RateLimitConfig{Client: Quota{Rate: 1, Burst: 1}}).Validate(); err != nil {
+		t.Errorf("valid config rejected: %v", err)
+	}
+}
@@ -54,6 +54,8 @@ type Server struct {
 	batchItemLimit     int
 	batchResponseLimit int
 	httpBodyLimit      int
+	rateLimit          *rateLimiter
+	responseCache      *ResponseCache
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -68,6 +70,7 @@ func NewServer() *Server {
 	// as the services and methods it offers.
 	rpcService := &RPCService{server}
 	server.RegisterName(MetadataApi, rpcService)
+	server.SetServiceDescription(MetadataApi, "RPC server metadata and service discovery")
 	return server
 }
 
@@ -89,6 +92,33 @@ func (s *Server) SetHTTPBodyLimit(limit int) {
 	s.httpBodyLimit = limit
 }
 
+// SetRateLimit sets the per-client rate limits of remote HTTP and WebSocket calls. A nil
+// config disables rate limiting.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetRateLimit(config *RateLimitConfig) error {
+	if config == nil {
+		s.rateLimit = nil
+		return nil
+	}
+	limiter, err := newRateLimiter(config)
+	if err != nil {
+		return err
+	}
+	s.rateLimit = limiter
+	return nil
+}
+
+// SetResponseCache sets the cache of the responses of immutable queries, which may be
+// shared between servers. A nil cache disables response caching.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetResponseCache(cache *ResponseCache) {
+	s.responseCache = cache
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either an RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -97,6 +127,12 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// SetServiceDescription sets the description of a registered namespace, included in the
+// OpenRPC service discovery document.
+func (s *Server) SetServiceDescription(name, description string) error {
+	return s.services.setDescription(name, description)
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
@@ -114,6 +150,8 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		rateLimit:          s.rateLimit,
+		responseCache:      s.responseCache,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -149,6 +187,8 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.allowSubscribe = false
+	h.rateLimit = s.rateLimit
+	h.responseCache = s.responseCache
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -213,6 +253,12 @@ func (s *RPCService) Modules() map[string]string {
 	return modules
 }
 
+// Discover returns the OpenRPC description of the methods offered by the server. It's
+// served as rpc.discover, as required by the OpenRPC specification.
+func (s *RPCService) Discover() *OpenRPCDocument {
+	return s.server.services.discover()
+}
+
 // PeerInfo contains information about the remote end of the network connection.
 //
 // This is available within RPC method handlers through the context. Call
@@ -234,6 +280,7 @@ type PeerInfo struct {
 		UserAgent string
 		Origin    string
 		Host      string
+		APIKey    string // Value of the APIKeyHeader
 	}
 }
 
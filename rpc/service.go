@@ -43,6 +43,7 @@ type serviceRegistry struct {
 // service represents a registered object.
 type service struct {
 	name          string               // name for service
+	description   string               // optional description of the namespace
 	callbacks     map[string]*callback // registered handlers
 	subscriptions map[string]*callback // available subscriptions/notifications
 }
@@ -91,8 +92,25 @@ func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
 	return nil
 }
 
+// setDescription sets the description of a registered service.
+func (r *serviceRegistry) setDescription(name, description string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	svc, ok := r.services[name]
+	if !ok {
+		return fmt.Errorf("no service %q registered", name)
+	}
+	svc.description = description
+	r.services[name] = svc
+	return nil
+}
+
 // callback returns the callback corresponding to the given RPC method name.
 func (r *serviceRegistry) callback(method string) *callback {
+	if method == DiscoverMethod {
+		method = discoverCallback
+	}
 	before, after, found := strings.Cut(method, serviceMethodSeparator)
 	if !found {
 		return nil
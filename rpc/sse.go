@@ -0,0 +1,499 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sseContentType    = "text/event-stream"
+	lastEventIDHeader = "Last-Event-ID"
+
+	// sseMaxEventSize is the maximum size of an event accepted by the client.
+	sseMaxEventSize = 32 * 1024 * 1024
+
+	// sseReconnectAttempts is the number of times the client tries to resume a
+	// broken event stream before failing the subscription.
+	sseReconnectAttempts = 3
+
+	// sseHeartbeatInterval is the interval of the comments sent on event streams,
+	// keeping proxies from timing out idle connections.
+	sseHeartbeatInterval = 15 * time.Second
+
+	// sseProbeTimeout is the time the client waits for the server to report its
+	// support of server-sent events.
+	sseProbeTimeout = 10 * time.Second
+)
+
+// sseReconnectDelay is the time the client waits before resuming a broken event
+// stream.
+var sseReconnectDelay = time.Second
+
+type eventIDKey struct{}
+
+type lastEventIDKey struct{}
+
+// LastEventID returns the ID of the last event received by a client resuming a
+// subscription over server-sent events, as sent in the Last-Event-ID header. Use
+// this with the context passed to subscription handler functions to replay the
+// notifications the client missed, see Notifier.NotifyWithEventID.
+func LastEventID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(lastEventIDKey{}).(string)
+	return id, ok
+}
+
+// isSSERequest reports whether the client requests a server-sent event stream.
+func isSSERequest(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("accept"), ",") {
+		if mt, _, err := mime.ParseMediaType(strings.TrimSpace(accept)); err == nil && mt == sseContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// readSSERequest reads the subscription request of an event stream. The request is
+// sent as the JSON body of POST requests, or as the method, params and id query
+// parameters of GET requests, which is what browsers' EventSource is limited to.
+func (s *Server) readSSERequest(r *http.Request) (*jsonrpcMessage, int, error) {
+	msg := new(jsonrpcMessage)
+	switch r.Method {
+	case http.MethodPost:
+		if code, err := s.validateRequest(r); err != nil {
+			return nil, code, err
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, int64(s.httpBodyLimit))).Decode(msg); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	case http.MethodGet:
+		if code, err := s.validateRequestLimits(r); err != nil {
+			return nil, code, err
+		}
+		// The request is carried by the query, limit it like a request body
+		if len(r.URL.RawQuery) > s.httpBodyLimit {
+			err := fmt.Errorf("query too large (%d>%d)", len(r.URL.RawQuery), s.httpBodyLimit)
+			return nil, http.StatusRequestEntityTooLarge, err
+		}
+		query := r.URL.Query()
+		msg.Version = vsn
+		msg.Method = query.Get("method")
+		msg.ID = json.RawMessage(`1`)
+		if id := query.Get("id"); id != "" {
+			msg.ID = json.RawMessage(id)
+		}
+		if params := query.Get("params"); params != "" {
+			msg.Params = json.RawMessage(params)
+		}
+		if !json.Valid(msg.ID) || (msg.Params != nil && !json.Valid(msg.Params)) {
+			return nil, http.StatusBadRequest, errors.New("invalid id or params")
+		}
+	default:
+		return nil, http.StatusMethodNotAllowed, errors.New("method not allowed")
+	}
+	if !msg.isCall() || !msg.isSubscribe() {
+		return nil, http.StatusBadRequest, errors.New("only subscriptions are supported over server-sent events")
+	}
+	return msg, 0, nil
+}
+
+// serveSSE serves a subscription as a stream of server-sent events. The response to
+// the subscription request is sent as the first event, followed by the notifications.
+// The stream ends when the client disconnects.
+func (s *Server) serveSSE(w http.ResponseWriter, r *http.Request, info PeerInfo) {
+	msg, code, err := s.readSSERequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("content-type", sseContentType)
+	w.Header().Set("cache-control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	conn := &sseServerConn{
+		w:           w,
+		flusher:     flusher,
+		rc:          http.NewResponseController(w),
+		info:        info,
+		lastEventID: r.Header.Get(lastEventIDHeader),
+		request:     msg,
+		closeCh:     make(chan interface{}),
+	}
+	if err := conn.write(nil); err != nil {
+		return
+	}
+	go conn.heartbeat(r.Context())
+	s.ServeCodec(conn, 0)
+
+	// The response can't be written after returning, wait for the pending write.
+	conn.mu.Lock()
+	conn.mu.Unlock()
+}
+
+// sseServerConn is the codec of a subscription served as server-sent events.
+type sseServerConn struct {
+	w           http.ResponseWriter
+	flusher     http.Flusher
+	rc          *http.ResponseController
+	info        PeerInfo
+	lastEventID string
+
+	request *jsonrpcMessage // the subscription request, nil once read
+
+	mu        sync.Mutex // protects writes
+	closeOnce sync.Once
+	closeCh   chan interface{}
+}
+
+func (c *sseServerConn) peerInfo() PeerInfo {
+	return c.info
+}
+
+func (c *sseServerConn) remoteAddr() string {
+	return c.info.RemoteAddr
+}
+
+// readBatch returns the subscription request, then blocks until the stream ends.
+func (c *sseServerConn) readBatch() ([]*jsonrpcMessage, bool, error) {
+	if msg := c.request; msg != nil {
+		c.request = nil
+		return []*jsonrpcMessage{msg}, false, nil
+	}
+	<-c.closeCh
+	return nil, false, io.EOF
+}
+
+// writeJSON sends a message as an event, carrying the event ID in the context if
+// any. The stream ends after a failed subscription request.
+func (c *sseServerConn) writeJSON(ctx context.Context, v interface{}, isError bool) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var event bytes.Buffer
+	if id, ok := ctx.Value(eventIDKey{}).(string); ok && !strings.ContainsAny(id, "\r\n") {
+		fmt.Fprintf(&event, "id: %s\n", id)
+	}
+	fmt.Fprintf(&event, "data: %s\n\n", data)
+	if err := c.write(event.Bytes()); err != nil {
+		return err
+	}
+	if msg, ok := v.(*jsonrpcMessage); ok && msg.Error != nil {
+		c.close()
+	}
+	return nil
+}
+
+// write writes and flushes the data to the stream, closing it if it fails.
+func (c *sseServerConn) write(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.closeCh:
+		return io.EOF
+	default:
+	}
+	// The write deadline of the HTTP server doesn't apply to the stream as a
+	// whole, only to each event.
+	c.rc.SetWriteDeadline(time.Now().Add(defaultWriteTimeout))
+	if _, err := c.w.Write(data); err != nil {
+		c.close()
+		return err
+	}
+	c.flusher.Flush()
+	return nil
+}
+
+// heartbeat sends periodic comments until the stream ends or the client goes away.
+func (c *sseServerConn) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(sseHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			c.close()
+			return
+		case <-c.closeCh:
+			return
+		}
+	}
+}
+
+func (c *sseServerConn) close() {
+	c.closeOnce.Do(func() { close(c.closeCh) })
+}
+
+func (c *sseServerConn) closed() <-chan interface{} {
+	return c.closeCh
+}
+
+// sseEvent is an event received from a server-sent event stream.
+type sseEvent struct {
+	id   string
+	data []byte
+}
+
+// sseReader parses the events of a server-sent event stream.
+type sseReader struct {
+	scanner *bufio.Scanner
+}
+
+func newSSEReader(r io.Reader) *sseReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, sseMaxEventSize)
+	return &sseReader{scanner: scanner}
+}
+
+// next returns the next event carrying data, skipping comments and unknown fields.
+func (r *sseReader) next() (*sseEvent, error) {
+	var (
+		event   sseEvent
+		hasData bool
+	)
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" {
+			if hasData {
+				return &event, nil
+			}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			event.id = value
+		case "data":
+			if hasData {
+				event.data = append(event.data, '\n')
+			}
+			event.data = append(event.data, value...)
+			hasData = true
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// openSSE sends a subscription request to the server, returning the event stream
+// and the ID of the subscription.
+func (hc *httpConn) openSSE(ctx context.Context, msg *jsonrpcMessage, lastEventID string) (io.ReadCloser, *sseReader, string, error) {
+	header := http.Header{"Accept": {sseContentType}}
+	if lastEventID != "" {
+		header.Set(lastEventIDHeader, lastEventID)
+	}
+	body, respHeader, err := hc.doRequestWithHeader(ctx, msg, header)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if mt, _, err := mime.ParseMediaType(respHeader.Get("content-type")); err != nil || mt != sseContentType {
+		body.Close()
+		return nil, nil, "", ErrNotificationsUnsupported
+	}
+	reader := newSSEReader(body)
+	event, err := reader.next()
+	if err != nil {
+		body.Close()
+		return nil, nil, "", err
+	}
+	var (
+		resp  jsonrpcMessage
+		subid string
+	)
+	if err := json.Unmarshal(event.data, &resp); err != nil {
+		body.Close()
+		return nil, nil, "", err
+	}
+	if resp.Error != nil {
+		body.Close()
+		return nil, nil, "", resp.Error
+	}
+	if err := json.Unmarshal(resp.Result, &subid); err != nil {
+		body.Close()
+		return nil, nil, "", err
+	}
+	return body, reader, subid, nil
+}
+
+// supportsSSE reports whether the server streams subscriptions as server-sent
+// events. The result of the first successful probe is cached, the server is
+// probed again after failures.
+func (hc *httpConn) supportsSSE() bool {
+	hc.sseMu.Lock()
+	defer hc.sseMu.Unlock()
+
+	if hc.sseProbed {
+		return hc.sseSupported
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sseProbeTimeout)
+	defer cancel()
+
+	supported, err := hc.probeSSE(ctx)
+	if err != nil {
+		return false
+	}
+	hc.sseProbed, hc.sseSupported = true, supported
+	return supported
+}
+
+// probeSSE asks the server whether it supports server-sent events. Servers which
+// do answer the health check request with the event stream content type.
+func (hc *httpConn) probeSSE(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hc.url, nil)
+	if err != nil {
+		return false, err
+	}
+	hc.mu.Lock()
+	req.Header = hc.headers.Clone()
+	hc.mu.Unlock()
+	setHeaders(req.Header, http.Header{"Accept": {sseContentType}})
+	req.Header.Del("content-type")
+
+	if hc.auth != nil {
+		if err := hc.auth(req.Header); err != nil {
+			return false, err
+		}
+	}
+	resp, err := hc.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, HTTPError{Status: resp.Status, StatusCode: resp.StatusCode}
+	}
+	mt, _, err := mime.ParseMediaType(resp.Header.Get("content-type"))
+	return err == nil && mt == sseContentType, nil
+}
+
+// subscribeSSE establishes a subscription over HTTP as a stream of server-sent
+// events. Broken streams are resumed after the last received event.
+func (c *Client) subscribeSSE(ctx context.Context, namespace string, channel reflect.Value, msg *jsonrpcMessage) (*ClientSubscription, error) {
+	hc := c.writeConn.(*httpConn)
+
+	// The stream outlives the context of the subscription request.
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stop := context.AfterFunc(ctx, cancel)
+	body, reader, subid, err := hc.openSSE(streamCtx, msg, "")
+	if !stop() {
+		err = errors.Join(err, ctx.Err())
+	}
+	if err != nil {
+		cancel()
+		if body != nil {
+			body.Close()
+		}
+		return nil, err
+	}
+	sub := newClientSubscription(c, namespace, channel)
+	sub.subid = subid
+	sub.cancelStream = cancel
+	go sub.run()
+	go c.readSSE(streamCtx, sub, msg, body, reader)
+	return sub, nil
+}
+
+// readSSE delivers the notifications of an event stream to the subscription.
+func (c *Client) readSSE(ctx context.Context, sub *ClientSubscription, msg *jsonrpcMessage, body io.ReadCloser, reader *sseReader) {
+	hc := c.writeConn.(*httpConn)
+	defer sub.cancelStream()
+
+	// Abort the stream when the client is closed.
+	go func() {
+		select {
+		case <-hc.closeCh:
+			sub.cancelStream()
+		case <-ctx.Done():
+		}
+	}()
+	var lastEventID string
+	for {
+		event, err := reader.next()
+		if err != nil {
+			body.Close()
+			if ctx.Err() != nil {
+				select {
+				case <-hc.closeCh:
+					sub.close(ErrClientQuit)
+				default:
+				}
+				return
+			}
+			if body, reader, err = c.resumeSSE(ctx, msg, lastEventID); err != nil {
+				sub.close(err)
+				return
+			}
+			continue
+		}
+		if event.id != "" {
+			lastEventID = event.id
+		}
+		var note jsonrpcMessage
+		if err := json.Unmarshal(event.data, &note); err != nil || !note.isNotification() {
+			continue
+		}
+		var result subscriptionResult
+		if err := json.Unmarshal(note.Params, &result); err != nil {
+			continue
+		}
+		if !sub.deliver(result.Result) {
+			body.Close()
+			return
+		}
+	}
+}
+
+// resumeSSE reopens a broken event stream after the last received event.
+func (c *Client) resumeSSE(ctx context.Context, msg *jsonrpcMessage, lastEventID string) (body io.ReadCloser, reader *sseReader, err error) {
+	hc := c.writeConn.(*httpConn)
+	for i := 0; i < sseReconnectAttempts; i++ {
+		select {
+		case <-time.After(sseReconnectDelay):
+		case <-ctx.Done():
+			return nil, nil, ErrClientQuit
+		}
+		if body, reader, _, err = hc.openSSE(ctx, msg, lastEventID); err == nil {
+			return body, reader, nil
+		}
+	}
+	return nil, nil, err
+}
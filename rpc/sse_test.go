@@ -0,0 +1,262 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resumeTestService notifies three consecutive numbers per subscription, starting
+// after the last event ID.
+type resumeTestService struct{}
+
+func (resumeTestService) Count(ctx context.Context) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	start := 1
+	if id, ok := LastEventID(ctx); ok {
+		last, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, err
+		}
+		start = last + 1
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		for i := start; i < start+3; i++ {
+			notifier.NotifyWithEventID(sub.ID, strconv.Itoa(i), i)
+		}
+	}()
+	return sub, nil
+}
+
+func TestSSESubscription(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	ch := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 5, 10)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		select {
+		case val := <-ch:
+			if val != 10+i {
+				t.Fatalf("value mismatch: have %d, want %d", val, 10+i)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for notification")
+		}
+	}
+	sub.Unsubscribe()
+
+	// Failed subscription requests are reported by Subscribe
+	if _, err := client.Subscribe(context.Background(), "nftest", ch, "missing"); err == nil {
+		t.Fatal("subscription to unknown name succeeded")
+	}
+}
+
+func TestSSEEventStream(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	get := func(query url.Values) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"?"+query.Encode(), nil)
+		req.Header.Set("accept", sseContentType)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp
+	}
+	// Only subscriptions can be streamed
+	resp := get(url.Values{"method": {"test_echo"}, "params": {`["x",1]`}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status mismatch: have %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+	// Subscriptions requested by browsers are sent as query parameters
+	resp = get(url.Values{"method": {"nftest_subscribe"}, "params": {`["someSubscription",2,7]`}, "id": {"3"}})
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("content-type"); ct != sseContentType {
+		t.Fatalf("content type mismatch: have %q, want %q", ct, sseContentType)
+	}
+	reader := newSSEReader(resp.Body)
+	want := []string{
+		`{"jsonrpc":"2.0","id":3,"result":"0x1"}`,
+		`{"jsonrpc":"2.0","method":"nftest_subscription","params":{"subscription":"0x1","result":7}}`,
+		`{"jsonrpc":"2.0","method":"nftest_subscription","params":{"subscription":"0x1","result":8}}`,
+	}
+	for i, data := range want {
+		event, err := reader.next()
+		if err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if mustCompact(t, string(event.data)) != mustCompact(t, data) {
+			t.Errorf("event %d mismatch:\nhave %s\nwant %s", i, event.data, data)
+		}
+	}
+}
+
+func TestSSERequestLimits(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetHTTPBodyLimit(100)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	do := func(method string, query url.Values, body string) int {
+		req, _ := http.NewRequest(method, ts.URL+"?"+query.Encode(), strings.NewReader(body))
+		req.Header.Set("accept", sseContentType)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	sub := url.Values{"method": {"nftest_subscribe"}, "params": {`["someSubscription",2,7]`}}
+	tests := []struct {
+		method string
+		query  url.Values
+		body   string
+		code   int
+	}{
+		{http.MethodPut, sub, "", http.StatusMethodNotAllowed},
+		{http.MethodDelete, sub, "", http.StatusMethodNotAllowed},
+		{http.MethodGet, sub, strings.Repeat("x", 101), http.StatusRequestEntityTooLarge},
+		{http.MethodGet, url.Values{"method": {"nftest_subscribe"}, "params": {`["` + strings.Repeat("x", 100) + `"]`}}, "", http.StatusRequestEntityTooLarge},
+	}
+	for i, tt := range tests {
+		if code := do(tt.method, tt.query, tt.body); code != tt.code {
+			t.Errorf("test %d: status mismatch: have %d, want %d", i, code, tt.code)
+		}
+	}
+}
+
+func TestSSESupport(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+	if !client.SupportsSubscriptions() {
+		t.Fatal("subscriptions not supported by the server-sent event server")
+	}
+
+	// Servers without server-sent events answer the probe with a plain response
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer plain.Close()
+
+	client, err = DialHTTP(plain.URL)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+	if client.SupportsSubscriptions() {
+		t.Fatal("subscriptions supported by a plain HTTP server")
+	}
+}
+
+func mustCompact(t *testing.T, data string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	enc, _ := json.Marshal(v)
+	return string(enc)
+}
+
+func TestSSEResume(t *testing.T) {
+	defer func(delay time.Duration) { sseReconnectDelay = delay }(sseReconnectDelay)
+	sseReconnectDelay = 10 * time.Millisecond
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("resume", resumeTestService{}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	ch := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "resume", ch, "count")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for want := 1; want <= 6; want++ {
+		select {
+		case val := <-ch:
+			if val != want {
+				t.Fatalf("value mismatch: have %d, want %d", val, want)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for notification")
+		}
+		// Break the stream after the first batch, the client resumes after the
+		// last received event
+		if want == 3 {
+			ts.CloseClientConnections()
+		}
+	}
+	// Closing the client ends the subscription without error
+	client.Close()
+	select {
+	case err := <-sub.Err():
+		if err != nil {
+			t.Fatalf("subscription failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not ended by closing the client")
+	}
+}
@@ -105,7 +105,7 @@ type Notifier struct {
 
 	mu           sync.Mutex
 	sub          *Subscription
-	buffer       []any
+	buffer       []notification
 	callReturned bool
 	activated    bool
 }
@@ -127,9 +127,22 @@ func (n *Notifier) CreateSubscription() *Subscription {
 	return n.sub
 }
 
+// notification is a buffered notification of an inactive subscription.
+type notification struct {
+	eventID string
+	data    any
+}
+
 // Notify sends a notification to the client with the given data as payload.
 // If an error occurs the RPC connection is closed and the error is returned.
 func (n *Notifier) Notify(id ID, data any) error {
+	return n.NotifyWithEventID(id, "", data)
+}
+
+// NotifyWithEventID sends a notification to the client like Notify, tagged with an
+// event ID the client can resume the subscription after, see LastEventID. The event
+// ID is only transmitted to clients subscribed via server-sent events.
+func (n *Notifier) NotifyWithEventID(id ID, eventID string, data any) error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
@@ -139,9 +152,9 @@ func (n *Notifier) Notify(id ID, data any) error {
 		panic("Notify with wrong ID")
 	}
 	if n.activated {
-		return n.send(n.sub, data)
+		return n.send(n.sub, eventID, data)
 	}
-	n.buffer = append(n.buffer, data)
+	n.buffer = append(n.buffer, notification{eventID, data})
 	return nil
 }
 
@@ -161,8 +174,8 @@ func (n *Notifier) activate() error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
-	for _, data := range n.buffer {
-		if err := n.send(n.sub, data); err != nil {
+	for _, note := range n.buffer {
+		if err := n.send(n.sub, note.eventID, note.data); err != nil {
 			return err
 		}
 	}
@@ -170,7 +183,7 @@ func (n *Notifier) activate() error {
 	return nil
 }
 
-func (n *Notifier) send(sub *Subscription, data any) error {
+func (n *Notifier) send(sub *Subscription, eventID string, data any) error {
 	msg := jsonrpcSubscriptionNotification{
 		Version: vsn,
 		Method:  n.namespace + notificationMethodSuffix,
@@ -179,7 +192,11 @@ func (n *Notifier) send(sub *Subscription, data any) error {
 			Result: data,
 		},
 	}
-	return n.h.conn.writeJSON(context.Background(), &msg, false)
+	ctx := context.Background()
+	if eventID != "" {
+		ctx = context.WithValue(ctx, eventIDKey{}, eventID)
+	}
+	return n.h.conn.writeJSON(ctx, &msg, false)
 }
 
 // A Subscription is created by a notifier and tied to that notifier. The client can use
@@ -209,6 +226,9 @@ type ClientSubscription struct {
 	namespace string
 	subid     string
 
+	// cancelStream ends the event stream of subscriptions over HTTP.
+	cancelStream context.CancelFunc
+
 	// The in channel receives notification values from client dispatcher.
 	in chan json.RawMessage
 
@@ -370,6 +390,10 @@ func (sub *ClientSubscription) unmarshal(result json.RawMessage) (interface{}, e
 }
 
 func (sub *ClientSubscription) requestUnsubscribe() error {
+	if sub.cancelStream != nil {
+		sub.cancelStream() // the server unsubscribes when the stream ends
+		return nil
+	}
 	var result interface{}
 	ctx, cancel := context.WithTimeout(context.Background(), unsubscribeTimeout)
 	defer cancel()
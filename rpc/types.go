@@ -35,6 +35,7 @@ type API struct {
 	Service       interface{} // receiver instance which holds the methods
 	Public        bool        // deprecated - this field is no longer used, but retained for compatibility
 	Authenticated bool        // whether the api should only be available behind authentication.
+	Description   string      // optional description of the namespace, used for service discovery
 }
 
 // ServerCodec implements reading, parsing and writing RPC messages for the server side of
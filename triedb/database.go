@@ -268,6 +268,18 @@ func (db *Database) Recover(target common.Hash) error {
 	return pdb.Recover(target)
 }
 
+// HistoricReader constructs a reader for accessing the requested historical
+// state which is older than the persistent state. It's only supported by
+// path-based database with the state history index enabled and will return
+// an error for others.
+func (db *Database) HistoricReader(root common.Hash) (*pathdb.HistoricalStateReader, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, errors.New("not supported")
+	}
+	return pdb.HistoricReader(root)
+}
+
 // Recoverable returns the indicator if the specified state is enabled to be
 // recovered. It's only supported by path-based database and will return an
 // error for others.
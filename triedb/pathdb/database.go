@@ -94,6 +94,7 @@ type Config struct {
 	CleanCacheSize int    // Maximum memory allowance (in bytes) for caching clean nodes
 	DirtyCacheSize int    // Maximum memory allowance (in bytes) for caching dirty nodes
 	ReadOnly       bool   // Flag whether the database is opened in read only mode.
+	HistoryIndex   bool   // Flag whether the state histories are indexed for serving historical state
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -218,12 +219,16 @@ func (db *Database) repairHistory() error {
 			if err != nil {
 				log.Crit("Failed to reset state histories", "err", err)
 			}
+			db.resetHistoryIndex()
 			log.Info("Truncated extraneous state history")
 		}
-		return nil
+		return db.initHistoryIndex()
 	}
 	// Truncate the extra state histories above in freezer in case it's not
 	// aligned with the disk layer. It might happen after a unclean shutdown.
+	if err := db.truncateIndexFromHead(id); err != nil {
+		log.Crit("Failed to truncate extra state history index", "err", err)
+	}
 	pruned, err := truncateFromHead(db.diskdb, db.freezer, id)
 	if err != nil {
 		log.Crit("Failed to truncate extra state histories", "err", err)
@@ -231,7 +236,7 @@ func (db *Database) repairHistory() error {
 	if pruned != 0 {
 		log.Warn("Truncated extra state histories", "number", pruned)
 	}
-	return nil
+	return db.initHistoryIndex()
 }
 
 // Update adds a new layer into the tree, if that can be linked to an existing
@@ -338,6 +343,7 @@ func (db *Database) Enable(root common.Hash) error {
 		if err := db.freezer.Reset(); err != nil {
 			return err
 		}
+		db.resetHistoryIndex()
 	}
 	// Re-construct a new disk layer backed by persistent state
 	// with **empty clean cache and node buffer**.
@@ -389,6 +395,9 @@ func (db *Database) Recover(root common.Hash) error {
 		db.tree.reset(dl)
 	}
 	rawdb.DeleteTrieJournal(db.diskdb)
+	if err := db.truncateIndexFromHead(dl.stateID()); err != nil {
+		return err
+	}
 	_, err := truncateFromHead(db.diskdb, db.freezer, dl.stateID())
 	if err != nil {
 		return err
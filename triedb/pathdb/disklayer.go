@@ -163,6 +163,15 @@ func (dl *diskLayer) commit(bottom *diffLayer, force bool) (*diskLayer, error) {
 		oldest   uint64
 	)
 	if dl.db.freezer != nil {
+		// Index the state history before storing it. The dangling index entries
+		// left by a crash in between are tolerated by the reader.
+		if dl.db.config.HistoryIndex && bottom.states != nil {
+			batch := dl.db.diskdb.NewBatch()
+			indexHistory(batch, bottom.stateID(), bottom.states)
+			if err := batch.Write(); err != nil {
+				return nil, err
+			}
+		}
 		err := writeHistory(dl.db.freezer, bottom)
 		if err != nil {
 			return nil, err
@@ -208,6 +217,9 @@ func (dl *diskLayer) commit(bottom *diffLayer, force bool) (*diskLayer, error) {
 	// To remove outdated history objects from the end, we set the 'tail' parameter
 	// to 'oldest-1' due to the offset between the freezer index and the history ID.
 	if overflow {
+		if err := ndl.db.truncateIndexFromTail(oldest - 1); err != nil {
+			return nil, err
+		}
 		pruned, err := truncateFromTail(ndl.db.diskdb, ndl.db.freezer, oldest-1)
 		if err != nil {
 			return nil, err
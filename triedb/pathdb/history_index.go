@@ -0,0 +1,219 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie/triestate"
+)
+
+// The state history index maps each mutated account and storage slot to the
+// ids of the state histories in which they are recorded. It's maintained in
+// the key-value store alongside the state histories in the freezer and allows
+// the state at a historical point to be resolved without reverting the disk
+// layer:
+//
+// The value of an account at state n is recorded by the first state history
+// with id > n that mutates it. If no such history exists up to the current
+// disk layer, the account has not been changed since state n and the value
+// can be resolved from the disk layer directly.
+//
+// Index entries are written before the corresponding state history is stored
+// in the freezer, so an unclean shutdown may leave a few dangling entries
+// behind. They are tolerated by the reader, which always verifies the entry
+// against the referenced state history before using it.
+//
+// The index tail marker records the id of the first state history from which
+// on all histories are indexed. Histories below the marker can't be used for
+// serving historical state.
+
+// indexHistory writes the index entries of the given state set, which belongs
+// to the state history with the specified id.
+func indexHistory(db ethdb.KeyValueWriter, id uint64, states *triestate.Set) {
+	for addr := range states.Accounts {
+		rawdb.WriteStateHistoryAccountIndex(db, addr, id)
+	}
+	for addr, slots := range states.Storages {
+		for slot := range slots {
+			rawdb.WriteStateHistoryStorageIndex(db, addr, slot, id)
+		}
+	}
+}
+
+// unindexHistories removes the index entries of the state histories in range
+// [start, end]. The histories must still be present in the freezer.
+func unindexHistories(db ethdb.Batcher, reader ethdb.AncientReader, start, end uint64) error {
+	batch := db.NewBatch()
+	for id := start; id <= end; id++ {
+		var (
+			accIndexes  = rawdb.ReadStateAccountIndex(reader, id)
+			slotIndexes = rawdb.ReadStateStorageIndex(reader, id)
+		)
+		for i := 0; i+accountIndexSize <= len(accIndexes); i += accountIndexSize {
+			var index accountIndex
+			index.decode(accIndexes[i : i+accountIndexSize])
+			rawdb.DeleteStateHistoryAccountIndex(batch, index.address, id)
+
+			for j := uint32(0); j < index.storageSlots; j++ {
+				pos := int(index.storageOffset+j) * slotIndexSize
+				if pos+slotIndexSize > len(slotIndexes) {
+					break
+				}
+				var slot slotIndex
+				slot.decode(slotIndexes[pos : pos+slotIndexSize])
+				rawdb.DeleteStateHistoryStorageIndex(batch, index.address, slot.hash, id)
+			}
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return batch.Write()
+}
+
+// initHistoryIndex sets up the index tail marker according to the configuration.
+// If the index is newly enabled, only the histories created from now on will be
+// indexed. If the index is disabled, the marker is removed as the following
+// histories won't be indexed anymore.
+func (db *Database) initHistoryIndex() error {
+	if db.freezer == nil || db.readOnly {
+		return nil
+	}
+	tail := rawdb.ReadStateHistoryIndexTail(db.diskdb)
+	if !db.config.HistoryIndex {
+		if tail != nil {
+			rawdb.DeleteStateHistoryIndexTail(db.diskdb)
+			log.Info("Disabled state history index")
+		}
+		return nil
+	}
+	head, err := db.freezer.Ancients()
+	if err != nil {
+		return err
+	}
+	switch {
+	case tail == nil:
+		rawdb.WriteStateHistoryIndexTail(db.diskdb, head+1)
+		log.Info("Enabled state history index", "tail", head+1)
+	case *tail > head+1:
+		// The state histories above the head have been truncated, all newly
+		// created histories will reuse these ids and be indexed.
+		rawdb.WriteStateHistoryIndexTail(db.diskdb, head+1)
+	}
+	return nil
+}
+
+// resetHistoryIndex resets the index tail marker after the state histories in
+// the freezer have been discarded entirely. The stale index entries are left
+// in the database and are ignored by the reader.
+func (db *Database) resetHistoryIndex() {
+	if db.config.HistoryIndex && !db.readOnly {
+		rawdb.WriteStateHistoryIndexTail(db.diskdb, 1)
+	}
+}
+
+// truncateIndexFromHead removes the index entries of the state histories above
+// the given head, which are about to be truncated from the freezer.
+func (db *Database) truncateIndexFromHead(nhead uint64) error {
+	if !db.config.HistoryIndex || db.freezer == nil {
+		return nil
+	}
+	ohead, err := db.freezer.Ancients()
+	if err != nil {
+		return err
+	}
+	if ohead <= nhead {
+		return nil
+	}
+	if err := unindexHistories(db.diskdb, db.freezer, nhead+1, ohead); err != nil {
+		return err
+	}
+	if tail := rawdb.ReadStateHistoryIndexTail(db.diskdb); tail != nil && *tail > nhead+1 {
+		rawdb.WriteStateHistoryIndexTail(db.diskdb, nhead+1)
+	}
+	return nil
+}
+
+// truncateIndexFromTail removes the index entries of the state histories below
+// or equal to the given tail, which are about to be pruned from the freezer.
+func (db *Database) truncateIndexFromTail(ntail uint64) error {
+	if !db.config.HistoryIndex || db.freezer == nil {
+		return nil
+	}
+	otail, err := db.freezer.Tail()
+	if err != nil {
+		return err
+	}
+	if otail >= ntail {
+		return nil
+	}
+	return unindexHistories(db.diskdb, db.freezer, otail+1, ntail)
+}
+
+// lookupAccountIndex finds the position of the account in the account index
+// section of a state history with binary search.
+func lookupAccountIndex(blob []byte, address common.Address) (accountIndex, bool) {
+	n := len(blob) / accountIndexSize
+	pos := sort.Search(n, func(i int) bool {
+		off := i * accountIndexSize
+		return bytes.Compare(blob[off:off+common.AddressLength], address.Bytes()) >= 0
+	})
+	if pos == n {
+		return accountIndex{}, false
+	}
+	var index accountIndex
+	index.decode(blob[pos*accountIndexSize : (pos+1)*accountIndexSize])
+	if index.address != address {
+		return accountIndex{}, false
+	}
+	return index, true
+}
+
+// lookupSlotIndex finds the storage slot in the storage index section of a
+// state history with binary search, within the range belonging to the account.
+func lookupSlotIndex(blob []byte, account accountIndex, slot common.Hash) (slotIndex, bool) {
+	var (
+		start = int(account.storageOffset)
+		n     = int(account.storageSlots)
+	)
+	if (start+n)*slotIndexSize > len(blob) {
+		return slotIndex{}, false
+	}
+	pos := sort.Search(n, func(i int) bool {
+		off := (start + i) * slotIndexSize
+		return bytes.Compare(blob[off:off+common.HashLength], slot.Bytes()) >= 0
+	})
+	if pos == n {
+		return slotIndex{}, false
+	}
+	var index slotIndex
+	off := (start + pos) * slotIndexSize
+	index.decode(blob[off : off+slotIndexSize])
+	if index.hash != slot {
+		return slotIndex{}, false
+	}
+	return index, true
+}
@@ -0,0 +1,175 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+var (
+	// errHistoryIndexDisabled is returned if the historical state is requested
+	// but the state history index is not enabled.
+	errHistoryIndexDisabled = errors.New("state history index is not enabled")
+
+	// errHistoricalStateUnavailable is returned if the requested historical
+	// state is not covered by the indexed state histories.
+	errHistoricalStateUnavailable = errors.New("historical state is not available")
+)
+
+// HistoricalStateReader provides access to the accounts and storage slots of a
+// historical state that has already been flattened into the disk layer, using
+// the state histories along with the state history index.
+type HistoricalStateReader struct {
+	db   *Database
+	id   uint64      // The state id of the historical state
+	root common.Hash // The state root of the historical state
+}
+
+// HistoricReader constructs a reader for accessing the requested historical
+// state. The state must be older than the disk layer, and all the state
+// histories after it must be available and indexed.
+func (db *Database) HistoricReader(root common.Hash) (*HistoricalStateReader, error) {
+	if db.freezer == nil || !db.config.HistoryIndex {
+		return nil, errHistoryIndexDisabled
+	}
+	root = types.TrieRootHash(root)
+	id := rawdb.ReadStateID(db.diskdb, root)
+	if id == nil {
+		return nil, fmt.Errorf("%w: unknown state %x", errHistoricalStateUnavailable, root)
+	}
+	if *id >= db.tree.bottom().stateID() {
+		return nil, fmt.Errorf("%w: state %x is not below the disk layer", errHistoricalStateUnavailable, root)
+	}
+	tail := rawdb.ReadStateHistoryIndexTail(db.diskdb)
+	if tail == nil || *id+1 < *tail {
+		return nil, fmt.Errorf("%w: state %x is not indexed", errHistoricalStateUnavailable, root)
+	}
+	r := &HistoricalStateReader{db: db, id: *id, root: root}
+	if err := r.checkTail(); err != nil {
+		return nil, err
+	}
+	// Ensure the state history right after is linked with the requested
+	// state, the root->id mapping might be a leftover of an unclean reset.
+	var m meta
+	if err := m.decode(rawdb.ReadStateHistoryMeta(db.freezer, *id+1)); err != nil {
+		return nil, err
+	}
+	if m.parent != root {
+		return nil, fmt.Errorf("%w: state %x, history parent %x", errHistoricalStateUnavailable, root, m.parent)
+	}
+	return r, nil
+}
+
+// checkTail ensures the state histories required for serving the state haven't
+// been pruned yet.
+func (r *HistoricalStateReader) checkTail() error {
+	tail, err := r.db.freezer.Tail()
+	if err != nil {
+		return err
+	}
+	if tail > r.id {
+		return fmt.Errorf("%w: state history %d is pruned", errHistoricalStateUnavailable, r.id+1)
+	}
+	return nil
+}
+
+// Root returns the state root of the historical state.
+func (r *HistoricalStateReader) Root() common.Hash {
+	return r.root
+}
+
+// lookup iterates the index entries and returns the value recorded in the
+// first state history which contains the item. The associated disk layer is
+// returned if the item hasn't been mutated since the historical state.
+func (r *HistoricalStateReader) lookup(it ethdb.Iterator, read func(id uint64) ([]byte, bool)) ([]byte, common.Hash, error) {
+	defer it.Release()
+
+	dl := r.db.tree.bottom()
+	for it.Next() {
+		key := it.Key()
+		id := binary.BigEndian.Uint64(key[len(key)-8:])
+		if id > dl.stateID() {
+			break
+		}
+		if blob, ok := read(id); ok {
+			if err := r.checkTail(); err != nil {
+				return nil, common.Hash{}, err
+			}
+			return blob, common.Hash{}, nil
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, common.Hash{}, err
+	}
+	if err := r.checkTail(); err != nil {
+		return nil, common.Hash{}, err
+	}
+	return nil, dl.rootHash(), nil
+}
+
+// Account retrieves the account in the slim-RLP format at the historical
+// state. An empty blob is returned if the account didn't exist.
+//
+// If the account hasn't been mutated since, the root of the disk layer is
+// returned instead and the account should be resolved from there.
+func (r *HistoricalStateReader) Account(address common.Address) ([]byte, common.Hash, error) {
+	it := rawdb.IterateStateHistoryAccountIndex(r.db.diskdb, address, r.id+1)
+	return r.lookup(it, func(id uint64) ([]byte, bool) {
+		index, ok := lookupAccountIndex(rawdb.ReadStateAccountIndex(r.db.freezer, id), address)
+		if !ok {
+			return nil, false // dangling index entry
+		}
+		data := rawdb.ReadStateAccountHistory(r.db.freezer, id)
+		last := index.offset + uint32(index.length)
+		if uint32(len(data)) < last {
+			return nil, false
+		}
+		return common.CopyBytes(data[index.offset:last]), true
+	})
+}
+
+// Storage retrieves the RLP-encoded storage slot at the historical state. An
+// empty blob is returned if the slot didn't exist.
+//
+// If the slot hasn't been mutated since, the root of the disk layer is returned
+// instead and the slot should be resolved from there.
+func (r *HistoricalStateReader) Storage(address common.Address, slot common.Hash) ([]byte, common.Hash, error) {
+	it := rawdb.IterateStateHistoryStorageIndex(r.db.diskdb, address, slot, r.id+1)
+	return r.lookup(it, func(id uint64) ([]byte, bool) {
+		account, ok := lookupAccountIndex(rawdb.ReadStateAccountIndex(r.db.freezer, id), address)
+		if !ok {
+			return nil, false // dangling index entry
+		}
+		index, ok := lookupSlotIndex(rawdb.ReadStateStorageIndex(r.db.freezer, id), account, slot)
+		if !ok {
+			return nil, false // dangling index entry
+		}
+		data := rawdb.ReadStateStorageHistory(r.db.freezer, id)
+		last := index.offset + uint32(index.length)
+		if uint32(len(data)) < last {
+			return nil, false
+		}
+		return common.CopyBytes(data[index.offset:last]), true
+	})
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// verifyHistoricState checks all the accounts and storage slots ever created
+// against the expected historical state.
+func (t *tester) verifyHistoricState(root common.Hash) error {
+	reader, err := t.db.HistoricReader(root)
+	if err != nil {
+		return err
+	}
+	readDisk := func(diskRoot common.Hash, addrHash common.Hash, slot *common.Hash) ([]byte, error) {
+		tr, err := trie.New(trie.StateTrieID(diskRoot), t.db)
+		if err != nil {
+			return nil, err
+		}
+		blob, err := tr.Get(addrHash.Bytes())
+		if err != nil || slot == nil || len(blob) == 0 {
+			return blob, err
+		}
+		account := new(types.StateAccount)
+		if err := rlp.DecodeBytes(blob, account); err != nil {
+			return nil, err
+		}
+		st, err := trie.New(trie.StorageTrieID(diskRoot, addrHash, account.Root), t.db)
+		if err != nil {
+			return nil, err
+		}
+		return st.Get(slot.Bytes())
+	}
+	for addrHash, addr := range t.preimages {
+		blob, diskRoot, err := reader.Account(addr)
+		if err != nil {
+			return err
+		}
+		if diskRoot != (common.Hash{}) {
+			if blob, err = readDisk(diskRoot, addrHash, nil); err != nil {
+				return err
+			}
+		}
+		if want := t.snapAccounts[root][addrHash]; !bytes.Equal(blob, want) {
+			return fmt.Errorf("account %x is mismatched, want %x, got %x", addr, want, blob)
+		}
+		for slotHash, want := range t.snapStorages[root][addrHash] {
+			blob, diskRoot, err := reader.Storage(addr, slotHash)
+			if err != nil {
+				return err
+			}
+			if diskRoot != (common.Hash{}) {
+				if blob, err = readDisk(diskRoot, addrHash, &slotHash); err != nil {
+					return err
+				}
+			}
+			if !bytes.Equal(blob, want) {
+				return fmt.Errorf("slot %x of %x is mismatched, want %x, got %x", slotHash, addr, want, blob)
+			}
+		}
+	}
+	return nil
+}
+
+func TestHistoricReader(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTester(t, 0, true)
+	defer tester.release()
+
+	bottom := tester.bottomIndex()
+	for i, root := range tester.roots {
+		err := tester.verifyHistoricState(root)
+		if i < bottom && err != nil {
+			t.Fatalf("Failed to read historical state %d: %v", i, err)
+		}
+		if i >= bottom && err == nil {
+			t.Fatalf("Unexpected historical state %d above the disk layer", i)
+		}
+	}
+}
+
+func TestHistoricReaderTailTruncation(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTester(t, 2, true)
+	defer tester.release()
+
+	tail, err := tester.db.freezer.Tail()
+	if err != nil {
+		t.Fatalf("Failed to obtain freezer tail: %v", err)
+	}
+	// The state lookup of the pruned history root is removed as well, the
+	// state with the id equal to the tail is not available either.
+	bottom := tester.bottomIndex()
+	for i, root := range tester.roots[:bottom] {
+		err := tester.verifyHistoricState(root)
+		if uint64(i+1) <= tail && err == nil {
+			t.Fatalf("Unexpected historical state %d below the tail", i)
+		}
+		if uint64(i+1) > tail && err != nil {
+			t.Fatalf("Failed to read historical state %d: %v", i, err)
+		}
+	}
+	// The index entries of the pruned state histories should be removed.
+	for _, prefix := range [][]byte{rawdb.StateHistoryAccountIndexPrefix, rawdb.StateHistoryStorageIndexPrefix} {
+		it := tester.db.diskdb.NewIterator(prefix, nil)
+		for it.Next() {
+			if id := binary.BigEndian.Uint64(it.Key()[len(it.Key())-8:]); id <= tail {
+				t.Fatalf("Unexpected index entry of pruned history %d", id)
+			}
+		}
+		it.Release()
+	}
+}